@@ -1,6 +1,6 @@
-// Package Stalker provides primitives to interact with the openapi HTTP API.
+// Package api provides primitives to interact with the openapi HTTP API.
 //
-// Code generated by github.com/deepmap/oapi-codegen version v1.9.0 DO NOT EDIT.
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
 package api
 
 import (
@@ -14,9 +14,72 @@ import (
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Get the pending, firing and recently resolved alerts
+	// (GET /alerts)
+	GetAlerts(w http.ResponseWriter, r *http.Request, params models.GetAlertsParams)
+	// Get the samples that deviated from the learned baseline of their series
+	// (GET /anomalies)
+	GetAnomalies(w http.ResponseWriter, r *http.Request, params models.GetAnomaliesParams)
+	// Store samples pushed with the Prometheus remote_write protocol
+	// (POST /api/v1/write)
+	PostApiV1Write(w http.ResponseWriter, r *http.Request)
+	// Back up the stored metrics now
+	// (POST /backup)
+	PostBackup(w http.ResponseWriter, r *http.Request)
+	// Get the containers contributing the most series
+	// (GET /cardinality)
+	GetCardinality(w http.ResponseWriter, r *http.Request, params models.GetCardinalityParams)
+	// Get the containers stalker collects
+	// (GET /containers)
+	GetContainers(w http.ResponseWriter, r *http.Request)
+	// Get container lifecycle and OOM events from a past time period
+	// (GET /events)
+	GetEvents(w http.ResponseWriter, r *http.Request, params models.GetEventsParams)
+	// Get the sum of the series of the agents of an aggregator
+	// (GET /federation/sum)
+	GetFederationSum(w http.ResponseWriter, r *http.Request, params models.GetFederationSumParams)
+	// Get the series ranking highest across the agents of an aggregator
+	// (GET /federation/top)
+	GetFederationTop(w http.ResponseWriter, r *http.Request, params models.GetFederationTopParams)
+	// Get usage of the filesystems holding container images
+	// (GET /fs/images)
+	GetFsImages(w http.ResponseWriter, r *http.Request)
+	// Get usage of the root filesystem
+	// (GET /fs/root)
+	GetFsRoot(w http.ResponseWriter, r *http.Request)
+	// Store line protocol or cadvisor ContainerInfo dumps captured elsewhere
+	// (POST /import)
+	PostImport(w http.ResponseWriter, r *http.Request, params models.PostImportParams)
+	// Get the hardware description of the machine stalker runs on
+	// (GET /machine)
+	GetMachine(w http.ResponseWriter, r *http.Request)
+	// Get the latest sample of every container in Prometheus exposition format
+	// (GET /metrics)
+	GetMetrics(w http.ResponseWriter, r *http.Request)
 	// Get metrics from a past time period
 	// (GET /metricsList)
 	GetMetricsList(w http.ResponseWriter, r *http.Request, params models.GetMetricsListParams)
+	// Store a batch of points pushed by an agent
+	// (POST /push)
+	PostPush(w http.ResponseWriter, r *http.Request, params models.PostPushParams)
+	// Replace the stored metrics with the latest backup in a directory
+	// (POST /restore)
+	PostRestore(w http.ResponseWriter, r *http.Request)
+	// Get node and pod usage in the kubelet models.Summary API shape
+	// (GET /stats/summary)
+	GetStatsSummary(w http.ResponseWriter, r *http.Request, params models.GetStatsSummaryParams)
+	// Stream the points of every scrape as they are collected
+	// (GET /stream)
+	GetStream(w http.ResponseWriter, r *http.Request, params models.GetStreamParams)
+	// Get metrics from a past time period as JSON encoded flux records
+	// (GET /v1/metricsList)
+	GetV1MetricsList(w http.ResponseWriter, r *http.Request, params models.GetV1MetricsListParams)
+	// Get metrics from a past time period as series of timestamped values
+	// (GET /v2/metricsList)
+	GetV2MetricsList(w http.ResponseWriter, r *http.Request, params models.GetV2MetricsListParams)
+	// Get stalker build information and cadvisor and kernel versions
+	// (GET /version)
+	GetVersion(w http.ResponseWriter, r *http.Request)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -28,6 +91,576 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.HandlerFunc) http.HandlerFunc
 
+// GetAlerts operation middleware
+func (siw *ServerInterfaceWrapper) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetAlertsParams
+
+	// ------------- Optional query parameter "state" -------------
+	if paramValue := r.URL.Query().Get("state"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "state", r.URL.Query(), &params.State)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "state", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAlerts(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetAnomalies operation middleware
+func (siw *ServerInterfaceWrapper) GetAnomalies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetAnomaliesParams
+
+	// ------------- Optional query parameter "startTime" -------------
+	if paramValue := r.URL.Query().Get("startTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "startTime", r.URL.Query(), &params.StartTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "startTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "endTime" -------------
+	if paramValue := r.URL.Query().Get("endTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "endTime", r.URL.Query(), &params.EndTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "endTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "series" -------------
+	if paramValue := r.URL.Query().Get("series"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "series", r.URL.Query(), &params.Series)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "series", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAnomalies(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostApiV1Write operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1Write(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1Write(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostBackup operation middleware
+func (siw *ServerInterfaceWrapper) PostBackup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostBackup(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetCardinality operation middleware
+func (siw *ServerInterfaceWrapper) GetCardinality(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetCardinalityParams
+
+	// ------------- Optional query parameter "limit" -------------
+	if paramValue := r.URL.Query().Get("limit"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetCardinality(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetContainers operation middleware
+func (siw *ServerInterfaceWrapper) GetContainers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetContainers(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetEventsParams
+
+	// ------------- Optional query parameter "startTime" -------------
+	if paramValue := r.URL.Query().Get("startTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "startTime", r.URL.Query(), &params.StartTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "startTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "endTime" -------------
+	if paramValue := r.URL.Query().Get("endTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "endTime", r.URL.Query(), &params.EndTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "endTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "type" -------------
+	if paramValue := r.URL.Query().Get("type"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "type", r.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "type", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetEvents(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetFederationSum operation middleware
+func (siw *ServerInterfaceWrapper) GetFederationSum(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetFederationSumParams
+
+	// ------------- Required query parameter "measurement" -------------
+	if paramValue := r.URL.Query().Get("measurement"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "measurement"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "measurement", r.URL.Query(), &params.Measurement)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "measurement", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "field" -------------
+	if paramValue := r.URL.Query().Get("field"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "field", r.URL.Query(), &params.Field)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "field", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "startTime" -------------
+	if paramValue := r.URL.Query().Get("startTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "startTime", r.URL.Query(), &params.StartTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "startTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "endTime" -------------
+	if paramValue := r.URL.Query().Get("endTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "endTime", r.URL.Query(), &params.EndTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "endTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "container" -------------
+	if paramValue := r.URL.Query().Get("container"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "container", r.URL.Query(), &params.Container)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "container", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "namespace" -------------
+	if paramValue := r.URL.Query().Get("namespace"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", r.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "pod" -------------
+	if paramValue := r.URL.Query().Get("pod"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "pod", r.URL.Query(), &params.Pod)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pod", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "groupBy" -------------
+	if paramValue := r.URL.Query().Get("groupBy"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "groupBy", r.URL.Query(), &params.GroupBy)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "groupBy", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "step" -------------
+	if paramValue := r.URL.Query().Get("step"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "step", r.URL.Query(), &params.Step)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "step", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFederationSum(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetFederationTop operation middleware
+func (siw *ServerInterfaceWrapper) GetFederationTop(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetFederationTopParams
+
+	// ------------- Required query parameter "measurement" -------------
+	if paramValue := r.URL.Query().Get("measurement"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "measurement"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "measurement", r.URL.Query(), &params.Measurement)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "measurement", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "field" -------------
+	if paramValue := r.URL.Query().Get("field"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "field", r.URL.Query(), &params.Field)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "field", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "startTime" -------------
+	if paramValue := r.URL.Query().Get("startTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "startTime", r.URL.Query(), &params.StartTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "startTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "endTime" -------------
+	if paramValue := r.URL.Query().Get("endTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "endTime", r.URL.Query(), &params.EndTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "endTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "container" -------------
+	if paramValue := r.URL.Query().Get("container"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "container", r.URL.Query(), &params.Container)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "container", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "namespace" -------------
+	if paramValue := r.URL.Query().Get("namespace"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", r.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "pod" -------------
+	if paramValue := r.URL.Query().Get("pod"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "pod", r.URL.Query(), &params.Pod)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pod", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+	if paramValue := r.URL.Query().Get("limit"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "by" -------------
+	if paramValue := r.URL.Query().Get("by"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "by", r.URL.Query(), &params.By)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "by", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFederationTop(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetFsImages operation middleware
+func (siw *ServerInterfaceWrapper) GetFsImages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFsImages(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetFsRoot operation middleware
+func (siw *ServerInterfaceWrapper) GetFsRoot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFsRoot(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostImport operation middleware
+func (siw *ServerInterfaceWrapper) PostImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.PostImportParams
+
+	// ------------- Optional query parameter "format" -------------
+	if paramValue := r.URL.Query().Get("format"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "precision" -------------
+	if paramValue := r.URL.Query().Get("precision"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "precision", r.URL.Query(), &params.Precision)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "precision", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "machine" -------------
+	if paramValue := r.URL.Query().Get("machine"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "machine", r.URL.Query(), &params.Machine)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "machine", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostImport(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetMachine operation middleware
+func (siw *ServerInterfaceWrapper) GetMachine(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetMachine(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetMetrics operation middleware
+func (siw *ServerInterfaceWrapper) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetMetrics(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
 // GetMetricsList operation middleware
 func (siw *ServerInterfaceWrapper) GetMetricsList(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -35,7 +668,226 @@ func (siw *ServerInterfaceWrapper) GetMetricsList(w http.ResponseWriter, r *http
 	var err error
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params models.GetMetricsListParams
+	var params models.GetMetricsListParams
+
+	// ------------- Optional query parameter "startTime" -------------
+	if paramValue := r.URL.Query().Get("startTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "startTime", r.URL.Query(), &params.StartTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "startTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "endTime" -------------
+	if paramValue := r.URL.Query().Get("endTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "endTime", r.URL.Query(), &params.EndTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "endTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "measurement" -------------
+	if paramValue := r.URL.Query().Get("measurement"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "measurement", r.URL.Query(), &params.Measurement)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "measurement", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "container" -------------
+	if paramValue := r.URL.Query().Get("container"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "container", r.URL.Query(), &params.Container)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "container", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "namespace" -------------
+	if paramValue := r.URL.Query().Get("namespace"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", r.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "pod" -------------
+	if paramValue := r.URL.Query().Get("pod"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "pod", r.URL.Query(), &params.Pod)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pod", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "qosClass" -------------
+	if paramValue := r.URL.Query().Get("qosClass"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "qosClass", r.URL.Query(), &params.QosClass)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "qosClass", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetMetricsList(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostPush operation middleware
+func (siw *ServerInterfaceWrapper) PostPush(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.PostPushParams
+
+	// ------------- Required query parameter "batch" -------------
+	if paramValue := r.URL.Query().Get("batch"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "batch"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "batch", r.URL.Query(), &params.Batch)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "batch", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPush(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostRestore operation middleware
+func (siw *ServerInterfaceWrapper) PostRestore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostRestore(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetStatsSummary operation middleware
+func (siw *ServerInterfaceWrapper) GetStatsSummary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetStatsSummaryParams
+
+	// ------------- Optional query parameter "time" -------------
+	if paramValue := r.URL.Query().Get("time"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "time", r.URL.Query(), &params.Time)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "time", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetStatsSummary(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetStream operation middleware
+func (siw *ServerInterfaceWrapper) GetStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetStreamParams
+
+	// ------------- Optional query parameter "container" -------------
+	if paramValue := r.URL.Query().Get("container"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "container", r.URL.Query(), &params.Container)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "container", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "measurement" -------------
+	if paramValue := r.URL.Query().Get("measurement"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "measurement", r.URL.Query(), &params.Measurement)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "measurement", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetStream(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetV1MetricsList operation middleware
+func (siw *ServerInterfaceWrapper) GetV1MetricsList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetV1MetricsListParams
 
 	// ------------- Optional query parameter "startTime" -------------
 	if paramValue := r.URL.Query().Get("startTime"); paramValue != "" {
@@ -59,8 +911,208 @@ func (siw *ServerInterfaceWrapper) GetMetricsList(w http.ResponseWriter, r *http
 		return
 	}
 
+	// ------------- Optional query parameter "measurement" -------------
+	if paramValue := r.URL.Query().Get("measurement"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "measurement", r.URL.Query(), &params.Measurement)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "measurement", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "container" -------------
+	if paramValue := r.URL.Query().Get("container"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "container", r.URL.Query(), &params.Container)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "container", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "namespace" -------------
+	if paramValue := r.URL.Query().Get("namespace"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", r.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "pod" -------------
+	if paramValue := r.URL.Query().Get("pod"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "pod", r.URL.Query(), &params.Pod)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pod", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "qosClass" -------------
+	if paramValue := r.URL.Query().Get("qosClass"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "qosClass", r.URL.Query(), &params.QosClass)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "qosClass", Err: err})
+		return
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetMetricsList(w, r, params)
+		siw.Handler.GetV1MetricsList(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetV2MetricsList operation middleware
+func (siw *ServerInterfaceWrapper) GetV2MetricsList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetV2MetricsListParams
+
+	// ------------- Optional query parameter "startTime" -------------
+	if paramValue := r.URL.Query().Get("startTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "startTime", r.URL.Query(), &params.StartTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "startTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "endTime" -------------
+	if paramValue := r.URL.Query().Get("endTime"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "endTime", r.URL.Query(), &params.EndTime)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "endTime", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "measurement" -------------
+	if paramValue := r.URL.Query().Get("measurement"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "measurement", r.URL.Query(), &params.Measurement)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "measurement", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "container" -------------
+	if paramValue := r.URL.Query().Get("container"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "container", r.URL.Query(), &params.Container)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "container", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "namespace" -------------
+	if paramValue := r.URL.Query().Get("namespace"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", r.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "namespace", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "pod" -------------
+	if paramValue := r.URL.Query().Get("pod"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "pod", r.URL.Query(), &params.Pod)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pod", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "qosClass" -------------
+	if paramValue := r.URL.Query().Get("qosClass"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "qosClass", r.URL.Query(), &params.QosClass)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "qosClass", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "format" -------------
+	if paramValue := r.URL.Query().Get("format"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "rollup" -------------
+	if paramValue := r.URL.Query().Get("rollup"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "rollup", r.URL.Query(), &params.Rollup)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "rollup", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "step" -------------
+	if paramValue := r.URL.Query().Get("step"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "step", r.URL.Query(), &params.Step)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "step", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetV2MetricsList(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetVersion operation middleware
+func (siw *ServerInterfaceWrapper) GetVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetVersion(w, r)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -183,9 +1235,72 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/alerts", wrapper.GetAlerts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/anomalies", wrapper.GetAnomalies)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/api/v1/write", wrapper.PostApiV1Write)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/backup", wrapper.PostBackup)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/cardinality", wrapper.GetCardinality)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/containers", wrapper.GetContainers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/events", wrapper.GetEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/federation/sum", wrapper.GetFederationSum)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/federation/top", wrapper.GetFederationTop)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/fs/images", wrapper.GetFsImages)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/fs/root", wrapper.GetFsRoot)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/import", wrapper.PostImport)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/machine", wrapper.GetMachine)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/metrics", wrapper.GetMetrics)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/metricsList", wrapper.GetMetricsList)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/push", wrapper.PostPush)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/restore", wrapper.PostRestore)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/stats/summary", wrapper.GetStatsSummary)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/stream", wrapper.GetStream)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/v1/metricsList", wrapper.GetV1MetricsList)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/v2/metricsList", wrapper.GetV2MetricsList)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/version", wrapper.GetVersion)
+	})
 
 	return r
 }
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"github.com/zawachte/stalker/pkg/client"
+)
+
+// command is a subcommand of stalkerctl, also run by stalker.
+type command struct {
+	usage string
+	// flags registers the flags of the command on fs.
+	flags func(fs *pflag.FlagSet)
+	run   func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error)
+	// raw replaces run for commands writing their own output, such as top
+	// driving the terminal or export streaming a file.
+	raw func(ctx context.Context, c *client.ClientWithResponses, args []string, stdout io.Writer) error
+}
+
+// tokenEnv names the environment variable holding the --token.
+const tokenEnv = "STALKER_API_TOKEN"
+
+// program is the name commands are invoked with, stalkerctl or stalker.
+var program = filepath.Base(os.Args[0])
+
+var commands = map[string]command{
+	"query":       queryCommand(),
+	"containers":  containersCommand(),
+	"events":      eventsCommand(),
+	"alerts":      alertsCommand(),
+	"backup":      backupCommand(),
+	"restore":     restoreCommand(),
+	"status":      statusCommand(),
+	"top":         topCommand(),
+	"export":      exportCommand(),
+	"import":      importCommand(),
+	"cluster-top": clusterTopCommand(),
+	"cluster-sum": clusterSumCommand(),
+}
+
+// IsCommand reports whether name is a stalkerctl command.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run runs the command named by args[0] with the remaining arguments and
+// returns the exit code of the process.
+func Run(args []string) int {
+	return run(args, os.Stdout, os.Stderr)
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		return 2
+	}
+
+	name := args[0]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", name)
+		usage(stderr)
+		return 2
+	}
+
+	var socket, server, token, output string
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&socket,
+		"socket",
+		client.DefaultSocket,
+		"unix socket of the stalker daemon",
+	)
+	fs.StringVar(&server,
+		"server",
+		"",
+		"host:port or http(s) url of the --listen-address of the daemon, used instead of the socket",
+	)
+	fs.StringVar(&token,
+		"token",
+		os.Getenv(tokenEnv),
+		"bearer token sent to the --server, defaults to $"+tokenEnv,
+	)
+	if cmd.raw == nil {
+		fs.StringVarP(&output,
+			"output",
+			"o",
+			formatTable,
+			"output format, table, json, csv or lp",
+		)
+	}
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s %s\n\nflags:\n%s", program, cmd.usage, fs.FlagUsages())
+	}
+
+	err := fs.Parse(args[1:])
+	if errors.Is(err, pflag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	if cmd.raw == nil && !validFormat(output) {
+		fmt.Fprintf(stderr, "unknown output format %q\n", output)
+		return 2
+	}
+
+	opts := []client.ClientOption{}
+	if token != "" {
+		opts = append(opts, client.WithBearerToken(token))
+	}
+	var c *client.ClientWithResponses
+	if server != "" {
+		c, err = client.NewTCPClient(server, nil, opts...)
+	} else {
+		c, err = client.NewUnixClient(socket, opts...)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+
+	if cmd.raw != nil {
+		err = cmd.raw(context.Background(), c, fs.Args(), stdout)
+	} else {
+		var res *result
+		res, err = cmd.run(context.Background(), c, fs.Args())
+		if err == nil {
+			for _, warning := range res.warnings {
+				fmt.Fprintf(stderr, "%s: %s\n", name, warning)
+			}
+			err = res.write(stdout, output)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return 1
+	}
+
+	return 0
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "usage: %s <command> [flags]\n\ncommands:\n", program)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintf(w, "\nrun %s <command> --help for the flags of a command\n", program)
+}
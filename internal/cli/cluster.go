@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/spf13/pflag"
+	"github.com/zawachte/stalker/pkg/client"
+)
+
+// clusterFlags select the series of the cluster-wide queries of an
+// aggregator.
+type clusterFlags struct {
+	since, until string
+	field        string
+	containers   []string
+	namespaces   []string
+	pods         []string
+}
+
+func (f *clusterFlags) register(fs *pflag.FlagSet) {
+	fs.StringVar(&f.since, "since", "15m", "start of the period, a duration ago, an RFC 3339 timestamp or unix seconds")
+	fs.StringVar(&f.until, "until", "now", "end of the period, a duration ago, an RFC 3339 timestamp or unix seconds")
+	fs.StringVar(&f.field, "field", "value", "field of the measurement")
+	fs.StringSliceVar(&f.containers, "container", nil, "container names of the series, defaults to all")
+	fs.StringSliceVar(&f.namespaces, "namespace", nil, "kubernetes namespaces of the series, defaults to all")
+	fs.StringSliceVar(&f.pods, "pod", nil, "kubernetes pods of the series, defaults to all")
+}
+
+// params returns the query of the measurement given as the only argument in
+// the period of the flags.
+func (f *clusterFlags) params(args []string) (*client.GetFederationTopParams, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected a measurement")
+	}
+	start, stop, err := timeRange(f.since, f.until)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &client.GetFederationTopParams{
+		Measurement: args[0],
+		Field:       &f.field,
+		StartTime:   &start,
+		EndTime:     &stop,
+	}
+	if len(f.containers) > 0 {
+		params.Container = &f.containers
+	}
+	if len(f.namespaces) > 0 {
+		params.Namespace = &f.namespaces
+	}
+	if len(f.pods) > 0 {
+		params.Pod = &f.pods
+	}
+	return params, nil
+}
+
+func clusterTopCommand() command {
+	var flags clusterFlags
+	var limit int
+	var by string
+
+	return command{
+		usage: "cluster-top [--limit 10] [--by last|mean|max|rate] [--since 15m] [--until now] [--field value] [--container name]... [--namespace name]... [--pod name]... <measurement>",
+		flags: func(fs *pflag.FlagSet) {
+			flags.register(fs)
+			fs.IntVar(&limit, "limit", 10, "number of series to list")
+			fs.StringVar(&by, "by", "last", "value series are ranked by, their last sample, mean, max or the per second rate of a counter")
+		},
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			params, err := flags.params(args)
+			if err != nil {
+				return nil, err
+			}
+			ranking := client.GetFederationTopParamsBy(by)
+			params.Limit = &limit
+			params.By = &ranking
+
+			resp, err := c.GetFederationTopWithResponse(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			res := &result{
+				value:    resp.JSON200,
+				columns:  []string{"NODE", "MEASUREMENT", "FIELD", "VALUE", "TAGS"},
+				warnings: nodeWarnings(resp.JSON200.Errors),
+			}
+			for _, entry := range resp.JSON200.Entries {
+				res.rows = append(res.rows, []string{
+					entry.Node, entry.Measurement, entry.Field, strconv.FormatFloat(entry.Value, 'f', -1, 64), formatTags(entry.Tags.AdditionalProperties),
+				})
+			}
+			return res, nil
+		},
+	}
+}
+
+func clusterSumCommand() command {
+	var flags clusterFlags
+	var groupBy []string
+	var step time.Duration
+
+	return command{
+		usage: "cluster-sum [--group-by tag]... [--step 10s] [--since 15m] [--until now] [--field value] [--container name]... [--namespace name]... [--pod name]... <measurement>",
+		flags: func(fs *pflag.FlagSet) {
+			flags.register(fs)
+			fs.StringSliceVar(&groupBy, "group-by", nil, "tags the sums are grouped by, such as node or namespace")
+			fs.DurationVar(&step, "step", 10*time.Second, "window of the samples summed")
+		},
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			query, err := flags.params(args)
+			if err != nil {
+				return nil, err
+			}
+			if step < time.Second {
+				return nil, fmt.Errorf("--step must be at least 1s")
+			}
+			seconds := int(step / time.Second)
+			params := &client.GetFederationSumParams{
+				Measurement: query.Measurement,
+				Field:       query.Field,
+				StartTime:   query.StartTime,
+				EndTime:     query.EndTime,
+				Container:   query.Container,
+				Namespace:   query.Namespace,
+				Pod:         query.Pod,
+				Step:        &seconds,
+			}
+			if len(groupBy) > 0 {
+				params.GroupBy = &groupBy
+			}
+
+			resp, err := c.GetFederationSumWithResponse(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			res := &result{
+				value:    resp.JSON200,
+				columns:  []string{"TIME", "MEASUREMENT", "FIELD", "VALUE", "TAGS"},
+				points:   []*write.Point{},
+				warnings: nodeWarnings(resp.JSON200.Errors),
+			}
+			for _, series := range resp.JSON200.Series {
+				tags := series.Tags.AdditionalProperties
+				for _, point := range series.Points {
+					res.rows = append(res.rows, []string{
+						formatTime(point.Timestamp), series.Measurement, series.Field, strconv.FormatFloat(point.Value, 'f', -1, 64), formatTags(tags),
+					})
+					res.points = append(res.points, write.NewPoint(series.Measurement, tags, map[string]interface{}{series.Field: point.Value}, point.Timestamp))
+				}
+			}
+			return res, nil
+		},
+	}
+}
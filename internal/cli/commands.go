@@ -0,0 +1,384 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/spf13/pflag"
+	"github.com/zawachte/stalker/pkg/client"
+)
+
+func queryCommand() command {
+	var since, until string
+	var measurements, containers []string
+	var pods podFlags
+	var rollup bool
+	var step time.Duration
+
+	return command{
+		usage: "query [--since 15m] [--until now] [--measurement name]... [--container name]... [--namespace name]... [--pod name]... [--qos-class class]... [--rollup-pods [--step 10s]]",
+		flags: func(fs *pflag.FlagSet) {
+			fs.StringVar(&since, "since", "15m", "start of the period, a duration ago, an RFC 3339 timestamp or unix seconds")
+			fs.StringVar(&until, "until", "now", "end of the period, a duration ago, an RFC 3339 timestamp or unix seconds")
+			fs.StringSliceVar(&measurements, "measurement", nil, "measurements to return, defaults to all")
+			fs.StringSliceVar(&containers, "container", nil, "container names to return, defaults to all")
+			pods.register(fs)
+			fs.BoolVar(&rollup, "rollup-pods", false, "sum the containers of every kubernetes pod")
+			fs.DurationVar(&step, "step", 10*time.Second, "window of the samples summed by --rollup-pods")
+		},
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			start, stop, err := timeRange(since, until)
+			if err != nil {
+				return nil, err
+			}
+			params := &client.GetV2MetricsListParams{
+				StartTime: &start,
+				EndTime:   &stop,
+			}
+			if len(measurements) > 0 {
+				params.Measurement = &measurements
+			}
+			if len(containers) > 0 {
+				params.Container = &containers
+			}
+			pods.apply(&params.Namespace, &params.Pod, &params.QosClass)
+			if rollup {
+				if step < time.Second {
+					return nil, fmt.Errorf("--step must be at least 1s")
+				}
+				r := client.GetV2MetricsListParamsRollup("pod")
+				seconds := int(step / time.Second)
+				params.Rollup = &r
+				params.Step = &seconds
+			}
+
+			resp, err := c.GetV2MetricsListWithResponse(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			res := &result{
+				value:    resp.JSON200,
+				columns:  []string{"TIME", "MEASUREMENT", "FIELD", "VALUE", "TAGS"},
+				points:   []*write.Point{},
+				warnings: nodeWarnings(resp.JSON200.Errors),
+			}
+			for _, series := range resp.JSON200.Series {
+				tags := series.Tags.AdditionalProperties
+				for _, point := range series.Points {
+					res.rows = append(res.rows, []string{
+						formatTime(point.Timestamp), series.Measurement, series.Field, strconv.FormatFloat(point.Value, 'f', -1, 64), formatTags(tags),
+					})
+					res.points = append(res.points, write.NewPoint(series.Measurement, tags, map[string]interface{}{series.Field: point.Value}, point.Timestamp))
+				}
+			}
+			return res, nil
+		},
+	}
+}
+
+func containersCommand() command {
+	return command{
+		usage: "containers",
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			resp, err := c.GetContainersWithResponse(ctx)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			res := &result{
+				value:   resp.JSON200,
+				columns: []string{"NAME", "IMAGE", "CREATED", "CGROUP"},
+			}
+			for _, container := range resp.JSON200.Containers {
+				res.rows = append(res.rows, []string{
+					container.Name, formatString(container.Image), formatTimePtr(container.CreatedAt), container.Cgroup,
+				})
+			}
+			return res, nil
+		},
+	}
+}
+
+func eventsCommand() command {
+	var since, until string
+	var types []string
+
+	return command{
+		usage: "events [--since 1h] [--until now] [--type oom|oomKill|containerCreation|containerDeletion]...",
+		flags: func(fs *pflag.FlagSet) {
+			fs.StringVar(&since, "since", "1h", "start of the period, a duration ago, an RFC 3339 timestamp or unix seconds")
+			fs.StringVar(&until, "until", "now", "end of the period, a duration ago, an RFC 3339 timestamp or unix seconds")
+			fs.StringSliceVar(&types, "type", nil, "event types to return, defaults to all")
+		},
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			start, stop, err := timeRange(since, until)
+			if err != nil {
+				return nil, err
+			}
+			params := &client.GetEventsParams{
+				StartTime: &start,
+				EndTime:   &stop,
+			}
+			if len(types) > 0 {
+				eventTypes := []client.GetEventsParamsType{}
+				for _, t := range types {
+					eventTypes = append(eventTypes, client.GetEventsParamsType(t))
+				}
+				params.Type = &eventTypes
+			}
+
+			resp, err := c.GetEventsWithResponse(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			events := []client.Event{}
+			if resp.JSON200.Events != nil {
+				events = *resp.JSON200.Events
+			}
+
+			res := &result{
+				value:   events,
+				columns: []string{"TIME", "TYPE", "CONTAINER", "PROCESS"},
+				points:  []*write.Point{},
+			}
+			for _, event := range events {
+				process := ""
+				if event.ProcessName != nil {
+					process = *event.ProcessName
+				}
+				if event.Pid != nil {
+					process = fmt.Sprintf("%s[%d]", process, *event.Pid)
+				}
+				res.rows = append(res.rows, []string{
+					formatTime(event.Timestamp), event.Type, event.ContainerName, process,
+				})
+				res.points = append(res.points, eventToPoint(event))
+			}
+			return res, nil
+		},
+	}
+}
+
+// eventToPoint converts an event back into a point of the events measurement.
+func eventToPoint(event client.Event) *write.Point {
+	tags := map[string]string{}
+	if event.Tags != nil {
+		for k, v := range event.Tags.AdditionalProperties {
+			tags[k] = v
+		}
+	}
+	tags["event_type"] = event.Type
+	tags["container_name"] = event.ContainerName
+
+	fields := map[string]interface{}{
+		"value": int64(1),
+	}
+	if event.Pid != nil {
+		fields["pid"] = *event.Pid
+	}
+	if event.ProcessName != nil {
+		fields["process_name"] = *event.ProcessName
+	}
+
+	return write.NewPoint("events", tags, fields, event.Timestamp)
+}
+
+func alertsCommand() command {
+	var state string
+
+	return command{
+		usage: "alerts [--state pending|firing|resolved]",
+		flags: func(fs *pflag.FlagSet) {
+			fs.StringVar(&state, "state", "", "only return alerts in this state")
+		},
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			params := &client.GetAlertsParams{}
+			if state != "" {
+				s := client.GetAlertsParamsState(state)
+				params.State = &s
+			}
+
+			resp, err := c.GetAlertsWithResponse(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			res := &result{
+				value:   resp.JSON200,
+				columns: []string{"RULE", "STATE", "VALUE", "ACTIVE", "LABELS"},
+			}
+			for _, alert := range resp.JSON200.Alerts {
+				res.rows = append(res.rows, []string{
+					alert.Rule,
+					string(alert.State),
+					strconv.FormatFloat(alert.Value, 'g', -1, 64),
+					formatTime(alert.ActiveAt),
+					formatTags(alert.Labels.AdditionalProperties),
+				})
+			}
+			return res, nil
+		},
+	}
+}
+
+func backupCommand() command {
+	return command{
+		usage: "backup",
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			resp, err := c.PostBackupWithResponse(ctx)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			return backupResult(resp.JSON200), nil
+		},
+	}
+}
+
+func restoreCommand() command {
+	return command{
+		usage: "restore [path]",
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			if len(args) > 1 {
+				return nil, fmt.Errorf("expected at most one backup path")
+			}
+			body := client.PostRestoreJSONRequestBody{}
+			if len(args) == 1 {
+				body.Path = &args[0]
+			}
+
+			resp, err := c.PostRestoreWithResponse(ctx, body)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			return backupResult(resp.JSON200), nil
+		},
+	}
+}
+
+func backupResult(backup *client.Backup) *result {
+	return &result{
+		value:   backup,
+		columns: []string{"PATH", "TIMESTAMP"},
+		rows:    [][]string{{backup.Path, formatTime(backup.Timestamp)}},
+	}
+}
+
+// status summarizes the daemon and the machine it monitors.
+type status struct {
+	Version      client.VersionInfo `json:"version"`
+	Machine      client.MachineInfo `json:"machine"`
+	FiringAlerts int                `json:"firingAlerts"`
+}
+
+func statusCommand() command {
+	return command{
+		usage: "status",
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			version, err := c.GetVersionWithResponse(ctx)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(version.Status(), version.Body, version.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			machine, err := c.GetMachineWithResponse(ctx)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(machine.Status(), machine.Body, machine.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			firing := client.GetAlertsParamsState("firing")
+			alerts, err := c.GetAlertsWithResponse(ctx, &client.GetAlertsParams{State: &firing})
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(alerts.Status(), alerts.Body, alerts.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			s := status{
+				Version:      *version.JSON200,
+				Machine:      *machine.JSON200,
+				FiringAlerts: len(alerts.JSON200.Alerts),
+			}
+
+			fields := [][]string{
+				{"version", s.Version.Version},
+				{"commit", s.Version.Commit},
+				{"built", s.Version.Date},
+				{"cadvisor", formatString(s.Version.CadvisorVersion)},
+				{"kernel", formatString(s.Version.KernelVersion)},
+				{"os", formatString(s.Version.ContainerOsVersion)},
+				{"cores", strconv.Itoa(s.Machine.NumCores)},
+				{"memory", formatBytes(s.Machine.MemoryCapacity)},
+				{"firing alerts", strconv.Itoa(s.FiringAlerts)},
+			}
+
+			return &result{
+				value:   s,
+				columns: []string{"FIELD", "VALUE"},
+				rows:    fields,
+			}, nil
+		},
+	}
+}
+
+// podFlags filter metrics by kubernetes pod.
+type podFlags struct {
+	namespaces, pods, qosClasses []string
+}
+
+func (f *podFlags) register(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&f.namespaces, "namespace", nil, "kubernetes namespaces to return, defaults to all")
+	fs.StringSliceVar(&f.pods, "pod", nil, "kubernetes pods to return, defaults to all")
+	fs.StringSliceVar(&f.qosClasses, "qos-class", nil, "kubernetes QoS classes to return, Guaranteed, Burstable or BestEffort, defaults to all")
+}
+
+func (f *podFlags) apply(namespaces, pods, qosClasses **[]string) {
+	if len(f.namespaces) > 0 {
+		*namespaces = &f.namespaces
+	}
+	if len(f.pods) > 0 {
+		*pods = &f.pods
+	}
+	if len(f.qosClasses) > 0 {
+		*qosClasses = &f.qosClasses
+	}
+}
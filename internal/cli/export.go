@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/spf13/pflag"
+	"github.com/zawachte/stalker/pkg/client"
+)
+
+func exportCommand() command {
+	var since, until, format, file string
+	var measurements, containers []string
+	var pods podFlags
+
+	return command{
+		usage: "export [--format csv|lp|arrow|parquet|json] [--since 1h] [--until now] [--measurement name]... [--container name]... [--namespace name]... [--pod name]... [--qos-class class]... [-f file]",
+		flags: func(fs *pflag.FlagSet) {
+			fs.StringVar(&format, "format", "csv", "format of the export, csv, lp, arrow, parquet or json")
+			fs.StringVar(&since, "since", "1h", "start of the period, a duration ago, an RFC 3339 timestamp or unix seconds")
+			fs.StringVar(&until, "until", "now", "end of the period, a duration ago, an RFC 3339 timestamp or unix seconds")
+			fs.StringSliceVar(&measurements, "measurement", nil, "measurements to export, defaults to all")
+			fs.StringSliceVar(&containers, "container", nil, "container names to export, defaults to all")
+			pods.register(fs)
+			fs.StringVarP(&file, "file", "f", "-", "file the export is written to, - for stdout")
+		},
+		raw: func(ctx context.Context, c *client.ClientWithResponses, args []string, stdout io.Writer) error {
+			start, stop, err := timeRange(since, until)
+			if err != nil {
+				return err
+			}
+			f := client.GetV2MetricsListParamsFormat(format)
+			params := &client.GetV2MetricsListParams{
+				StartTime: &start,
+				EndTime:   &stop,
+				Format:    &f,
+			}
+			if len(measurements) > 0 {
+				params.Measurement = &measurements
+			}
+			if len(containers) > 0 {
+				params.Container = &containers
+			}
+			pods.apply(&params.Namespace, &params.Pod, &params.QosClass)
+
+			resp, err := c.ClientInterface.GetV2MetricsList(ctx, params)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				return client.CheckResponse(resp.Status, body, false)
+			}
+
+			if file == "-" {
+				return copyExport(stdout, resp.Body)
+			}
+
+			// The file is created once the daemon accepted the export, so
+			// failed requests leave no empty file behind.
+			w, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			err = copyExport(w, resp.Body)
+			if err != nil {
+				w.Close()
+				return err
+			}
+			return w.Close()
+		},
+	}
+}
+
+// copyExport copies the streamed export to w. The daemon aborts the
+// response when the export fails midway, which surfaces here.
+func copyExport(w io.Writer, body io.Reader) error {
+	_, err := io.Copy(w, body)
+	if err != nil {
+		return fmt.Errorf("export interrupted: %w", err)
+	}
+	return nil
+}
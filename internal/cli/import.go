@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/zawachte/stalker/pkg/client"
+)
+
+func importCommand() command {
+	var format, precision, machine string
+
+	return command{
+		usage: "import [--format lp|json] [--precision ns|us|ms|s] [--machine name] <file>",
+		flags: func(fs *pflag.FlagSet) {
+			fs.StringVar(&format, "format", "", "format of the file, lp or json, defaults to json for .json files and lp otherwise")
+			fs.StringVar(&precision, "precision", "ns", "precision of line protocol timestamps, ns, us, ms or s")
+			fs.StringVar(&machine, "machine", "", "machine_name tag of the points converted from json, defaults to the hostname of the daemon")
+		},
+		run: func(ctx context.Context, c *client.ClientWithResponses, args []string) (*result, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("expected the file to import, - for stdin")
+			}
+			file := args[0]
+
+			var body io.Reader = os.Stdin
+			if file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					return nil, err
+				}
+				defer f.Close()
+				body = f
+			}
+
+			// Files are sent as they are, compressed ones are decompressed by
+			// the daemon.
+			name := strings.TrimSuffix(file, ".gz")
+			if format == "" {
+				format = "lp"
+				if strings.HasSuffix(name, ".json") {
+					format = "json"
+				}
+			}
+			contentType := "text/plain; charset=utf-8"
+			if format == "json" {
+				contentType = "application/json"
+			}
+
+			f := client.PostImportParamsFormat(format)
+			p := client.PostImportParamsPrecision(precision)
+			params := &client.PostImportParams{
+				Format:    &f,
+				Precision: &p,
+			}
+			if machine != "" {
+				params.Machine = &machine
+			}
+
+			editors := []client.RequestEditorFn{}
+			if name != file {
+				editors = append(editors, func(ctx context.Context, req *http.Request) error {
+					req.Header.Set("Content-Encoding", "gzip")
+					return nil
+				})
+			}
+
+			resp, err := c.PostImportWithBodyWithResponse(ctx, params, contentType, body, editors...)
+			if err != nil {
+				return nil, err
+			}
+			err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+			if err != nil {
+				return nil, err
+			}
+
+			return &result{
+				value:   resp.JSON200,
+				columns: []string{"FILE", "POINTS"},
+				rows:    [][]string{{file, strconv.FormatInt(resp.JSON200.Points, 10)}},
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/client"
+)
+
+// Output formats
+const (
+	formatTable        = "table"
+	formatJson         = "json"
+	formatCsv          = "csv"
+	formatLineProtocol = "lp"
+)
+
+func validFormat(format string) bool {
+	switch format {
+	case formatTable, formatJson, formatCsv, formatLineProtocol:
+		return true
+	}
+	return false
+}
+
+// result is the output of a command, rendered in the requested format.
+type result struct {
+	// value is encoded by the json format.
+	value interface{}
+	// columns and rows are rendered by the table and csv formats.
+	columns []string
+	rows    [][]string
+	// points are rendered by the line protocol format, which is not supported
+	// by commands without points.
+	points []*write.Point
+	// warnings are printed to stderr, such as the agents of an aggregator
+	// that failed to answer.
+	warnings []string
+}
+
+func (r *result) write(w io.Writer, format string) error {
+	switch format {
+	case formatJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r.value)
+	case formatCsv:
+		cw := csv.NewWriter(w)
+		cw.Write(r.columns)
+		cw.WriteAll(r.rows)
+		return cw.Error()
+	case formatLineProtocol:
+		if r.points == nil {
+			return fmt.Errorf("line protocol output is only supported by query, events and cluster-sum")
+		}
+		for _, point := range r.points {
+			_, err := io.WriteString(w, write.PointToLineProtocol(point, time.Nanosecond))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(r.columns, "\t"))
+		for _, row := range r.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// formatTime renders timestamps in table and csv output.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+func formatString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// formatTags renders tags as sorted, comma separated key=value pairs.
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// formatBytes renders a byte count with a binary unit.
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// nodeWarnings returns the warnings of the agents of an aggregator that failed
+// to answer.
+func nodeWarnings(nodeErrors *[]client.NodeError) []string {
+	if nodeErrors == nil {
+		return nil
+	}
+	warnings := []string{}
+	for _, nodeError := range *nodeErrors {
+		warnings = append(warnings, fmt.Sprintf("node %s failed: %s", nodeError.Node, nodeError.Error))
+	}
+	return warnings
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTime parses a point in time given relative to now as a duration like
+// 15m or 2d, as an RFC 3339 timestamp or in unix seconds.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" || value == "now" {
+		return now, nil
+	}
+
+	d, err := parseDuration(value)
+	if err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a duration like 15m, an RFC 3339 timestamp or unix seconds", value)
+}
+
+// parseDuration extends time.ParseDuration with a d suffix for days.
+func parseDuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(value, "d"), 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+// timeRange returns the unix seconds of the period selected by the since and
+// until flags.
+func timeRange(since, until string) (int, int, error) {
+	now := time.Now()
+	start, err := parseTime(since, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	stop, err := parseTime(until, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !start.Before(stop) {
+		return 0, 0, fmt.Errorf("--since must be before --until")
+	}
+
+	return int(start.Unix()), int(stop.Unix()), nil
+}
@@ -0,0 +1,465 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/pflag"
+	"github.com/zawachte/stalker/pkg/client"
+	"golang.org/x/term"
+)
+
+// Orders containers are sorted in.
+const (
+	sortCPU     = "cpu"
+	sortMemory  = "memory"
+	sortNetwork = "network"
+	sortDisk    = "disk"
+	sortName    = "name"
+)
+
+// ANSI escape sequences.
+const (
+	escAltScreen     = "\x1b[?1049h"
+	escMainScreen    = "\x1b[?1049l"
+	escHideCursor    = "\x1b[?25l"
+	escShowCursor    = "\x1b[?25h"
+	escHome          = "\x1b[H"
+	escClearLine     = "\x1b[K"
+	escClearToEnd    = "\x1b[J"
+	escReverse       = "\x1b[7m"
+	escBold          = "\x1b[1m"
+	escReset         = "\x1b[0m"
+	keyUp            = "\x1b[A"
+	keyDown          = "\x1b[B"
+	keyEscape        = "\x1b"
+	keyEnter         = "\r"
+	keyBackspace     = "\x7f"
+	keyCtrlC         = "\x03"
+	sparklineSymbols = "▁▂▃▄▅▆▇█"
+)
+
+func topCommand() command {
+	var interval, history time.Duration
+	var sortBy string
+
+	return command{
+		usage: "top [--interval 5s] [--history 10m] [--sort cpu|memory|network|disk|name]",
+		flags: func(fs *pflag.FlagSet) {
+			fs.DurationVar(&interval, "interval", 5*time.Second, "period between refreshes, new samples appear once the daemon scraped them")
+			fs.DurationVar(&history, "history", 10*time.Minute, "stored history shown by the sparklines of a container")
+			fs.StringVar(&sortBy, "sort", sortCPU, "initial order of the containers, cpu, memory, network, disk or name")
+		},
+		raw: func(ctx context.Context, c *client.ClientWithResponses, args []string, _ io.Writer) error {
+			switch sortBy {
+			case sortCPU, sortMemory, sortNetwork, sortDisk, sortName:
+			default:
+				return fmt.Errorf("unknown sort order %q", sortBy)
+			}
+			if interval <= 0 || history <= 0 {
+				return fmt.Errorf("--interval and --history must be positive")
+			}
+
+			t := &top{
+				client:   c,
+				interval: interval,
+				history:  newUsageHistory(history),
+				sortBy:   sortBy,
+			}
+			return t.run(ctx)
+		},
+	}
+}
+
+// top is an interactive view of the resource usage of the containers.
+type top struct {
+	client   *client.ClientWithResponses
+	interval time.Duration
+	history  *usageHistory
+	err      error
+
+	sortBy  string
+	reverse bool
+	// filter keeps the containers whose name contains it, it is edited while
+	// filtering is set.
+	filter    string
+	filtering bool
+	// selected is the name of the highlighted container, detail whether it is
+	// drilled into.
+	selected string
+	detail   bool
+}
+
+func (t *top) run(ctx context.Context) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("top requires a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	os.Stdout.WriteString(escAltScreen + escHideCursor)
+	defer os.Stdout.WriteString(escShowCursor + escMainScreen)
+
+	keys := make(chan string)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- string(buf[:n])
+		}
+	}()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	t.refresh(ctx)
+	for {
+		t.render()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			t.refresh(ctx)
+		case input, ok := <-keys:
+			if !ok || !t.handle(input) {
+				return nil
+			}
+		}
+	}
+}
+
+// refresh queries the samples stored since the previous refresh.
+func (t *top) refresh(ctx context.Context) {
+	now := time.Now()
+	start := now.Add(-t.history.window)
+	if t.history.latest.After(start) {
+		start = t.history.latest
+	}
+	// The api takes whole seconds, the period must not be empty.
+	startTime, endTime := int(start.Unix()), int(now.Unix())+1
+	measurements := usageMeasurements
+
+	resp, err := t.client.GetV2MetricsListWithResponse(ctx, &client.GetV2MetricsListParams{
+		StartTime:   &startTime,
+		EndTime:     &endTime,
+		Measurement: &measurements,
+	})
+	if err == nil {
+		err = client.CheckResponse(resp.Status(), resp.Body, resp.JSON200 != nil)
+	}
+	if err != nil {
+		t.err = err
+		return
+	}
+
+	t.history.add(resp.JSON200.Series, now)
+	t.err = nil
+}
+
+// handle applies the keys of input and reports whether top keeps running.
+func (t *top) handle(input string) bool {
+	if input == keyCtrlC {
+		return false
+	}
+
+	if t.filtering {
+		switch input {
+		case keyEnter:
+			t.filtering = false
+		case keyEscape:
+			t.filtering = false
+			t.filter = ""
+		case keyBackspace:
+			if t.filter != "" {
+				_, size := utf8.DecodeLastRuneInString(t.filter)
+				t.filter = t.filter[:len(t.filter)-size]
+			}
+		default:
+			if !strings.HasPrefix(input, keyEscape) && utf8.ValidString(input) && input[0] >= ' ' {
+				t.filter += input
+			}
+		}
+		return true
+	}
+
+	switch input {
+	case "q":
+		return false
+	case keyEscape, keyBackspace:
+		t.detail = false
+	case keyEnter:
+		t.detail = t.selected != ""
+	case keyUp, "k":
+		t.move(-1)
+	case keyDown, "j":
+		t.move(1)
+	case "/":
+		t.filtering = true
+		t.detail = false
+	case "c":
+		t.sortBy = sortCPU
+	case "m":
+		t.sortBy = sortMemory
+	case "n":
+		t.sortBy = sortNetwork
+	case "d":
+		t.sortBy = sortDisk
+	case "s":
+		t.sortBy = sortName
+	case "r":
+		t.reverse = !t.reverse
+	}
+	return true
+}
+
+// move moves the selection by delta rows.
+func (t *top) move(delta int) {
+	containers := t.containers()
+	if len(containers) == 0 {
+		return
+	}
+
+	i := 0
+	for j, u := range containers {
+		if u.name == t.selected {
+			i = j + delta
+		}
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(containers) {
+		i = len(containers) - 1
+	}
+	t.selected = containers[i].name
+}
+
+// containers returns the containers matching the filter in the selected
+// order.
+func (t *top) containers() []*containerUsage {
+	containers := []*containerUsage{}
+	for _, u := range t.history.containers {
+		if strings.Contains(u.name, t.filter) {
+			containers = append(containers, u)
+		}
+	}
+
+	key := func(u *containerUsage) float64 {
+		switch t.sortBy {
+		case sortMemory:
+			return u.latest(usageMemory)
+		case sortNetwork:
+			return u.latest(usageRx) + u.latest(usageTx)
+		case sortDisk:
+			return u.latest(usageDisk)
+		default:
+			return u.latest(usageCPU)
+		}
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		a, b := containers[i], containers[j]
+		if t.reverse {
+			a, b = b, a
+		}
+		if t.sortBy != sortName {
+			ka, kb := key(a), key(b)
+			if ka != kb {
+				return ka > kb
+			}
+		}
+		return a.name < b.name
+	})
+
+	return containers
+}
+
+func (t *top) render() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+
+	lines := []string{}
+	status := fmt.Sprintf("stalker top - %s - %d containers - sort: %s", time.Now().Format("15:04:05"), len(t.history.containers), t.sortBy)
+	if t.reverse {
+		status += " (reversed)"
+	}
+	if t.filter != "" || t.filtering {
+		status += " - filter: " + t.filter
+	}
+	lines = append(lines, escBold+truncate(status, width)+escReset)
+	if t.err != nil {
+		lines = append(lines, truncate("error: "+t.err.Error(), width))
+	} else {
+		lines = append(lines, "")
+	}
+
+	var help string
+	containers := t.containers()
+	selected := t.find(containers)
+	if t.detail && selected != nil {
+		lines = append(lines, t.renderDetail(selected, width)...)
+		help = "esc back  q quit"
+	} else {
+		t.detail = false
+		lines = append(lines, t.renderList(containers, width, height-len(lines)-1)...)
+		help = "↑↓ select  enter details  / filter  c m n d s sort  r reverse  q quit"
+		if t.filtering {
+			help = "type to filter  enter apply  esc clear"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(escHome)
+	for i, line := range lines {
+		if i >= height-1 {
+			break
+		}
+		b.WriteString(line + escClearLine + "\r\n")
+	}
+	b.WriteString(escClearToEnd)
+	fmt.Fprintf(&b, "\x1b[%d;1H%s%s", height, truncate(help, width), escClearLine)
+	os.Stdout.WriteString(b.String())
+}
+
+// find returns the selected container, selecting the first one when the
+// selection is gone.
+func (t *top) find(containers []*containerUsage) *containerUsage {
+	for _, u := range containers {
+		if u.name == t.selected {
+			return u
+		}
+	}
+	if len(containers) == 0 {
+		t.selected = ""
+		return nil
+	}
+	t.selected = containers[0].name
+	return containers[0]
+}
+
+const rowFormat = "%-*s %7s %9s %9s %6s %10s %10s %10s"
+
+func (t *top) renderList(containers []*containerUsage, width, rows int) []string {
+	nameWidth := width - 68
+	if nameWidth < 20 {
+		nameWidth = 20
+	}
+
+	header := fmt.Sprintf(rowFormat, nameWidth, "NAME", "CPU", "MEM", "LIMIT", "MEM%", "NET RX/s", "NET TX/s", "DISK/s")
+	lines := []string{escReverse + truncate(header, width) + escReset}
+
+	// Scroll the selection into view.
+	offset := 0
+	for i, u := range containers {
+		if u.name == t.selected && i >= rows-1 {
+			offset = i - rows + 2
+		}
+	}
+
+	for _, u := range containers[offset:] {
+		if len(lines) >= rows {
+			break
+		}
+		limit, percent := "-", "-"
+		if u.latest(usageMemoryLimit) > 0 {
+			limit = formatBytes(int64(u.latest(usageMemoryLimit)))
+			percent = fmt.Sprintf("%.1f", u.memoryPercent())
+		}
+		line := truncate(fmt.Sprintf(rowFormat, nameWidth, truncate(u.name, nameWidth),
+			fmt.Sprintf("%.2f", u.cores()),
+			formatBytes(int64(u.latest(usageMemory))),
+			limit,
+			percent,
+			formatBytes(int64(u.latest(usageRx))),
+			formatBytes(int64(u.latest(usageTx))),
+			formatBytes(int64(u.latest(usageDisk))),
+		), width)
+		if u.name == t.selected {
+			line = escReverse + line + escReset
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func (t *top) renderDetail(u *containerUsage, width int) []string {
+	lines := []string{escBold + truncate(u.name, width) + escReset, ""}
+
+	graph := func(title string, usage int, current string, scale float64) {
+		lines = append(lines, truncate(fmt.Sprintf("%-14s %s", title, current), width))
+		lines = append(lines, sparkline(u.usages[usage], scale, width), "")
+	}
+
+	memory := formatBytes(int64(u.latest(usageMemory)))
+	if limit := u.latest(usageMemoryLimit); limit > 0 {
+		memory += fmt.Sprintf(" of %s (%.1f%%)", formatBytes(int64(limit)), u.memoryPercent())
+	}
+
+	graph("cpu cores", usageCPU, fmt.Sprintf("%.2f", u.cores()), 0)
+	graph("memory", usageMemory, memory, u.latest(usageMemoryLimit))
+	graph("network rx/s", usageRx, formatBytes(int64(u.latest(usageRx))), 0)
+	graph("network tx/s", usageTx, formatBytes(int64(u.latest(usageTx))), 0)
+	graph("disk io/s", usageDisk, formatBytes(int64(u.latest(usageDisk))), 0)
+
+	return lines
+}
+
+// sparkline renders the latest samples that fit the width. Bars are relative
+// to scale, or to the largest sample when scale is 0.
+func sparkline(samples []sample, scale float64, width int) string {
+	if len(samples) > width {
+		samples = samples[len(samples)-width:]
+	}
+
+	max := scale
+	if max == 0 {
+		for _, s := range samples {
+			if s.value > max {
+				max = s.value
+			}
+		}
+	}
+
+	symbols := []rune(sparklineSymbols)
+	var b strings.Builder
+	for _, s := range samples {
+		i := 0
+		if max > 0 {
+			i = int(s.value / max * float64(len(symbols)-1))
+		}
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(symbols) {
+			i = len(symbols) - 1
+		}
+		b.WriteRune(symbols[i])
+	}
+	return b.String()
+}
+
+// truncate cuts s to width runes.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width])
+}
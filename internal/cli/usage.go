@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zawachte/stalker/pkg/client"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+// Resources shown by top, indexing usageMeasurements.
+const (
+	usageCPU = iota
+	usageMemory
+	usageMemoryLimit
+	usageRx
+	usageTx
+	usageDisk
+	numUsages
+)
+
+// usageMeasurements are the measurements top queries, cumulative ones are
+// shown as per second rates summed over the series of a container.
+var usageMeasurements = []string{
+	"cpu_usage_total",
+	"memory_working_set",
+	"memory_limit",
+	"rx_bytes",
+	"tx_bytes",
+	"disk_io_bytes",
+}
+
+const tagContainerName = "container_name"
+
+type sample struct {
+	time  time.Time
+	value float64
+}
+
+// containerUsage is the recent resource usage of a container.
+type containerUsage struct {
+	name   string
+	usages [numUsages][]sample
+}
+
+// latest returns the latest value of a resource, 0 when it has no samples.
+func (u *containerUsage) latest(usage int) float64 {
+	samples := u.usages[usage]
+	if len(samples) == 0 {
+		return 0
+	}
+	return samples[len(samples)-1].value
+}
+
+// cores returns the cpu usage in cores.
+func (u *containerUsage) cores() float64 {
+	return u.latest(usageCPU) / float64(time.Second)
+}
+
+// memoryPercent returns the working set relative to the memory limit, 0 for
+// containers without limit.
+func (u *containerUsage) memoryPercent() float64 {
+	limit := u.latest(usageMemoryLimit)
+	if limit == 0 {
+		return 0
+	}
+	return 100 * u.latest(usageMemory) / limit
+}
+
+// usageHistory accumulates the samples of a sliding window, fed with the
+// series stored since the previous update.
+type usageHistory struct {
+	window time.Duration
+	// latest is the time of the latest sample, the next update queries from.
+	latest time.Time
+	// previous holds the latest sample of every series, which rates are
+	// derived from and which older samples are skipped against.
+	previous   map[string]sample
+	containers map[string]*containerUsage
+}
+
+func newUsageHistory(window time.Duration) *usageHistory {
+	return &usageHistory{
+		window:     window,
+		previous:   map[string]sample{},
+		containers: map[string]*containerUsage{},
+	}
+}
+
+// usageSample is a sample of one of the series of a container.
+type usageSample struct {
+	series      string
+	container   string
+	measurement string
+	usage       int
+	sample
+}
+
+// add feeds series to the history and drops the samples that left the
+// window.
+func (h *usageHistory) add(series []client.Series, now time.Time) {
+	samples := []usageSample{}
+	for _, s := range series {
+		// The root cgroup holds the usage of the whole machine.
+		name, ok := s.Tags.AdditionalProperties[tagContainerName]
+		if !ok || name == influx.RootContainerName {
+			continue
+		}
+		usage := usageIndex(s.Measurement)
+		if usage < 0 || s.Field != "value" {
+			continue
+		}
+
+		key := seriesKey(s)
+		for _, point := range s.Points {
+			samples = append(samples, usageSample{
+				series:      key,
+				container:   name,
+				measurement: s.Measurement,
+				usage:       usage,
+				sample:      sample{time: point.Timestamp, value: point.Value},
+			})
+		}
+	}
+	// Samples of all series are replayed in time order, which sums the series
+	// of a container sampled at the same time.
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].time.Before(samples[j].time)
+	})
+
+	for _, s := range samples {
+		previous, seen := h.previous[s.series]
+		if seen && !s.time.After(previous.time) {
+			continue
+		}
+		h.previous[s.series] = s.sample
+		if s.time.After(h.latest) {
+			h.latest = s.time
+		}
+
+		value := s.value
+		if influx.IsCumulative(s.measurement) {
+			// Counter resets and the first sample leave no rate to derive.
+			if !seen || value < previous.value {
+				continue
+			}
+			value = (value - previous.value) / s.time.Sub(previous.time).Seconds()
+		}
+
+		u, ok := h.containers[s.container]
+		if !ok {
+			u = &containerUsage{name: s.container}
+			h.containers[s.container] = u
+		}
+
+		usages := u.usages[s.usage]
+		if n := len(usages); n > 0 && usages[n-1].time.Equal(s.time) {
+			usages[n-1].value += value
+		} else {
+			u.usages[s.usage] = append(usages, sample{time: s.time, value: value})
+		}
+	}
+
+	h.expire(now)
+}
+
+func (h *usageHistory) expire(now time.Time) {
+	deadline := now.Add(-h.window)
+	for name, u := range h.containers {
+		empty := true
+		for usage, samples := range u.usages {
+			i := sort.Search(len(samples), func(i int) bool {
+				return !samples[i].time.Before(deadline)
+			})
+			u.usages[usage] = samples[i:]
+			if len(u.usages[usage]) > 0 {
+				empty = false
+			}
+		}
+		if empty {
+			delete(h.containers, name)
+		}
+	}
+	for key, previous := range h.previous {
+		if previous.time.Before(deadline) {
+			delete(h.previous, key)
+		}
+	}
+}
+
+func usageIndex(measurement string) int {
+	for i, m := range usageMeasurements {
+		if m == measurement {
+			return i
+		}
+	}
+	return -1
+}
+
+// seriesKey identifies a series by its measurement, field and tags.
+func seriesKey(s client.Series) string {
+	keys := make([]string, 0, len(s.Tags.AdditionalProperties))
+	for k, v := range s.Tags.AdditionalProperties {
+		keys = append(keys, k+"\xff"+v)
+	}
+	sort.Strings(keys)
+
+	return s.Measurement + "\xff" + s.Field + "\xff" + strings.Join(keys, "\xff")
+}
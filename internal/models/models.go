@@ -1,18 +1,1134 @@
-// Package Stalker provides primitives to interact with the openapi HTTP API.
+// Package models provides primitives to interact with the openapi HTTP API.
 //
-// Code generated by github.com/deepmap/oapi-codegen version v1.9.0 DO NOT EDIT.
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Defines values for AlertState.
+const (
+	Firing   AlertState = "firing"
+	Pending  AlertState = "pending"
+	Resolved AlertState = "resolved"
+)
+
+// Defines values for AnomalyMethod.
+const (
+	Ewma       AnomalyMethod = "ewma"
+	RollingMad AnomalyMethod = "rolling-mad"
+)
+
+// Alert defines model for alert.
+type Alert struct {
+	// when the rule started to hold
+	ActiveAt    time.Time          `json:"activeAt"`
+	Annotations *Alert_Annotations `json:"annotations,omitempty"`
+	Expr        string             `json:"expr"`
+	FiredAt     *time.Time         `json:"firedAt,omitempty"`
+
+	// tags of the series the rule matched, merged with the labels of the rule
+	Labels     Alert_Labels `json:"labels"`
+	ResolvedAt *time.Time   `json:"resolvedAt,omitempty"`
+	Rule       string       `json:"rule"`
+	State      AlertState   `json:"state"`
+
+	// value of the compared series on the latest scrape the rule held
+	Value float64 `json:"value"`
+}
+
+// Alert_Annotations defines model for Alert.Annotations.
+type Alert_Annotations struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// tags of the series the rule matched, merged with the labels of the rule
+type Alert_Labels struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// AlertState defines model for Alert.State.
+type AlertState string
+
+// AlertList defines model for alertList.
+type AlertList struct {
+	Alerts []Alert `json:"alerts"`
+}
+
+// Anomaly defines model for anomaly.
+type Anomaly struct {
+	ContainerName *string `json:"containerName,omitempty"`
+
+	// distance between value and baseline in standard deviations
+	Deviation float64 `json:"deviation"`
+
+	// baseline of the series when the value was observed
+	Expected float64       `json:"expected"`
+	Method   AnomalyMethod `json:"method"`
+
+	// series name, analyzed as a per second rate when the series is cumulative
+	Series    string        `json:"series"`
+	Tags      *Anomaly_Tags `json:"tags,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+
+	// observed value
+	Value float64 `json:"value"`
+}
+
+// AnomalyMethod defines model for Anomaly.Method.
+type AnomalyMethod string
+
+// Anomaly_Tags defines model for Anomaly.Tags.
+type Anomaly_Tags struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// AnomalyList defines model for anomalyList.
+type AnomalyList struct {
+	Anomalies []Anomaly `json:"anomalies"`
+}
+
+// Backup defines model for backup.
+type Backup struct {
+	// directory holding the backups
+	Path      string    `json:"path"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CardinalityReport defines model for cardinalityReport.
+type CardinalityReport struct {
+	Containers []ContainerCardinality `json:"containers"`
+
+	// maximum number of series per container, 0 when unlimited
+	SeriesBudget int `json:"seriesBudget"`
+}
+
+// Container defines model for container.
+type Container struct {
+	Aliases   *[]string         `json:"aliases,omitempty"`
+	Cgroup    string            `json:"cgroup"`
+	CreatedAt *time.Time        `json:"createdAt,omitempty"`
+	Image     *string           `json:"image,omitempty"`
+	Labels    *Container_Labels `json:"labels,omitempty"`
+
+	// first alias of the container, or its cgroup when it has none
+	Name string `json:"name"`
+}
+
+// Container_Labels defines model for Container.Labels.
+type Container_Labels struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// ContainerCardinality defines model for containerCardinality.
+type ContainerCardinality struct {
+	Cgroup        string  `json:"cgroup"`
+	ContainerName *string `json:"containerName,omitempty"`
+
+	// points dropped because the container exceeded the series budget
+	DroppedPoints int64 `json:"droppedPoints"`
+
+	// whether points were dropped on the latest scrape
+	OverBudget bool `json:"overBudget"`
+
+	// number of series currently tracked for the container
+	Series int `json:"series"`
+
+	// distinct values per tag, highest first
+	Tags *[]TagCardinality `json:"tags,omitempty"`
+}
+
+// ContainerList defines model for containerList.
+type ContainerList struct {
+	Containers []Container `json:"containers"`
+}
+
+// ContainerStats defines model for containerStats.
+type ContainerStats struct {
+	Cpu       *CpuStats    `json:"cpu,omitempty"`
+	Memory    *MemoryStats `json:"memory,omitempty"`
+	Name      string       `json:"name"`
+	Rootfs    *FsStats     `json:"rootfs,omitempty"`
+	StartTime *time.Time   `json:"startTime,omitempty"`
+}
+
+// CpuCore defines model for cpuCore.
+type CpuCore struct {
+	Id       int    `json:"id"`
+	SocketId int    `json:"socketId"`
+	Threads  *[]int `json:"threads,omitempty"`
+}
+
+// CpuStats defines model for cpuStats.
+type CpuStats struct {
+	Time                 time.Time `json:"time"`
+	UsageCoreNanoSeconds *int64    `json:"usageCoreNanoSeconds,omitempty"`
+	UsageNanoCores       *int64    `json:"usageNanoCores,omitempty"`
+}
+
+// Event defines model for event.
+type Event struct {
+	ContainerName string `json:"containerName"`
+
+	// process id of the process killed by an oomKill event
+	Pid *int64 `json:"pid,omitempty"`
+
+	// name of the process killed by an oomKill event
+	ProcessName *string     `json:"processName,omitempty"`
+	Tags        *Event_Tags `json:"tags,omitempty"`
+	Timestamp   time.Time   `json:"timestamp"`
+	Type        string      `json:"type"`
+}
+
+// Event_Tags defines model for Event.Tags.
+type Event_Tags struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// EventList defines model for eventList.
+type EventList struct {
+	Events *[]Event `json:"events,omitempty"`
+}
+
+// FsInfo defines model for fsInfo.
+type FsInfo struct {
+	Available  int64      `json:"available"`
+	Capacity   int64      `json:"capacity"`
+	Device     string     `json:"device"`
+	Inodes     *int64     `json:"inodes,omitempty"`
+	InodesFree *int64     `json:"inodesFree,omitempty"`
+	Labels     *[]string  `json:"labels,omitempty"`
+	Mountpoint string     `json:"mountpoint"`
+	Timestamp  *time.Time `json:"timestamp,omitempty"`
+	Usage      int64      `json:"usage"`
+}
+
+// FsInfoList defines model for fsInfoList.
+type FsInfoList struct {
+	Filesystems *[]FsInfo `json:"filesystems,omitempty"`
+}
+
+// FsStats defines model for fsStats.
+type FsStats struct {
+	AvailableBytes *int64    `json:"availableBytes,omitempty"`
+	CapacityBytes  *int64    `json:"capacityBytes,omitempty"`
+	Inodes         *int64    `json:"inodes,omitempty"`
+	InodesFree     *int64    `json:"inodesFree,omitempty"`
+	InodesUsed     *int64    `json:"inodesUsed,omitempty"`
+	Time           time.Time `json:"time"`
+	UsedBytes      *int64    `json:"usedBytes,omitempty"`
+}
+
+// ImportResult defines model for importResult.
+type ImportResult struct {
+	// number of points stored
+	Points int64 `json:"points"`
+}
+
+// InterfaceStats defines model for interfaceStats.
+type InterfaceStats struct {
+	Name     string `json:"name"`
+	RxBytes  *int64 `json:"rxBytes,omitempty"`
+	RxErrors *int64 `json:"rxErrors,omitempty"`
+	TxBytes  *int64 `json:"txBytes,omitempty"`
+	TxErrors *int64 `json:"txErrors,omitempty"`
+}
+
+// MachineFilesystem defines model for machineFilesystem.
+type MachineFilesystem struct {
+	Capacity int64   `json:"capacity"`
+	Device   string  `json:"device"`
+	Inodes   *int64  `json:"inodes,omitempty"`
+	Type     *string `json:"type,omitempty"`
+}
+
+// MachineInfo defines model for machineInfo.
+type MachineInfo struct {
+	BootId           *string              `json:"bootId,omitempty"`
+	CloudProvider    *string              `json:"cloudProvider,omitempty"`
+	CpuFrequencyKhz  int64                `json:"cpuFrequencyKhz"`
+	CpuVendorId      *string              `json:"cpuVendorId,omitempty"`
+	Filesystems      *[]MachineFilesystem `json:"filesystems,omitempty"`
+	InstanceType     *string              `json:"instanceType,omitempty"`
+	MachineId        *string              `json:"machineId,omitempty"`
+	MemoryCapacity   int64                `json:"memoryCapacity"`
+	NetworkDevices   *[]NetworkDevice     `json:"networkDevices,omitempty"`
+	NumCores         int                  `json:"numCores"`
+	NumPhysicalCores int                  `json:"numPhysicalCores"`
+	NumSockets       int                  `json:"numSockets"`
+	NumaNodes        int                  `json:"numaNodes"`
+	SystemUuid       *string              `json:"systemUuid,omitempty"`
+	Timestamp        *time.Time           `json:"timestamp,omitempty"`
+	Topology         *[]NumaNode          `json:"topology,omitempty"`
+}
+
+// MemoryStats defines model for memoryStats.
+type MemoryStats struct {
+	AvailableBytes  *int64    `json:"availableBytes,omitempty"`
+	MajorPageFaults *int64    `json:"majorPageFaults,omitempty"`
+	PageFaults      *int64    `json:"pageFaults,omitempty"`
+	RssBytes        *int64    `json:"rssBytes,omitempty"`
+	Time            time.Time `json:"time"`
+	UsageBytes      *int64    `json:"usageBytes,omitempty"`
+	WorkingSetBytes *int64    `json:"workingSetBytes,omitempty"`
+}
+
 // MetricsList defines model for metricsList.
 type MetricsList struct {
 	Metrics *[]string `json:"metrics,omitempty"`
 }
 
+// NetworkDevice defines model for networkDevice.
+type NetworkDevice struct {
+	MacAddress *string `json:"macAddress,omitempty"`
+	Mtu        *int64  `json:"mtu,omitempty"`
+	Name       string  `json:"name"`
+
+	// link speed in Mbit/s
+	Speed *int64 `json:"speed,omitempty"`
+}
+
+// stats of the default interface and of every interface
+type NetworkStats struct {
+	Interfaces *[]InterfaceStats `json:"interfaces,omitempty"`
+	Name       *string           `json:"name,omitempty"`
+	RxBytes    *int64            `json:"rxBytes,omitempty"`
+	RxErrors   *int64            `json:"rxErrors,omitempty"`
+	Time       time.Time         `json:"time"`
+	TxBytes    *int64            `json:"txBytes,omitempty"`
+	TxErrors   *int64            `json:"txErrors,omitempty"`
+}
+
+// NodeError defines model for nodeError.
+type NodeError struct {
+	Error string `json:"error"`
+	Node  string `json:"node"`
+}
+
+// NodeStats defines model for nodeStats.
+type NodeStats struct {
+	Cpu    *CpuStats    `json:"cpu,omitempty"`
+	Fs     *FsStats     `json:"fs,omitempty"`
+	Memory *MemoryStats `json:"memory,omitempty"`
+
+	// stats of the default interface and of every interface
+	Network   *NetworkStats `json:"network,omitempty"`
+	NodeName  string        `json:"nodeName"`
+	Runtime   *RuntimeStats `json:"runtime,omitempty"`
+	StartTime *time.Time    `json:"startTime,omitempty"`
+}
+
+// NumaNode defines model for numaNode.
+type NumaNode struct {
+	Cores  *[]CpuCore `json:"cores,omitempty"`
+	Id     int        `json:"id"`
+	Memory int64      `json:"memory"`
+}
+
+// PodReference defines model for podReference.
+type PodReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Uid       string `json:"uid"`
+}
+
+// PodStats defines model for podStats.
+type PodStats struct {
+	Containers       []ContainerStats `json:"containers"`
+	Cpu              *CpuStats        `json:"cpu,omitempty"`
+	EphemeralStorage *FsStats         `json:"ephemeral-storage,omitempty"`
+	Memory           *MemoryStats     `json:"memory,omitempty"`
+
+	// stats of the default interface and of every interface
+	Network   *NetworkStats `json:"network,omitempty"`
+	PodRef    PodReference  `json:"podRef"`
+	StartTime *time.Time    `json:"startTime,omitempty"`
+}
+
+// Point defines model for point.
+type Point struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// PushResult defines model for pushResult.
+type PushResult struct {
+	// the batch was accepted before and was not stored again
+	Duplicate bool `json:"duplicate"`
+
+	// number of points stored, 0 for duplicates
+	Points int64 `json:"points"`
+}
+
+// RestoreRequest defines model for restoreRequest.
+type RestoreRequest struct {
+	// directory holding the backups, under the backup path of the daemon and relative to it unless absolute, defaults to the backup path
+	Path *string `json:"path,omitempty"`
+}
+
+// RuntimeStats defines model for runtimeStats.
+type RuntimeStats struct {
+	ImageFs *FsStats `json:"imageFs,omitempty"`
+}
+
+// Series defines model for series.
+type Series struct {
+	// field of the measurement holding the values, value for most measurements
+	Field       string `json:"field"`
+	Measurement string `json:"measurement"`
+
+	// numeric samples oldest first, fields with string values are left out
+	Points []Point     `json:"points"`
+	Tags   Series_Tags `json:"tags"`
+}
+
+// Series_Tags defines model for Series.Tags.
+type Series_Tags struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// SeriesList defines model for seriesList.
+type SeriesList struct {
+	// agents of an aggregator that failed to answer, their series are missing
+	Errors *[]NodeError `json:"errors,omitempty"`
+	Series []Series     `json:"series"`
+}
+
+// Summary defines model for summary.
+type Summary struct {
+	Node NodeStats  `json:"node"`
+	Pods []PodStats `json:"pods"`
+}
+
+// TagCardinality defines model for tagCardinality.
+type TagCardinality struct {
+	Name   string `json:"name"`
+	Values int    `json:"values"`
+}
+
+// TopEntry defines model for topEntry.
+type TopEntry struct {
+	Field       string        `json:"field"`
+	Measurement string        `json:"measurement"`
+	Node        string        `json:"node"`
+	Tags        TopEntry_Tags `json:"tags"`
+	Value       float64       `json:"value"`
+}
+
+// TopEntry_Tags defines model for TopEntry.Tags.
+type TopEntry_Tags struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// TopList defines model for topList.
+type TopList struct {
+	Entries []TopEntry `json:"entries"`
+
+	// agents that failed to answer, their series are missing from the ranking
+	Errors *[]NodeError `json:"errors,omitempty"`
+}
+
+// VersionInfo defines model for versionInfo.
+type VersionInfo struct {
+	CadvisorRevision   *string `json:"cadvisorRevision,omitempty"`
+	CadvisorVersion    *string `json:"cadvisorVersion,omitempty"`
+	Commit             string  `json:"commit"`
+	ContainerOsVersion *string `json:"containerOsVersion,omitempty"`
+	Date               string  `json:"date"`
+	GoVersion          string  `json:"goVersion"`
+	KernelVersion      *string `json:"kernelVersion,omitempty"`
+	Version            string  `json:"version"`
+}
+
+// FederationContainer defines model for federationContainer.
+type FederationContainer = []string
+
+// FederationEndTime defines model for federationEndTime.
+type FederationEndTime = int
+
+// FederationField defines model for federationField.
+type FederationField = string
+
+// FederationMeasurement defines model for federationMeasurement.
+type FederationMeasurement = string
+
+// FederationNamespace defines model for federationNamespace.
+type FederationNamespace = []string
+
+// FederationPod defines model for federationPod.
+type FederationPod = []string
+
+// FederationStartTime defines model for federationStartTime.
+type FederationStartTime = int
+
+// GetAlertsParams defines parameters for GetAlerts.
+type GetAlertsParams struct {
+	// Only return alerts in this state
+	State *GetAlertsParamsState `form:"state,omitempty" json:"state,omitempty"`
+}
+
+// GetAlertsParamsState defines parameters for GetAlerts.
+type GetAlertsParamsState string
+
+// GetAnomaliesParams defines parameters for GetAnomalies.
+type GetAnomaliesParams struct {
+	// Start of the period in unix seconds, defaults to one hour before endTime
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Series to return anomalies of, defaults to all
+	Series *[]string `form:"series,omitempty" json:"series,omitempty"`
+}
+
+// GetCardinalityParams defines parameters for GetCardinality.
+type GetCardinalityParams struct {
+	// Number of containers to return, defaults to 10
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetEventsParams defines parameters for GetEvents.
+type GetEventsParams struct {
+	// Start of the period in unix seconds, defaults to one hour before endTime
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Event types to return, defaults to all
+	Type *[]GetEventsParamsType `form:"type,omitempty" json:"type,omitempty"`
+}
+
+// GetEventsParamsType defines parameters for GetEvents.
+type GetEventsParamsType string
+
+// GetFederationSumParams defines parameters for GetFederationSum.
+type GetFederationSumParams struct {
+	// Measurement of the series
+	Measurement FederationMeasurement `form:"measurement" json:"measurement"`
+
+	// Field of the measurement, defaults to value
+	Field *FederationField `form:"field,omitempty" json:"field,omitempty"`
+
+	// Start of the period in unix seconds, defaults to an hour before its end
+	StartTime *FederationStartTime `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *FederationEndTime `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Container names of the series, defaults to all
+	Container *FederationContainer `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces of the series, defaults to all
+	Namespace *FederationNamespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names of the series, defaults to all
+	Pod *FederationPod `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Tags the sums are grouped by, such as node or namespace, defaults to none
+	GroupBy *[]string `form:"groupBy,omitempty" json:"groupBy,omitempty"`
+
+	// Window of the sums in seconds, defaults to 10
+	Step *int `form:"step,omitempty" json:"step,omitempty"`
+}
+
+// GetFederationTopParams defines parameters for GetFederationTop.
+type GetFederationTopParams struct {
+	// Measurement of the series
+	Measurement FederationMeasurement `form:"measurement" json:"measurement"`
+
+	// Field of the measurement, defaults to value
+	Field *FederationField `form:"field,omitempty" json:"field,omitempty"`
+
+	// Start of the period in unix seconds, defaults to an hour before its end
+	StartTime *FederationStartTime `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *FederationEndTime `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Container names of the series, defaults to all
+	Container *FederationContainer `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces of the series, defaults to all
+	Namespace *FederationNamespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names of the series, defaults to all
+	Pod *FederationPod `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Number of series to return, defaults to 10
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Value a series is ranked by, its last sample, the mean or maximum of its samples or the per second rate of a counter over the period, defaults to last
+	By *GetFederationTopParamsBy `form:"by,omitempty" json:"by,omitempty"`
+}
+
+// GetFederationTopParamsBy defines parameters for GetFederationTop.
+type GetFederationTopParamsBy string
+
+// PostImportJSONBody defines parameters for PostImport.
+type PostImportJSONBody struct {
+	AdditionalProperties map[string]map[string]interface{} `json:"-"`
+}
+
+// PostImportParams defines parameters for PostImport.
+type PostImportParams struct {
+	// Format of the body, lp for line protocol or json for the container infos returned by cadvisor ContainerInfoV2, defaults to json for application/json bodies and lp otherwise
+	Format *PostImportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Precision of line protocol timestamps, defaults to ns
+	Precision *PostImportParamsPrecision `form:"precision,omitempty" json:"precision,omitempty"`
+
+	// machine_name tag of the points converted from json, defaults to the hostname of the daemon
+	Machine *string `form:"machine,omitempty" json:"machine,omitempty"`
+}
+
+// PostImportParamsFormat defines parameters for PostImport.
+type PostImportParamsFormat string
+
+// PostImportParamsPrecision defines parameters for PostImport.
+type PostImportParamsPrecision string
+
 // GetMetricsListParams defines parameters for GetMetricsList.
 type GetMetricsListParams struct {
-	// Get metrics from a past time period
-	StartTime *int `json:"startTime,omitempty"`
+	// Start of the period in unix seconds. Without a period the last minute stored by the periodic scrapes is returned
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Measurements to return from the period, defaults to all
+	Measurement *[]string `form:"measurement,omitempty" json:"measurement,omitempty"`
+
+	// Container names to return from the period, defaults to all
+	Container *[]string `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces to return from the period, defaults to all
+	Namespace *[]string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names to return from the period, defaults to all
+	Pod *[]string `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Kubernetes QoS classes to return from the period, Guaranteed, Burstable or BestEffort, defaults to all
+	QosClass *[]string `form:"qosClass,omitempty" json:"qosClass,omitempty"`
+}
+
+// PostPushParams defines parameters for PostPush.
+type PostPushParams struct {
+	// Id of the batch, unique per agent
+	Batch string `form:"batch" json:"batch"`
+}
+
+// PostRestoreJSONBody defines parameters for PostRestore.
+type PostRestoreJSONBody = RestoreRequest
+
+// GetStatsSummaryParams defines parameters for GetStatsSummary.
+type GetStatsSummaryParams struct {
+	// Instant of the summary in unix seconds, defaults to now
+	Time *int `form:"time,omitempty" json:"time,omitempty"`
+}
+
+// GetStreamParams defines parameters for GetStream.
+type GetStreamParams struct {
+	// Container names or path globs to stream
+	Container *[]string `form:"container,omitempty" json:"container,omitempty"`
+
+	// Measurements to stream
+	Measurement *[]string `form:"measurement,omitempty" json:"measurement,omitempty"`
+}
+
+// GetV1MetricsListParams defines parameters for GetV1MetricsList.
+type GetV1MetricsListParams struct {
+	// Start of the period in unix seconds. Without a period the last minute stored by the periodic scrapes is returned
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Measurements to return from the period, defaults to all
+	Measurement *[]string `form:"measurement,omitempty" json:"measurement,omitempty"`
+
+	// Container names to return from the period, defaults to all
+	Container *[]string `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces to return from the period, defaults to all
+	Namespace *[]string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names to return from the period, defaults to all
+	Pod *[]string `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Kubernetes QoS classes to return from the period, Guaranteed, Burstable or BestEffort, defaults to all
+	QosClass *[]string `form:"qosClass,omitempty" json:"qosClass,omitempty"`
+}
+
+// GetV2MetricsListParams defines parameters for GetV2MetricsList.
+type GetV2MetricsListParams struct {
+	// Start of the period in unix seconds. Without a period the last minute stored by the periodic scrapes is returned
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Measurements to return from the period, defaults to all
+	Measurement *[]string `form:"measurement,omitempty" json:"measurement,omitempty"`
+
+	// Container names to return from the period, defaults to all
+	Container *[]string `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces to return from the period, defaults to all
+	Namespace *[]string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names to return from the period, defaults to all
+	Pod *[]string `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Kubernetes QoS classes to return from the period, Guaranteed, Burstable or BestEffort, defaults to all
+	QosClass *[]string `form:"qosClass,omitempty" json:"qosClass,omitempty"`
+
+	// Format of the response, overriding the Accept header. Formats other than json stream the records of the period, which defaults to the last hour, and the columnar arrow and parquet formats hold numeric values only
+	Format *GetV2MetricsListParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Sum the series of the containers of every Kubernetes pod into pod series tagged with namespace, pod and qos_class along with the tags telling apart the series of a container, such as instance or failure_type. Network series are counted once per pod and limits are left out, json only
+	Rollup *GetV2MetricsListParamsRollup `form:"rollup,omitempty" json:"rollup,omitempty"`
+
+	// Window of a rollup in seconds, the last sample of every container in a window is summed, defaults to 10
+	Step *int `form:"step,omitempty" json:"step,omitempty"`
+}
+
+// GetV2MetricsListParamsFormat defines parameters for GetV2MetricsList.
+type GetV2MetricsListParamsFormat string
+
+// GetV2MetricsListParamsRollup defines parameters for GetV2MetricsList.
+type GetV2MetricsListParamsRollup string
+
+// PostImportJSONRequestBody defines body for PostImport for application/json ContentType.
+type PostImportJSONRequestBody PostImportJSONBody
+
+// PostRestoreJSONRequestBody defines body for PostRestore for application/json ContentType.
+type PostRestoreJSONRequestBody = PostRestoreJSONBody
+
+// Getter for additional properties for PostImportJSONBody. Returns the specified
+// element and whether it was found
+func (a PostImportJSONBody) Get(fieldName string) (value map[string]interface{}, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for PostImportJSONBody
+func (a *PostImportJSONBody) Set(fieldName string, value map[string]interface{}) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]map[string]interface{})
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for PostImportJSONBody to handle AdditionalProperties
+func (a *PostImportJSONBody) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]map[string]interface{})
+		for fieldName, fieldBuf := range object {
+			var fieldVal map[string]interface{}
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for PostImportJSONBody to handle AdditionalProperties
+func (a PostImportJSONBody) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Alert_Annotations. Returns the specified
+// element and whether it was found
+func (a Alert_Annotations) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Alert_Annotations
+func (a *Alert_Annotations) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Alert_Annotations to handle AdditionalProperties
+func (a *Alert_Annotations) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Alert_Annotations to handle AdditionalProperties
+func (a Alert_Annotations) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Alert_Labels. Returns the specified
+// element and whether it was found
+func (a Alert_Labels) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Alert_Labels
+func (a *Alert_Labels) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Alert_Labels to handle AdditionalProperties
+func (a *Alert_Labels) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Alert_Labels to handle AdditionalProperties
+func (a Alert_Labels) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Anomaly_Tags. Returns the specified
+// element and whether it was found
+func (a Anomaly_Tags) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Anomaly_Tags
+func (a *Anomaly_Tags) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Anomaly_Tags to handle AdditionalProperties
+func (a *Anomaly_Tags) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Anomaly_Tags to handle AdditionalProperties
+func (a Anomaly_Tags) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Container_Labels. Returns the specified
+// element and whether it was found
+func (a Container_Labels) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Container_Labels
+func (a *Container_Labels) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Container_Labels to handle AdditionalProperties
+func (a *Container_Labels) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Container_Labels to handle AdditionalProperties
+func (a Container_Labels) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Event_Tags. Returns the specified
+// element and whether it was found
+func (a Event_Tags) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Event_Tags
+func (a *Event_Tags) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Event_Tags to handle AdditionalProperties
+func (a *Event_Tags) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Event_Tags to handle AdditionalProperties
+func (a Event_Tags) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Series_Tags. Returns the specified
+// element and whether it was found
+func (a Series_Tags) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Series_Tags
+func (a *Series_Tags) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Series_Tags to handle AdditionalProperties
+func (a *Series_Tags) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Series_Tags to handle AdditionalProperties
+func (a Series_Tags) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for TopEntry_Tags. Returns the specified
+// element and whether it was found
+func (a TopEntry_Tags) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for TopEntry_Tags
+func (a *TopEntry_Tags) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for TopEntry_Tags to handle AdditionalProperties
+func (a *TopEntry_Tags) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for TopEntry_Tags to handle AdditionalProperties
+func (a TopEntry_Tags) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
 
-	// Get metrics from a past time period
-	EndTime *int `json:"endTime,omitempty"`
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
 }
@@ -0,0 +1,354 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/internal/services"
+	"github.com/zawachte/stalker/pkg/client"
+	"github.com/zawachte/stalker/pkg/export"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+// defaultTopLimit is how many series the federated top returns by default.
+const defaultTopLimit = 10
+
+// defaultField is the field of the measurements federated queries select by
+// default.
+const defaultField = "value"
+
+// failedNodesHeader lists the agents missing from federated exports.
+const failedNodesHeader = "X-Stalker-Failed-Nodes"
+
+type FederationProviderParams struct {
+	Agents  []services.Agent
+	Token   string
+	Timeout time.Duration
+	Build   BuildInfo
+}
+
+// federationProvider serves the api of an aggregator, which stores nothing
+// and answers metrics queries from its agents. The endpoints of a single
+// host are left to the agents.
+type federationProvider struct {
+	federationService services.FederationService
+	build             BuildInfo
+}
+
+func NewFederationProvider(params FederationProviderParams) (*federationProvider, error) {
+	federationService, err := services.NewFederationService(services.FederationServiceParams{
+		Agents:  params.Agents,
+		Token:   params.Token,
+		Timeout: params.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &federationProvider{
+		federationService: federationService,
+		build:             params.Build,
+	}, nil
+}
+
+func (p *federationProvider) GetV2MetricsList(w http.ResponseWriter, r *http.Request, params models.GetV2MetricsListParams) {
+	requested := ""
+	if params.Format != nil {
+		requested = string(*params.Format)
+	}
+	format, err := export.Negotiate(requested, r.Header.Get("Accept"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotAcceptable)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if params.Rollup != nil && format != export.FormatJson {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("rollups are only returned as json"))
+		return
+	}
+	// Agents would all answer 400 to a step they reject.
+	if _, ok := rollupStep(w, params.Step); !ok {
+		return
+	}
+
+	query := client.GetV2MetricsListParams{
+		StartTime:   params.StartTime,
+		EndTime:     params.EndTime,
+		Measurement: params.Measurement,
+		Container:   params.Container,
+		Namespace:   params.Namespace,
+		Pod:         params.Pod,
+		QosClass:    params.QosClass,
+		Step:        params.Step,
+	}
+	if params.Rollup != nil {
+		rollup := client.GetV2MetricsListParamsRollup(*params.Rollup)
+		query.Rollup = &rollup
+	}
+	// The agents answer json, exports without a period cover the last hour
+	// as they do on the agents.
+	if format != export.FormatJson {
+		start, stop, ok := metricsPeriod(w, params.StartTime, params.EndTime)
+		if !ok {
+			return
+		}
+		query.StartTime, query.EndTime = unixSeconds(start, stop)
+	}
+
+	seriesList, err := p.federationService.GetSeries(r.Context(), query)
+	if err != nil {
+		federationError(w, err)
+		return
+	}
+
+	if format == export.FormatJson {
+		writeJson(w, seriesList)
+		return
+	}
+	exportSeries(w, format, seriesList)
+}
+
+// exportSeries writes the points of the series in format. Unlike the exports
+// of an agent, which stream the records as influxdb returns them, the series
+// hold the full json answers of every agent: federated exports are buffered
+// in memory and should be narrowed by period, measurement or container.
+func exportSeries(w http.ResponseWriter, format string, seriesList models.SeriesList) {
+	body := &startedWriter{w: w}
+	writer, err := export.NewWriter(format, body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	if seriesList.Errors != nil {
+		nodes := []string{}
+		for _, nodeError := range *seriesList.Errors {
+			nodes = append(nodes, nodeError.Node)
+		}
+		w.Header().Set(failedNodesHeader, strings.Join(nodes, ","))
+	}
+	w.Header().Set("Content-Type", export.ContentType(format))
+
+	for _, series := range seriesList.Series {
+		for _, point := range series.Points {
+			err = writer.Write(influx.Record{
+				Time:        point.Timestamp,
+				Measurement: series.Measurement,
+				Field:       series.Field,
+				Value:       point.Value,
+				Tags:        series.Tags.AdditionalProperties,
+			})
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if !body.started {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		log.Printf("failed to export metrics: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func (p *federationProvider) GetFederationTop(w http.ResponseWriter, r *http.Request, params models.GetFederationTopParams) {
+	limit := defaultTopLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit < 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("limit must be positive"))
+		return
+	}
+	by := services.TopByLast
+	if params.By != nil {
+		by = string(*params.By)
+	}
+	switch by {
+	case services.TopByLast, services.TopByMean, services.TopByMax, services.TopByRate:
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf("unknown ranking %q, expected last, mean, max or rate", by)))
+		return
+	}
+
+	query, ok := federationQuery(w, params.Measurement, params.StartTime, params.EndTime, params.Container, params.Namespace, params.Pod)
+	if !ok {
+		return
+	}
+
+	top, err := p.federationService.GetTop(r.Context(), query, field(params.Field), limit, by)
+	if err != nil {
+		federationError(w, err)
+		return
+	}
+
+	writeJson(w, top)
+}
+
+func (p *federationProvider) GetFederationSum(w http.ResponseWriter, r *http.Request, params models.GetFederationSumParams) {
+	step, ok := rollupStep(w, params.Step)
+	if !ok {
+		return
+	}
+
+	query, ok := federationQuery(w, params.Measurement, params.StartTime, params.EndTime, params.Container, params.Namespace, params.Pod)
+	if !ok {
+		return
+	}
+
+	sum, err := p.federationService.GetSum(r.Context(), query, field(params.Field), stringList(params.GroupBy), step)
+	if err != nil {
+		federationError(w, err)
+		return
+	}
+
+	writeJson(w, sum)
+}
+
+// federationQuery returns the query of the agents for the series of a
+// federated top or sum, answering with 400 when its period is empty.
+func federationQuery(w http.ResponseWriter, measurement string, startTime, endTime *int, containers, namespaces, pods *[]string) (client.GetV2MetricsListParams, bool) {
+	start, stop, ok := metricsPeriod(w, startTime, endTime)
+	if !ok {
+		return client.GetV2MetricsListParams{}, false
+	}
+
+	query := client.GetV2MetricsListParams{
+		Measurement: &[]string{measurement},
+		Container:   containers,
+		Namespace:   namespaces,
+		Pod:         pods,
+	}
+	query.StartTime, query.EndTime = unixSeconds(start, stop)
+	return query, true
+}
+
+func unixSeconds(start, stop time.Time) (*int, *int) {
+	startTime, endTime := int(start.Unix()), int(stop.Unix())
+	return &startTime, &endTime
+}
+
+func field(f *string) string {
+	if f == nil || *f == "" {
+		return defaultField
+	}
+	return *f
+}
+
+// federationError answers with 502 when no agent answered.
+func federationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrNoAgentAnswered) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte(err.Error()))
+}
+
+func (p *federationProvider) GetVersion(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, models.VersionInfo{
+		Version:   p.build.Version,
+		Commit:    p.build.Commit,
+		Date:      p.build.Date,
+		GoVersion: runtime.Version(),
+	})
+}
+
+// notServed answers requests for the endpoints of a single host.
+func notServed(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotImplemented)
+	w.Write([]byte("not served by aggregators, query /v2/metricsList, /federation/top or /federation/sum or the agents"))
+}
+
+func (p *federationProvider) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetMetricsList(w http.ResponseWriter, r *http.Request, params models.GetMetricsListParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetV1MetricsList(w http.ResponseWriter, r *http.Request, params models.GetV1MetricsListParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetAlerts(w http.ResponseWriter, r *http.Request, params models.GetAlertsParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetAnomalies(w http.ResponseWriter, r *http.Request, params models.GetAnomaliesParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) PostBackup(w http.ResponseWriter, r *http.Request) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetContainers(w http.ResponseWriter, r *http.Request) {
+	notServed(w)
+}
+
+func (p *federationProvider) PostRestore(w http.ResponseWriter, r *http.Request) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetCardinality(w http.ResponseWriter, r *http.Request, params models.GetCardinalityParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetEvents(w http.ResponseWriter, r *http.Request, params models.GetEventsParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetFsImages(w http.ResponseWriter, r *http.Request) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetFsRoot(w http.ResponseWriter, r *http.Request) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetStatsSummary(w http.ResponseWriter, r *http.Request, params models.GetStatsSummaryParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetMachine(w http.ResponseWriter, r *http.Request) {
+	notServed(w)
+}
+
+func (p *federationProvider) GetStream(w http.ResponseWriter, r *http.Request, params models.GetStreamParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) PostApiV1Write(w http.ResponseWriter, r *http.Request) {
+	notServed(w)
+}
+
+func (p *federationProvider) PostImport(w http.ResponseWriter, r *http.Request, params models.PostImportParams) {
+	notServed(w)
+}
+
+func (p *federationProvider) PostPush(w http.ResponseWriter, r *http.Request, params models.PostPushParams) {
+	notServed(w)
+}
@@ -0,0 +1,67 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/internal/services"
+)
+
+func TestFederationProviderErrors(t *testing.T) {
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer agent.Close()
+
+	p, err := NewFederationProvider(FederationProviderParams{
+		Agents: []services.Agent{services.ParseAgent("a=" + agent.URL)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zero := 0
+	tests := []struct {
+		name  string
+		serve func(w http.ResponseWriter, r *http.Request)
+		code  int
+	}{
+		{
+			name: "no agent answered",
+			serve: func(w http.ResponseWriter, r *http.Request) {
+				p.GetV2MetricsList(w, r, models.GetV2MetricsListParams{})
+			},
+			code: http.StatusBadGateway,
+		},
+		{
+			name: "metrics list step",
+			serve: func(w http.ResponseWriter, r *http.Request) {
+				p.GetV2MetricsList(w, r, models.GetV2MetricsListParams{Step: &zero})
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "sum step",
+			serve: func(w http.ResponseWriter, r *http.Request) {
+				p.GetFederationSum(w, r, models.GetFederationSumParams{Measurement: "memory_usage", Step: &zero})
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "single host endpoint",
+			serve: func(w http.ResponseWriter, r *http.Request) {
+				p.GetContainers(w, r)
+			},
+			code: http.StatusNotImplemented,
+		},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != tt.code {
+			t.Errorf("%s: got %d %s, want %d", tt.name, rec.Code, rec.Body, tt.code)
+		}
+	}
+}
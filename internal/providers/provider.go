@@ -1,45 +1,181 @@
 package providers
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	cadvisorapiv2 "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/prometheus/common/expfmt"
 	"github.com/zawachte/stalker/internal/models"
 	"github.com/zawachte/stalker/internal/services"
+	"github.com/zawachte/stalker/pkg/alerting"
+	"github.com/zawachte/stalker/pkg/anomaly"
+	"github.com/zawachte/stalker/pkg/cadvisor"
+	"github.com/zawachte/stalker/pkg/export"
+	"github.com/zawachte/stalker/pkg/filter"
+	"github.com/zawachte/stalker/pkg/importer"
+	"github.com/zawachte/stalker/pkg/influx"
+	"github.com/zawachte/stalker/pkg/prometheus"
+	"github.com/zawachte/stalker/pkg/push"
+	"github.com/zawachte/stalker/pkg/remotewrite"
+	"github.com/zawachte/stalker/pkg/stream"
 )
 
 type Provider interface {
+	GetMetrics(w http.ResponseWriter, r *http.Request)
 	GetMetricsList(w http.ResponseWriter, r *http.Request, params models.GetMetricsListParams)
+	GetV1MetricsList(w http.ResponseWriter, r *http.Request, params models.GetV1MetricsListParams)
+	GetV2MetricsList(w http.ResponseWriter, r *http.Request, params models.GetV2MetricsListParams)
+	GetAlerts(w http.ResponseWriter, r *http.Request, params models.GetAlertsParams)
+	GetAnomalies(w http.ResponseWriter, r *http.Request, params models.GetAnomaliesParams)
+	PostBackup(w http.ResponseWriter, r *http.Request)
+	GetContainers(w http.ResponseWriter, r *http.Request)
+	PostRestore(w http.ResponseWriter, r *http.Request)
+	GetCardinality(w http.ResponseWriter, r *http.Request, params models.GetCardinalityParams)
+	GetEvents(w http.ResponseWriter, r *http.Request, params models.GetEventsParams)
+	GetFsImages(w http.ResponseWriter, r *http.Request)
+	GetFsRoot(w http.ResponseWriter, r *http.Request)
+	GetStatsSummary(w http.ResponseWriter, r *http.Request, params models.GetStatsSummaryParams)
+	GetMachine(w http.ResponseWriter, r *http.Request)
+	GetStream(w http.ResponseWriter, r *http.Request, params models.GetStreamParams)
+	GetVersion(w http.ResponseWriter, r *http.Request)
+	PostApiV1Write(w http.ResponseWriter, r *http.Request)
+	PostImport(w http.ResponseWriter, r *http.Request, params models.PostImportParams)
+	GetFederationTop(w http.ResponseWriter, r *http.Request, params models.GetFederationTopParams)
+	GetFederationSum(w http.ResponseWriter, r *http.Request, params models.GetFederationSumParams)
+	PostPush(w http.ResponseWriter, r *http.Request, params models.PostPushParams)
+}
+
+// streamHeartbeat is how often idle streams receive a heartbeat event.
+const streamHeartbeat = 15 * time.Second
+
+// defaultCardinalityLimit is how many containers the cardinality report lists by default.
+const defaultCardinalityLimit = 10
+
+// maxRemoteWriteBytes bounds the snappy-compressed body of a remote_write
+// request.
+const maxRemoteWriteBytes = 32 << 20
+
+// maxPushBytes bounds the decompressed body of a push.
+const maxPushBytes = 64 << 20
+
+// defaultEventsPeriod is how far back events are returned when no start time is given.
+const defaultEventsPeriod = time.Hour
+
+var eventTypes = map[models.GetEventsParamsType]bool{
+	"containerCreation": true,
+	"containerDeletion": true,
+	"oom":               true,
+	"oomKill":           true,
 }
 
 type ProviderParams struct {
-	DatabaseUrl   string
-	DatabaseToken string
+	DatabaseUrl     string
+	DatabaseToken   string
+	Sinks           []services.PointSink
+	Containers      *filter.File
+	LabelPolicy     *influx.LabelPolicyFile
+	SeriesBudget    int
+	CAdvisor        cadvisor.Options
+	RuntimeEndpoint string
+	TopProcesses    int
+	TopProcessesBy  []string
+	Alerts          *alerting.Engine
+	Anomalies       *anomaly.Detector
+	Backups         services.BackupService
+	// PushAgents are the agents allowed to push, nil rejects pushes.
+	PushAgents *push.AgentsFile
+	Build      BuildInfo
+}
+
+// BuildInfo identifies the stalker build, as set through ldflags.
+type BuildInfo struct {
+	Version string
+	Commit  string
+	Date    string
 }
 
 type provider struct {
 	cadvisorService services.CAdvisorService
+	backupService   services.BackupService
+	pushService     services.PushService
+	build           BuildInfo
 }
 
 func NewProvider(ctx context.Context, params ProviderParams) (*provider, error) {
 
 	cadvisorService, err := services.NewCAdvisorService(ctx, services.CAdvisorServiceParams{
-		DatabaseUrl:   params.DatabaseUrl,
-		DatabaseToken: params.DatabaseToken,
+		DatabaseUrl:     params.DatabaseUrl,
+		DatabaseToken:   params.DatabaseToken,
+		Sinks:           params.Sinks,
+		Containers:      params.Containers,
+		LabelPolicy:     params.LabelPolicy,
+		SeriesBudget:    params.SeriesBudget,
+		CAdvisor:        params.CAdvisor,
+		RuntimeEndpoint: params.RuntimeEndpoint,
+		TopProcesses:    params.TopProcesses,
+		TopProcessesBy:  params.TopProcessesBy,
+		Alerts:          params.Alerts,
+		Anomalies:       params.Anomalies,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	var pushService services.PushService
+	if params.PushAgents != nil {
+		pushService = services.NewPushService(services.PushServiceParams{
+			Agents: params.PushAgents,
+			Points: cadvisorService,
+		})
+	}
+
 	return &provider{
 		cadvisorService: cadvisorService,
+		backupService:   params.Backups,
+		pushService:     pushService,
+		build:           params.Build,
 	}, nil
 }
 
+// Scrape collects and stores the latest stats of the containers, it is
+// called on the scrape interval.
+func (p *provider) Scrape(ctx context.Context) error {
+	return p.cadvisorService.Scrape(ctx)
+}
+
+// GetMetricsList serves the deprecated alias of /v1/metricsList.
 func (p *provider) GetMetricsList(w http.ResponseWriter, r *http.Request, params models.GetMetricsListParams) {
-	// deal with periods
-	metricsList, err := p.cadvisorService.GetMetricsList(r.Context())
+	p.GetV1MetricsList(w, r, models.GetV1MetricsListParams(params))
+}
+
+func (p *provider) GetV1MetricsList(w http.ResponseWriter, r *http.Request, params models.GetV1MetricsListParams) {
+	var metricsList models.MetricsList
+	var err error
+	if params.StartTime != nil || params.EndTime != nil {
+		start, stop, ok := metricsPeriod(w, params.StartTime, params.EndTime)
+		if !ok {
+			return
+		}
+
+		filter := statsFilter(params.Measurement, params.Container, params.Namespace, params.Pod, params.QosClass)
+		metricsList, err = p.cadvisorService.GetMetricsListInPeriod(r.Context(), start, stop, filter)
+	} else {
+		metricsList, err = p.cadvisorService.GetMetricsList(r.Context())
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(err.Error()))
@@ -53,6 +189,640 @@ func (p *provider) GetMetricsList(w http.ResponseWriter, r *http.Request, params
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write(metricsListJson)
 }
+
+func (p *provider) GetV2MetricsList(w http.ResponseWriter, r *http.Request, params models.GetV2MetricsListParams) {
+	requested := ""
+	if params.Format != nil {
+		requested = string(*params.Format)
+	}
+	format, err := export.Negotiate(requested, r.Header.Get("Accept"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotAcceptable)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if params.Rollup != nil && format != export.FormatJson {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("rollups are only returned as json"))
+		return
+	}
+	step, ok := rollupStep(w, params.Step)
+	if !ok {
+		return
+	}
+	if format != export.FormatJson {
+		p.exportMetrics(w, r, format, params)
+		return
+	}
+
+	var seriesList models.SeriesList
+	if params.StartTime != nil || params.EndTime != nil {
+		start, stop, ok := metricsPeriod(w, params.StartTime, params.EndTime)
+		if !ok {
+			return
+		}
+
+		filter := statsFilter(params.Measurement, params.Container, params.Namespace, params.Pod, params.QosClass)
+		seriesList, err = p.cadvisorService.GetSeriesInPeriod(r.Context(), start, stop, filter)
+	} else {
+		seriesList, err = p.cadvisorService.GetSeries(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	if params.Rollup != nil {
+		seriesList = services.RollupPods(seriesList, step)
+	}
+
+	writeJson(w, seriesList)
+}
+
+// exportMetrics streams the records of the period in format.
+func (p *provider) exportMetrics(w http.ResponseWriter, r *http.Request, format string, params models.GetV2MetricsListParams) {
+	start, stop, ok := metricsPeriod(w, params.StartTime, params.EndTime)
+	if !ok {
+		return
+	}
+
+	body := &startedWriter{w: w}
+	writer, err := export.NewWriter(format, body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	filter := statsFilter(params.Measurement, params.Container, params.Namespace, params.Pod, params.QosClass)
+	err = p.cadvisorService.StreamMetricsInPeriod(r.Context(), start, stop, filter, writer.Write)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if !body.started {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		// The status is sent, abort the response so the client does not take
+		// the truncated body for a complete export.
+		log.Printf("failed to export metrics: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// startedWriter records whether a response body was started.
+type startedWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (s *startedWriter) Write(p []byte) (int, error) {
+	s.started = true
+	return s.w.Write(p)
+}
+
+// metricsPeriod returns the period of a metrics query, answering with 400
+// when it is empty.
+func metricsPeriod(w http.ResponseWriter, startTime, endTime *int) (time.Time, time.Time, bool) {
+	stop := time.Now()
+	if endTime != nil {
+		stop = time.Unix(int64(*endTime), 0)
+	}
+	start := stop.Add(-defaultEventsPeriod)
+	if startTime != nil {
+		start = time.Unix(int64(*startTime), 0)
+	}
+	if !start.Before(stop) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("startTime must be before endTime"))
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, stop, true
+}
+
+// rollupStep returns the window of a rollup given in seconds, answering with
+// 400 when it is not positive.
+func rollupStep(w http.ResponseWriter, seconds *int) (time.Duration, bool) {
+	if seconds == nil {
+		return services.DefaultRollupStep, true
+	}
+	if *seconds < 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("step must be at least 1 second"))
+		return 0, false
+	}
+	return time.Duration(*seconds) * time.Second, true
+}
+
+// statsFilter selects the records of the query parameters of a metrics list.
+func statsFilter(measurements, containers, namespaces, pods, qosClasses *[]string) influx.StatsFilter {
+	return influx.StatsFilter{
+		Measurements: stringList(measurements),
+		Containers:   stringList(containers),
+		Namespaces:   stringList(namespaces),
+		Pods:         stringList(pods),
+		QOSClasses:   stringList(qosClasses),
+	}
+}
+
+func stringList(values *[]string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return *values
+}
+
+func (p *provider) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	points, err := p.cadvisorService.GetLatestPoints(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	format := expfmt.NegotiateIncludingOpenMetrics(r.Header)
+	w.Header().Set("Content-Type", string(format))
+	w.WriteHeader(http.StatusOK)
+	prometheus.WriteMetricFamilies(w, format, prometheus.PointsToMetricFamilies(points))
+}
+
+func (p *provider) PostApiV1Write(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRemoteWriteBytes))
+	if err != nil && len(body) == maxRemoteWriteBytes {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(fmt.Sprintf("remote_write requests are limited to %d bytes", maxRemoteWriteBytes)))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	series, err := remotewrite.DecodeWriteRequest(body)
+	if err == remotewrite.ErrTooLarge {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	err = p.cadvisorService.PostPoints(r.Context(), remotewrite.TimeSeriesToPoints(series))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *provider) PostImport(w http.ResponseWriter, r *http.Request, params models.PostImportParams) {
+	format := export.FormatLineProtocol
+	if params.Format != nil {
+		format = string(*params.Format)
+	} else if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		format = export.FormatJson
+	}
+
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	count := 0
+	var err error
+	switch format {
+	case export.FormatJson:
+		machine := ""
+		if params.Machine != nil {
+			machine = *params.Machine
+		}
+		err = importer.ReadContainerInfos(body, func(infos map[string]cadvisorapiv2.ContainerInfo) error {
+			n, err := p.cadvisorService.ImportContainerInfos(r.Context(), machine, infos)
+			count += n
+			return err
+		})
+	default:
+		precision := ""
+		if params.Precision != nil {
+			precision = string(*params.Precision)
+		}
+		var unit time.Duration
+		unit, err = importer.Precision(precision)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		err = importer.ReadLineProtocol(body, unit, func(points []*write.Point) error {
+			err := p.cadvisorService.PostPoints(r.Context(), points)
+			if err == nil {
+				count += len(points)
+			}
+			return err
+		})
+	}
+
+	var malformed *importer.MalformedError
+	if errors.As(err, &malformed) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf("%v, %d points were imported before", err, count)))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, models.ImportResult{Points: int64(count)})
+}
+
+func (p *provider) PostPush(w http.ResponseWriter, r *http.Request, params models.PostPushParams) {
+	if p.pushService == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		w.Write([]byte("pushes are not accepted, start stalker with --push-agents"))
+		return
+	}
+
+	authorization := r.Header.Get("Authorization")
+	agent, ok := p.pushService.Authenticate(strings.TrimPrefix(authorization, "Bearer "))
+	if !ok || !strings.HasPrefix(authorization, "Bearer ") {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("missing or invalid agent token"))
+		return
+	}
+
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxPushBytes+1))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if len(data) > maxPushBytes {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(fmt.Sprintf("pushes are limited to %d bytes of line protocol", maxPushBytes)))
+		return
+	}
+
+	points := []*write.Point{}
+	err = importer.ReadLineProtocol(bytes.NewReader(data), time.Nanosecond, func(batch []*write.Point) error {
+		points = append(points, batch...)
+		return nil
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	result, err := p.pushService.Push(r.Context(), agent, params.Batch, points)
+	var quota *push.QuotaError
+	if errors.As(err, &quota) {
+		if quota.RetryAfter == 0 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(quota.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if err == push.ErrPending {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, result)
+}
+
+func (p *provider) GetStream(w http.ResponseWriter, r *http.Request, params models.GetStreamParams) {
+	filter := stream.Filter{}
+	if params.Container != nil {
+		filter.Containers = *params.Container
+	}
+	if params.Measurement != nil {
+		filter.Measurements = *params.Measurement
+	}
+
+	sub := p.cadvisorService.Subscribe(filter)
+	defer p.cadvisorService.Unsubscribe(sub)
+
+	if stream.IsWebSocket(r) {
+		stream.ServeWebSocket(w, r, sub, streamHeartbeat)
+		return
+	}
+	stream.ServeSSE(w, r, sub, streamHeartbeat)
+}
+
+func (p *provider) GetEvents(w http.ResponseWriter, r *http.Request, params models.GetEventsParams) {
+	stop := time.Now()
+	if params.EndTime != nil {
+		stop = time.Unix(int64(*params.EndTime), 0)
+	}
+	start := stop.Add(-defaultEventsPeriod)
+	if params.StartTime != nil {
+		start = time.Unix(int64(*params.StartTime), 0)
+	}
+	if !start.Before(stop) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("startTime must be before endTime"))
+		return
+	}
+
+	types := []string{}
+	if params.Type != nil {
+		for _, eventType := range *params.Type {
+			if !eventTypes[eventType] {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(fmt.Sprintf("unknown event type %q", eventType)))
+				return
+			}
+			types = append(types, string(eventType))
+		}
+	}
+
+	eventList, err := p.cadvisorService.GetEvents(r.Context(), start, stop, types)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	eventListJson, err := json.Marshal(eventList)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(eventListJson)
+}
+
+func (p *provider) GetMachine(w http.ResponseWriter, r *http.Request) {
+	machineInfo, err := p.cadvisorService.GetMachineInfo(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, machineInfo)
+}
+
+func (p *provider) GetVersion(w http.ResponseWriter, r *http.Request) {
+	versionInfo, err := p.cadvisorService.GetVersionInfo(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	versionInfo.Version = p.build.Version
+	versionInfo.Commit = p.build.Commit
+	versionInfo.Date = p.build.Date
+
+	writeJson(w, versionInfo)
+}
+
+func (p *provider) GetFsRoot(w http.ResponseWriter, r *http.Request) {
+	fsInfo, err := p.cadvisorService.GetRootFsInfo(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, fsInfo)
+}
+
+func writeJson(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (p *provider) GetAlerts(w http.ResponseWriter, r *http.Request, params models.GetAlertsParams) {
+	state := ""
+	if params.State != nil {
+		state = string(*params.State)
+	}
+
+	alerts, err := p.cadvisorService.GetAlerts(r.Context(), state)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, alerts)
+}
+
+func (p *provider) GetAnomalies(w http.ResponseWriter, r *http.Request, params models.GetAnomaliesParams) {
+	stop := time.Now()
+	if params.EndTime != nil {
+		stop = time.Unix(int64(*params.EndTime), 0)
+	}
+	start := stop.Add(-defaultEventsPeriod)
+	if params.StartTime != nil {
+		start = time.Unix(int64(*params.StartTime), 0)
+	}
+	if !start.Before(stop) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("startTime must be before endTime"))
+		return
+	}
+
+	series := []string{}
+	if params.Series != nil {
+		series = *params.Series
+	}
+
+	anomalies, err := p.cadvisorService.GetAnomalies(r.Context(), start, stop, series)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, anomalies)
+}
+
+func (p *provider) GetContainers(w http.ResponseWriter, r *http.Request) {
+	containers, err := p.cadvisorService.GetContainers(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, containers)
+}
+
+func (p *provider) PostBackup(w http.ResponseWriter, r *http.Request) {
+	if p.backupService == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		w.Write([]byte("backups are not configured"))
+		return
+	}
+
+	backup, err := p.backupService.Backup(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, backup)
+}
+
+func (p *provider) PostRestore(w http.ResponseWriter, r *http.Request) {
+	if p.backupService == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		w.Write([]byte("backups are not configured"))
+		return
+	}
+
+	request := models.PostRestoreJSONRequestBody{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	path := ""
+	if request.Path != nil {
+		path = *request.Path
+	}
+
+	backup, err := p.backupService.Restore(r.Context(), path)
+	if errors.Is(err, services.ErrBackupPath) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, backup)
+}
+
+func (p *provider) GetCardinality(w http.ResponseWriter, r *http.Request, params models.GetCardinalityParams) {
+	limit := defaultCardinalityLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit < 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("limit must be positive"))
+		return
+	}
+
+	report, err := p.cadvisorService.GetCardinality(r.Context(), limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, report)
+}
+
+func (p *provider) GetFsImages(w http.ResponseWriter, r *http.Request) {
+	fsInfoList, err := p.cadvisorService.GetImagesFsInfo(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, fsInfoList)
+}
+
+func (p *provider) GetStatsSummary(w http.ResponseWriter, r *http.Request, params models.GetStatsSummaryParams) {
+	var at *time.Time
+	if params.Time != nil {
+		t := time.Unix(int64(*params.Time), 0)
+		if t.After(time.Now()) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("time must not be in the future"))
+			return
+		}
+		at = &t
+	}
+
+	summary, err := p.cadvisorService.GetSummary(r.Context(), at)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeJson(w, summary)
+}
+
+func (p *provider) GetFederationTop(w http.ResponseWriter, r *http.Request, params models.GetFederationTopParams) {
+	notAggregator(w)
+}
+
+func (p *provider) GetFederationSum(w http.ResponseWriter, r *http.Request, params models.GetFederationSumParams) {
+	notAggregator(w)
+}
+
+func notAggregator(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotImplemented)
+	w.Write([]byte("federation queries are served by aggregators, started with --agents"))
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/internal/services"
+	"github.com/zawachte/stalker/pkg/remotewrite"
+)
+
+// pointStore is a CAdvisorService recording the points posted to it, its
+// other methods are not implemented.
+type pointStore struct {
+	services.CAdvisorService
+	points []*write.Point
+}
+
+func (s *pointStore) PostPoints(ctx context.Context, points []*write.Point) error {
+	s.points = append(s.points, points...)
+	return nil
+}
+
+func postApiV1Write(p *provider, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(body))
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec := httptest.NewRecorder()
+	p.PostApiV1Write(rec, req)
+	return rec
+}
+
+func TestPostApiV1Write(t *testing.T) {
+	store := &pointStore{}
+	p := &provider{cadvisorService: store}
+
+	body := remotewrite.EncodeWriteRequest([]remotewrite.TimeSeries{{
+		Labels: []remotewrite.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "code", Value: "200"}},
+		Samples: []remotewrite.Sample{
+			{Value: 10, Timestamp: 1600000000000},
+			{Value: math.NaN(), Timestamp: 1600000010000},
+			{Value: 12, Timestamp: 1600000020000},
+		},
+	}})
+	rec := postApiV1Write(p, body)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("got %d %s, want 204", rec.Code, rec.Body)
+	}
+
+	if len(store.points) != 2 {
+		t.Fatalf("stored %d points, want the 2 finite samples", len(store.points))
+	}
+	for i, want := range []float64{10, 12} {
+		point := store.points[i]
+		if point.Name() != "http_requests_total" {
+			t.Errorf("point %d measurement = %s", i, point.Name())
+		}
+		if tags := point.TagList(); len(tags) != 1 || tags[0].Key != "code" || tags[0].Value != "200" {
+			t.Errorf("point %d tags = %v, want code=200", i, tags)
+		}
+		if value := point.FieldList()[0].Value; value != want {
+			t.Errorf("point %d value = %v, want %v", i, value, want)
+		}
+	}
+}
+
+func TestPostApiV1WriteRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+		code int
+	}{
+		{name: "not snappy", body: []byte("not snappy"), code: http.StatusBadRequest},
+		{name: "not protobuf", body: snappy.Encode(nil, []byte{0xff, 0xff}), code: http.StatusBadRequest},
+		{name: "too large", body: make([]byte, maxRemoteWriteBytes+1), code: http.StatusRequestEntityTooLarge},
+		{name: "too large decompressed", body: snappy.Encode(nil, make([]byte, remotewrite.MaxDecodedBytes+1)), code: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &pointStore{}
+			rec := postApiV1Write(&provider{cadvisorService: store}, tt.body)
+			if rec.Code != tt.code {
+				t.Errorf("got %d %s, want %d", rec.Code, rec.Body, tt.code)
+			}
+			if len(store.points) != 0 {
+				t.Errorf("stored %d points", len(store.points))
+			}
+		})
+	}
+}
+
+func TestGetV2MetricsListRejectsStep(t *testing.T) {
+	rollup := models.GetV2MetricsListParamsRollup("pod")
+	for _, step := range []int{0, -10} {
+		step := step
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v2/metricsList", nil)
+		p := &provider{cadvisorService: &pointStore{}}
+		p.GetV2MetricsList(rec, req, models.GetV2MetricsListParams{Rollup: &rollup, Step: &step})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("step %d: got %d %s, want 400", step, rec.Code, rec.Body)
+		}
+	}
+}
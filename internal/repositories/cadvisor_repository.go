@@ -3,9 +3,13 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	cadvisorapiv2 "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 	"github.com/zawachte/stalker/internal/models"
 	"github.com/zawachte/stalker/pkg/influx"
 )
@@ -13,7 +17,14 @@ import (
 // CAdvisorRepository
 type CAdvisorRepository interface {
 	PostStats(context.Context, *cadvisorapiv2.ContainerInfo, *cadvisorapiv2.ContainerStats) error
+	PostPoints(context.Context, []*write.Point) error
 	GetMetricsList(context.Context) (models.MetricsList, error)
+	GetMetricsListInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.MetricsList, error)
+	GetSeries(context.Context) (models.SeriesList, error)
+	GetSeriesInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.SeriesList, error)
+	StreamMetricsInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter, fn func(influx.Record) error) error
+	GetEvents(ctx context.Context, start, stop time.Time, eventTypes []string) (models.EventList, error)
+	GetAnomalies(ctx context.Context, start, stop time.Time, series []string) (models.AnomalyList, error)
 }
 
 type CAdvisorRepositoryParams struct {
@@ -52,12 +63,30 @@ func (cr *cadvisorRepositoryInfluxDB) PostStats(ctx context.Context, info *cadvi
 	return nil
 }
 
+func (cr *cadvisorRepositoryInfluxDB) PostPoints(ctx context.Context, points []*write.Point) error {
+	return cr.cadvisorInfluxClient.AddPoints(points...)
+}
+
 func (cr *cadvisorRepositoryInfluxDB) GetMetricsList(ctx context.Context) (models.MetricsList, error) {
 	stats, err := cr.cadvisorInfluxClient.GetStats()
 	if err != nil {
 		return models.MetricsList{}, err
 	}
 
+	return recordsToMetricsList(stats)
+}
+
+func (cr *cadvisorRepositoryInfluxDB) GetMetricsListInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.MetricsList, error) {
+	stats, err := cr.cadvisorInfluxClient.GetStatsInPeriod(ctx, start, stop, filter)
+	if err != nil {
+		return models.MetricsList{}, err
+	}
+
+	return recordsToMetricsList(stats)
+}
+
+// recordsToMetricsList encodes every flux record as a JSON object.
+func recordsToMetricsList(stats []map[string]interface{}) (models.MetricsList, error) {
 	metrics := []string{}
 	for _, stat := range stats {
 		statByte, err := json.Marshal(stat)
@@ -73,6 +102,146 @@ func (cr *cadvisorRepositoryInfluxDB) GetMetricsList(ctx context.Context) (model
 	}, nil
 }
 
+func (cr *cadvisorRepositoryInfluxDB) GetSeries(ctx context.Context) (models.SeriesList, error) {
+	stats, err := cr.cadvisorInfluxClient.GetStats()
+	if err != nil {
+		return models.SeriesList{}, err
+	}
+
+	return recordsToSeriesList(stats), nil
+}
+
+func (cr *cadvisorRepositoryInfluxDB) GetSeriesInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.SeriesList, error) {
+	stats, err := cr.cadvisorInfluxClient.GetStatsInPeriod(ctx, start, stop, filter)
+	if err != nil {
+		return models.SeriesList{}, err
+	}
+
+	return recordsToSeriesList(stats), nil
+}
+
+func (cr *cadvisorRepositoryInfluxDB) StreamMetricsInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter, fn func(influx.Record) error) error {
+	return cr.cadvisorInfluxClient.StreamStatsInPeriod(ctx, start, stop, filter, fn)
+}
+
+// recordsToSeriesList groups flux records into series by measurement, field
+// and tags. Records with string values are left out.
+func recordsToSeriesList(stats []map[string]interface{}) models.SeriesList {
+	list := models.SeriesList{
+		Series: []models.Series{},
+	}
+	index := map[string]int{}
+	for _, stat := range stats {
+		value, ok := influx.ToFloat(stat["_value"])
+		if !ok {
+			continue
+		}
+		timestamp, _ := stat["_time"].(time.Time)
+		measurement, _ := stat["_measurement"].(string)
+		field, _ := stat["_field"].(string)
+
+		tags := map[string]string{}
+		for k, v := range stat {
+			tag, ok := v.(string)
+			if ok && !strings.HasPrefix(k, "_") && k != "result" && k != "table" {
+				tags[k] = tag
+			}
+		}
+
+		key := seriesKey(measurement, field, tags)
+		i, ok := index[key]
+		if !ok {
+			i = len(list.Series)
+			index[key] = i
+			list.Series = append(list.Series, models.Series{
+				Measurement: measurement,
+				Field:       field,
+				Tags:        models.Series_Tags{AdditionalProperties: tags},
+				Points:      []models.Point{},
+			})
+		}
+		list.Series[i].Points = append(list.Series[i].Points, models.Point{
+			Timestamp: timestamp,
+			Value:     value,
+		})
+	}
+
+	for _, series := range list.Series {
+		points := series.Points
+		sort.SliceStable(points, func(i, j int) bool {
+			return points[i].Timestamp.Before(points[j].Timestamp)
+		})
+	}
+
+	return list
+}
+
+func seriesKey(measurement, field string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		keys = append(keys, k+"\xff"+v)
+	}
+	sort.Strings(keys)
+
+	return measurement + "\xff" + field + "\xff" + strings.Join(keys, "\xff")
+}
+
+func (cr *cadvisorRepositoryInfluxDB) GetEvents(ctx context.Context, start, stop time.Time, eventTypes []string) (models.EventList, error) {
+	stored, err := cr.cadvisorInfluxClient.GetEvents(ctx, start, stop, eventTypes)
+	if err != nil {
+		return models.EventList{}, err
+	}
+
+	events := []models.Event{}
+	for _, e := range stored {
+		event := models.Event{
+			Timestamp:     e.Timestamp,
+			Type:          e.Type,
+			ContainerName: e.ContainerName,
+			Tags:          &models.Event_Tags{AdditionalProperties: e.Tags},
+		}
+		if e.ProcessName != "" {
+			pid := e.Pid
+			processName := e.ProcessName
+			event.Pid = &pid
+			event.ProcessName = &processName
+		}
+		events = append(events, event)
+	}
+
+	return models.EventList{
+		Events: &events,
+	}, nil
+}
+
+func (cr *cadvisorRepositoryInfluxDB) GetAnomalies(ctx context.Context, start, stop time.Time, series []string) (models.AnomalyList, error) {
+	stored, err := cr.cadvisorInfluxClient.GetAnomalies(ctx, start, stop, series)
+	if err != nil {
+		return models.AnomalyList{}, err
+	}
+
+	anomalies := []models.Anomaly{}
+	for _, a := range stored {
+		anomaly := models.Anomaly{
+			Timestamp: a.Timestamp,
+			Series:    a.Series,
+			Method:    models.AnomalyMethod(a.Method),
+			Value:     a.Value,
+			Expected:  a.Expected,
+			Deviation: a.Deviation,
+			Tags:      &models.Anomaly_Tags{AdditionalProperties: a.Tags},
+		}
+		if containerName, ok := a.Tags[influx.TagContainerName]; ok {
+			anomaly.ContainerName = &containerName
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+
+	return models.AnomalyList{
+		Anomalies: anomalies,
+	}, nil
+}
+
 type cadvisorRepositoryMemory struct {
 	mu        sync.Mutex
 	simpleMap map[int]models.MetricsList
@@ -82,6 +251,34 @@ func (cr *cadvisorRepositoryMemory) PostStats(ctx context.Context, info *cadviso
 	return nil
 }
 
+func (cr *cadvisorRepositoryMemory) PostPoints(ctx context.Context, points []*write.Point) error {
+	return nil
+}
+
 func (cr *cadvisorRepositoryMemory) GetMetricsList(ctx context.Context) (models.MetricsList, error) {
 	return models.MetricsList{}, nil
 }
+
+func (cr *cadvisorRepositoryMemory) GetMetricsListInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.MetricsList, error) {
+	return models.MetricsList{}, nil
+}
+
+func (cr *cadvisorRepositoryMemory) GetSeries(ctx context.Context) (models.SeriesList, error) {
+	return models.SeriesList{Series: []models.Series{}}, nil
+}
+
+func (cr *cadvisorRepositoryMemory) GetSeriesInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.SeriesList, error) {
+	return models.SeriesList{Series: []models.Series{}}, nil
+}
+
+func (cr *cadvisorRepositoryMemory) StreamMetricsInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter, fn func(influx.Record) error) error {
+	return nil
+}
+
+func (cr *cadvisorRepositoryMemory) GetEvents(ctx context.Context, start, stop time.Time, eventTypes []string) (models.EventList, error) {
+	return models.EventList{}, nil
+}
+
+func (cr *cadvisorRepositoryMemory) GetAnomalies(ctx context.Context, start, stop time.Time, series []string) (models.AnomalyList, error) {
+	return models.AnomalyList{Anomalies: []models.Anomaly{}}, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/pkg/influx_cli"
+)
+
+// BackupService backs up and restores the stored metrics.
+type BackupService interface {
+	Backup(context.Context) (models.Backup, error)
+	// Restore replaces the stored metrics with the latest backup in path, or
+	// in the backup path when path is empty. Path must be a directory under
+	// the backup path, relative paths are relative to it.
+	Restore(ctx context.Context, path string) (models.Backup, error)
+}
+
+// ErrBackupPath is returned when a restore names a directory outside of the
+// backup path.
+var ErrBackupPath = errors.New("restores are limited to directories under the backup path")
+
+type BackupServiceParams struct {
+	InfluxCli influx_cli.Client
+	Org       string
+	Bucket    string
+	// Path is the directory backups are written to.
+	Path string
+}
+
+// NewBackupService creates a backup service.
+func NewBackupService(params BackupServiceParams) BackupService {
+	return &backupService{params: params}
+}
+
+type backupService struct {
+	params BackupServiceParams
+
+	// Backups and restores of the same bucket must not overlap.
+	mu sync.Mutex
+}
+
+func (bs *backupService) Backup(ctx context.Context) (models.Backup, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	err := bs.params.InfluxCli.BackupInflux(influx_cli.BackupInfluxParams{
+		Org:    bs.params.Org,
+		Bucket: bs.params.Bucket,
+		Path:   bs.params.Path,
+	})
+	if err != nil {
+		return models.Backup{}, err
+	}
+
+	return models.Backup{
+		Path:      bs.params.Path,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+func (bs *backupService) Restore(ctx context.Context, path string) (models.Backup, error) {
+	path, err := bs.backupDir(path)
+	if err != nil {
+		return models.Backup{}, err
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	err = bs.params.InfluxCli.RestoreInflux(influx_cli.RestoreInfluxParams{
+		Org:    bs.params.Org,
+		Bucket: bs.params.Bucket,
+		Path:   path,
+	})
+	if err != nil {
+		return models.Backup{}, err
+	}
+
+	return models.Backup{
+		Path:      path,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// backupDir resolves the directory a restore reads from, following symbolic
+// links, and rejects directories outside of the backup path.
+func (bs *backupService) backupDir(path string) (string, error) {
+	root, err := filepath.Abs(bs.params.Path)
+	if err != nil {
+		return "", err
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return root, nil
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBackupPath, err)
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s is not under %s", ErrBackupPath, path, root)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zawachte/stalker/pkg/influx_cli"
+)
+
+// restoreRecorder is an influx_cli.Client recording the paths restored from.
+type restoreRecorder struct {
+	influx_cli.Client
+	paths []string
+}
+
+func (r *restoreRecorder) RestoreInflux(params influx_cli.RestoreInfluxParams) error {
+	r.paths = append(r.paths, params.Path)
+	return nil
+}
+
+func TestRestorePath(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, "backups")
+	for _, d := range []string{filepath.Join(root, "daily"), filepath.Join(dir, "elsewhere")} {
+		err = os.MkdirAll(d, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.Symlink(filepath.Join(dir, "elsewhere"), filepath.Join(root, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: root},
+		{path: "daily", want: filepath.Join(root, "daily")},
+		{path: filepath.Join(root, "daily"), want: filepath.Join(root, "daily")},
+		{path: "daily/../daily", want: filepath.Join(root, "daily")},
+		{path: ".."},
+		{path: "../elsewhere"},
+		{path: filepath.Join(dir, "elsewhere")},
+		{path: "/etc"},
+		{path: "link"},
+		{path: "missing"},
+	}
+
+	for _, tt := range tests {
+		influx := &restoreRecorder{}
+		bs := NewBackupService(BackupServiceParams{InfluxCli: influx, Org: "stalker", Bucket: "stalker", Path: root})
+
+		backup, err := bs.Restore(context.Background(), tt.path)
+		if tt.want == "" {
+			if !errors.Is(err, ErrBackupPath) || len(influx.paths) != 0 {
+				t.Errorf("%q: got error %v and restores %v, want ErrBackupPath", tt.path, err, influx.paths)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.path, err)
+			continue
+		}
+		if len(influx.paths) != 1 || influx.paths[0] != tt.want || backup.Path != tt.want {
+			t.Errorf("%q: restored %v, want %s", tt.path, influx.paths, tt.want)
+		}
+	}
+}
@@ -2,24 +2,95 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
 
+	cadvisormetrics "github.com/google/cadvisor/container"
+	"github.com/google/cadvisor/events"
+	cadvisorapi "github.com/google/cadvisor/info/v1"
 	cadvisorapiv2 "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 	"github.com/zawachte/stalker/internal/models"
 	"github.com/zawachte/stalker/internal/repositories"
+	"github.com/zawachte/stalker/pkg/alerting"
+	"github.com/zawachte/stalker/pkg/anomaly"
 	"github.com/zawachte/stalker/pkg/cadvisor"
+	"github.com/zawachte/stalker/pkg/cardinality"
+	"github.com/zawachte/stalker/pkg/filter"
+	"github.com/zawachte/stalker/pkg/influx"
+	"github.com/zawachte/stalker/pkg/procs"
+	"github.com/zawachte/stalker/pkg/psi"
+	"github.com/zawachte/stalker/pkg/stream"
 )
 
 // FleetService
 type CAdvisorService interface {
+	// Scrape collects, stores and forwards the latest stats of the
+	// containers. It is called on the scrape interval only, reads are served
+	// from the stored points of the previous scrapes.
+	Scrape(context.Context) error
 	GetMetricsList(context.Context) (models.MetricsList, error)
-	GetMetricsListInPeriod(context.Context, int, int) (models.MetricsList, error)
+	GetMetricsListInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.MetricsList, error)
+	GetSeries(context.Context) (models.SeriesList, error)
+	GetSeriesInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.SeriesList, error)
+	StreamMetricsInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter, fn func(influx.Record) error) error
+	GetContainers(context.Context) (models.ContainerList, error)
+	GetLatestPoints(context.Context) ([]*write.Point, error)
+	PostPoints(context.Context, []*write.Point) error
+	ImportContainerInfos(ctx context.Context, machineName string, infos map[string]cadvisorapiv2.ContainerInfo) (int, error)
+	Subscribe(stream.Filter) *stream.Subscription
+	Unsubscribe(*stream.Subscription)
+	GetEvents(ctx context.Context, start, stop time.Time, eventTypes []string) (models.EventList, error)
+	GetMachineInfo(context.Context) (models.MachineInfo, error)
+	GetVersionInfo(context.Context) (models.VersionInfo, error)
+	GetRootFsInfo(context.Context) (models.FsInfo, error)
+	GetImagesFsInfo(context.Context) (models.FsInfoList, error)
+	GetSummary(ctx context.Context, at *time.Time) (models.Summary, error)
+	GetCardinality(ctx context.Context, limit int) (models.CardinalityReport, error)
+	GetAlerts(ctx context.Context, state string) (models.AlertList, error)
+	GetAnomalies(ctx context.Context, start, stop time.Time, series []string) (models.AnomalyList, error)
+}
+
+// PointSink receives the points collected on every scrape.
+type PointSink interface {
+	WritePoints(context.Context, []*write.Point) error
 }
 
 type CAdvisorServiceParams struct {
 	DatabaseUrl   string
 	DatabaseToken string
+	Sinks         []PointSink
+	// Containers selects the collected containers, nil collects all of them.
+	Containers *filter.File
+	// LabelPolicy selects the container labels kept as tags, nil keeps all of them.
+	LabelPolicy *influx.LabelPolicyFile
+	// SeriesBudget caps the number of series per container, 0 disables the cap.
+	SeriesBudget int
+	// CAdvisor selects the collected metric sets and housekeeping intervals.
+	CAdvisor cadvisor.Options
+	// RuntimeEndpoint is the container runtime socket used to locate the
+	// image filesystem, detected when empty.
+	RuntimeEndpoint string
+	// TopProcesses is the number of processes per container reported for
+	// each order in TopProcessesBy, 0 disables the report.
+	TopProcesses   int
+	TopProcessesBy []string
+	// Alerts evaluates alerting rules on every scrape, nil disables alerting.
+	Alerts *alerting.Engine
+	// Anomalies flags the samples deviating from the baseline of their
+	// series, nil disables anomaly detection.
+	Anomalies *anomaly.Detector
 }
 
+// processSampleTTL is how long the cpu sample of a process that was not seen
+// again is kept.
+const processSampleTTL = 10 * time.Minute
+
 // NewCAdvisorService creates an cadvisor service.
 func NewCAdvisorService(ctx context.Context, params CAdvisorServiceParams) (CAdvisorService, error) {
 	repo, err := repositories.NewCAdvisorRepository(ctx, repositories.CAdvisorRepositoryParams{
@@ -30,25 +101,103 @@ func NewCAdvisorService(ctx context.Context, params CAdvisorServiceParams) (CAdv
 		return nil, err
 	}
 
-	imageFsInfoProvider := cadvisor.NewImageFsInfoProvider("")
-	cadvisorInterface, err := cadvisor.New(imageFsInfoProvider, "var/lib/kubelet", []string{}, true)
+	runtimeEndpoint := params.RuntimeEndpoint
+	if runtimeEndpoint == "" {
+		runtimeEndpoint = cadvisor.DetectRuntimeEndpoint()
+	}
+
+	for _, by := range params.TopProcessesBy {
+		if by != procs.ByCPU && by != procs.ByRSS {
+			return nil, fmt.Errorf("top processes can be ranked by %s or %s, not %q", procs.ByCPU, procs.ByRSS, by)
+		}
+	}
+
+	imageFsInfoProvider := cadvisor.NewImageFsInfoProvider(runtimeEndpoint)
+	cadvisorInterface, err := cadvisor.New(imageFsInfoProvider, "var/lib/kubelet", []string{}, params.CAdvisor)
 	if err != nil {
 		return nil, err
 	}
 
-	err = cadvisorInterface.Start()
+	pointConverter, err := influx.NewPointConverter()
 	if err != nil {
 		return nil, err
 	}
-	return &cadvisorService{
+	pointConverter.SetIncludedMetrics(cadvisorInterface.IncludedMetrics())
+
+	broadcaster := stream.NewBroadcaster(0)
+
+	cs := &cadvisorService{
 		cadvisorRepository: repo,
 		cadvisorInterface:  cadvisorInterface,
-	}, nil
+		pointConverter:     pointConverter,
+		broadcaster:        broadcaster,
+		sinks:              append([]PointSink{broadcaster, params.Alerts}, params.Sinks...),
+		containers:         params.Containers,
+		labelPolicy:        params.LabelPolicy,
+		series:             cardinality.NewTracker(params.SeriesBudget, 0),
+		oomEvents:          map[string]uint64{},
+		collectImagesFs:    runtimeEndpoint != "",
+		processes:          procs.NewSampler(),
+		topProcesses:       params.TopProcesses,
+		topProcessesBy:     params.TopProcessesBy,
+		collectPressure:    psi.Supported(),
+		alerts:             params.Alerts,
+		anomalies:          params.Anomalies,
+	}
+
+	// Watch before starting the manager so the creation events of containers
+	// discovered at startup are not missed.
+	eventChannel, err := cadvisorInterface.WatchEvents(&events.Request{
+		EventType: map[cadvisorapi.EventType]bool{
+			cadvisorapi.EventContainerCreation: true,
+			cadvisorapi.EventContainerDeletion: true,
+			cadvisorapi.EventOom:               true,
+			cadvisorapi.EventOomKill:           true,
+		},
+		ContainerName:        "/",
+		IncludeSubcontainers: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	go cs.recordEvents(ctx, eventChannel.GetChannel())
+
+	err = cadvisorInterface.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	return cs, nil
 }
 
 type cadvisorService struct {
 	cadvisorRepository repositories.CAdvisorRepository
 	cadvisorInterface  cadvisor.Interface
+	pointConverter     *influx.PointConverter
+	broadcaster        *stream.Broadcaster
+	sinks              []PointSink
+	containers         *filter.File
+	labelPolicy        *influx.LabelPolicyFile
+	series             *cardinality.Tracker
+
+	machineInfoLock sync.Mutex
+	lastMachineInfo []byte
+
+	oomEventsLock sync.Mutex
+	oomEvents     map[string]uint64
+
+	// Whether a container runtime was found whose image filesystem is scraped.
+	collectImagesFs bool
+
+	processes      *procs.Sampler
+	topProcesses   int
+	topProcessesBy []string
+
+	// Whether the host reports pressure stall information per cgroup.
+	collectPressure bool
+
+	alerts    *alerting.Engine
+	anomalies *anomaly.Detector
 }
 
 func latestContainerStats(info *cadvisorapiv2.ContainerInfo) (*cadvisorapiv2.ContainerStats, bool) {
@@ -63,32 +212,592 @@ func latestContainerStats(info *cadvisorapiv2.ContainerInfo) (*cadvisorapiv2.Con
 	return latest, true
 }
 
-func (cs *cadvisorService) GetMetricsList(ctx context.Context) (models.MetricsList, error) {
-
+// Scrape collects and stores the latest stats of the containers, and
+// forwards them to the sinks.
+func (cs *cadvisorService) Scrape(ctx context.Context) error {
 	infos, err := cs.cadvisorInterface.ContainerInfoV2("/", cadvisorapiv2.RequestOptions{
 		IdType:    cadvisorapiv2.TypeName,
 		Count:     2, // 2 samples are needed to compute "instantaneous" CPU
 		Recursive: true,
 	})
 	if err != nil {
-		return models.MetricsList{}, err
+		return err
 	}
 
-	for _, info := range infos {
+	cs.pointConverter.SetLabelPolicy(cs.labelPolicy.Policy())
+	containerFilter := cs.containers.Filter()
+	scraped := []*write.Point{}
+	for name, info := range infos {
+		if !containerFilter.Matches(name, &info) {
+			continue
+		}
+
 		stat, ok := latestContainerStats(&info)
 		if !ok {
 			continue
 		}
 
-		err := cs.cadvisorRepository.PostStats(ctx, &info, stat)
+		points := cs.series.Admit(name, cs.containerPoints(name, &info, stat, true))
+		err := cs.cadvisorRepository.PostPoints(ctx, points)
+		if err != nil {
+			return err
+		}
+		scraped = append(scraped, points...)
+	}
+	cs.series.Expire()
+	cs.processes.Expire(time.Now().Add(-processSampleTTL))
+
+	if cs.collectImagesFs {
+		points, err := cs.imagesFsPoints()
+		if err != nil {
+			log.Printf("failed to collect image filesystem usage: %v", err)
+		} else {
+			err := cs.cadvisorRepository.PostPoints(ctx, points)
+			if err != nil {
+				return err
+			}
+			scraped = append(scraped, points...)
+		}
+	}
+
+	machinePoints, err := cs.machineInfoSnapshot()
+	if err != nil {
+		return err
+	}
+	if len(machinePoints) > 0 {
+		err := cs.cadvisorRepository.PostPoints(ctx, machinePoints)
+		if err != nil {
+			return err
+		}
+		scraped = append(scraped, machinePoints...)
+	}
+
+	anomalies := cs.anomalies.Detect(scraped)
+	if len(anomalies) > 0 {
+		points := make([]*write.Point, 0, len(anomalies))
+		for _, a := range anomalies {
+			points = append(points, influx.AnomalyToPoint(a))
+		}
+		err := cs.cadvisorRepository.PostPoints(ctx, points)
 		if err != nil {
-			return models.MetricsList{}, err
+			return err
 		}
 	}
 
+	for _, sink := range cs.sinks {
+		err := sink.WritePoints(ctx, scraped)
+		if err != nil {
+			log.Printf("failed to forward %d points: %v", len(scraped), err)
+		}
+	}
+
+	return nil
+}
+
+// GetMetricsList returns the records of the last minute stored by the
+// previous scrapes.
+func (cs *cadvisorService) GetMetricsList(ctx context.Context) (models.MetricsList, error) {
 	return cs.cadvisorRepository.GetMetricsList(ctx)
 }
 
-func (cs *cadvisorService) GetMetricsListInPeriod(ctx context.Context, startTime, endTime int) (models.MetricsList, error) {
-	return models.MetricsList{}, nil
+// GetSeries returns the series of the last minute stored by the previous
+// scrapes.
+func (cs *cadvisorService) GetSeries(ctx context.Context) (models.SeriesList, error) {
+	return cs.cadvisorRepository.GetSeries(ctx)
+}
+
+// GetMetricsListInPeriod returns the stored records of a past period without scraping.
+func (cs *cadvisorService) GetMetricsListInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.MetricsList, error) {
+	return cs.cadvisorRepository.GetMetricsListInPeriod(ctx, start, stop, filter)
+}
+
+// GetSeriesInPeriod returns the stored series of a past period without scraping.
+func (cs *cadvisorService) GetSeriesInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter) (models.SeriesList, error) {
+	return cs.cadvisorRepository.GetSeriesInPeriod(ctx, start, stop, filter)
+}
+
+// StreamMetricsInPeriod calls fn with the stored records of a past period as
+// they are read.
+func (cs *cadvisorService) StreamMetricsInPeriod(ctx context.Context, start, stop time.Time, filter influx.StatsFilter, fn func(influx.Record) error) error {
+	return cs.cadvisorRepository.StreamMetricsInPeriod(ctx, start, stop, filter, fn)
+}
+
+// GetContainers lists the containers selected by the container filter.
+func (cs *cadvisorService) GetContainers(ctx context.Context) (models.ContainerList, error) {
+	infos, err := cs.cadvisorInterface.ContainerInfoV2("/", cadvisorapiv2.RequestOptions{
+		IdType:    cadvisorapiv2.TypeName,
+		Count:     1,
+		Recursive: true,
+	})
+	if err != nil {
+		return models.ContainerList{}, err
+	}
+
+	containerFilter := cs.containers.Filter()
+	containers := []models.Container{}
+	for cgroup, info := range infos {
+		if !containerFilter.Matches(cgroup, &info) {
+			continue
+		}
+
+		container := models.Container{
+			Cgroup: cgroup,
+			Name:   cgroup,
+		}
+		if len(info.Spec.Aliases) > 0 {
+			aliases := info.Spec.Aliases
+			container.Name = aliases[0]
+			container.Aliases = &aliases
+		}
+		if info.Spec.Image != "" {
+			container.Image = stringPtr(info.Spec.Image)
+		}
+		if len(info.Spec.Labels) > 0 {
+			container.Labels = &models.Container_Labels{AdditionalProperties: info.Spec.Labels}
+		}
+		if !info.Spec.CreationTime.IsZero() {
+			createdAt := info.Spec.CreationTime
+			container.CreatedAt = &createdAt
+		}
+		containers = append(containers, container)
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Cgroup < containers[j].Cgroup
+	})
+
+	return models.ContainerList{Containers: containers}, nil
+}
+
+// GetLatestPoints converts the most recent cadvisor sample of every container into points.
+func (cs *cadvisorService) GetLatestPoints(ctx context.Context) ([]*write.Point, error) {
+	infos, err := cs.cadvisorInterface.ContainerInfoV2("/", cadvisorapiv2.RequestOptions{
+		IdType:    cadvisorapiv2.TypeName,
+		Count:     1,
+		Recursive: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	containerFilter := cs.containers.Filter()
+	points := []*write.Point{}
+	for name, info := range infos {
+		if !containerFilter.Matches(name, &info) {
+			continue
+		}
+
+		stat, ok := latestContainerStats(&info)
+		if !ok {
+			continue
+		}
+
+		points = append(points, cs.containerPoints(name, &info, stat, false)...)
+	}
+
+	return points, nil
+}
+
+// containerPoints converts a container sample, including the OOM events
+// counted for the container when that metric set is collected, its pressure
+// stall information on cgroup v2 hosts and the top processes when they are
+// enabled. Only scrapes sample the CPU usage of the processes, other callers
+// report the usage over the interval between the last two scrapes.
+func (cs *cadvisorService) containerPoints(name string, info *cadvisorapiv2.ContainerInfo, stat *cadvisorapiv2.ContainerStats, scrape bool) []*write.Point {
+	points := cs.pointConverter.StatsToPoints(info, stat)
+	if cs.pointConverter.Includes(cadvisormetrics.OOMMetrics) {
+		cs.oomEventsLock.Lock()
+		count := cs.oomEvents[name]
+		cs.oomEventsLock.Unlock()
+
+		points = append(points, cs.pointConverter.OomEventsToPoint(info, stat, count))
+	}
+
+	if cs.collectPressure {
+		pressure, err := psi.Read(name)
+		if err != nil {
+			log.Printf("failed to read pressure of %s: %v", name, err)
+		} else {
+			points = append(points, cs.pointConverter.PressureToPoints(info, stat, pressure)...)
+		}
+	}
+
+	if cs.topProcesses > 0 && stat.Processes != nil {
+		var processes []procs.Process
+		var err error
+		if scrape {
+			processes, err = cs.processes.Processes(name)
+		} else {
+			processes, err = cs.processes.Latest(name)
+		}
+		if err != nil {
+			log.Printf("failed to list processes of %s: %v", name, err)
+		} else {
+			for _, by := range cs.topProcessesBy {
+				top := procs.Top(processes, cs.topProcesses, by)
+				points = append(points, cs.pointConverter.TopProcessesToPoints(info, stat, by, top)...)
+			}
+		}
+	}
+
+	tagCgroupPoints(name, info, points)
+	return points
+}
+
+// tagCgroupPoints tags the points of the root cgroup and of kubernetes pods,
+// which the converter cannot tell from their container info.
+func tagCgroupPoints(name string, info *cadvisorapiv2.ContainerInfo, points []*write.Point) {
+	if name == "/" {
+		influx.TagRootPoints(points)
+	}
+	influx.TagPodPoints(name, info, points)
+}
+
+// PostPoints stores points received from outside the collection loop.
+func (cs *cadvisorService) PostPoints(ctx context.Context, points []*write.Point) error {
+	return cs.cadvisorRepository.PostPoints(ctx, points)
+}
+
+// ImportContainerInfos converts every sample of container infos collected
+// elsewhere, tagged with machineName or else the local hostname, and stores
+// the points. It returns the number of points stored. The container filter
+// and series budget only apply to the collection loop.
+func (cs *cadvisorService) ImportContainerInfos(ctx context.Context, machineName string, infos map[string]cadvisorapiv2.ContainerInfo) (int, error) {
+	converter := cs.pointConverter
+	if machineName != "" {
+		converter = converter.WithMachineName(machineName)
+	}
+	converter.SetLabelPolicy(cs.labelPolicy.Policy())
+
+	count := 0
+	for name := range infos {
+		info := infos[name]
+		points := []*write.Point{}
+		for _, stat := range info.Stats {
+			points = append(points, converter.StatsToPoints(&info, stat)...)
+		}
+		tagCgroupPoints(name, &info, points)
+		if len(points) == 0 {
+			continue
+		}
+
+		err := cs.cadvisorRepository.PostPoints(ctx, points)
+		if err != nil {
+			return count, err
+		}
+		count += len(points)
+	}
+
+	return count, nil
+}
+
+// Subscribe registers a live subscriber for the points of upcoming scrapes.
+func (cs *cadvisorService) Subscribe(filter stream.Filter) *stream.Subscription {
+	return cs.broadcaster.Subscribe(filter)
+}
+
+func (cs *cadvisorService) Unsubscribe(sub *stream.Subscription) {
+	cs.broadcaster.Unsubscribe(sub)
+}
+
+// recordEvents stores every container event and forwards it to the sinks.
+func (cs *cadvisorService) recordEvents(ctx context.Context, eventChannel <-chan *cadvisorapi.Event) {
+	for event := range eventChannel {
+		cs.countOomEvent(event)
+
+		var cInfo *cadvisorapiv2.ContainerInfo
+		infos, err := cs.cadvisorInterface.ContainerInfoV2(event.ContainerName, cadvisorapiv2.RequestOptions{
+			IdType: cadvisorapiv2.TypeName,
+			Count:  1,
+		})
+		if err == nil {
+			if info, ok := infos[event.ContainerName]; ok {
+				cInfo = &info
+			}
+		}
+
+		points := []*write.Point{cs.pointConverter.EventToPoint(event, cInfo)}
+		err = cs.cadvisorRepository.PostPoints(ctx, points)
+		if err != nil {
+			log.Printf("failed to store %s event for %q: %v", event.EventType, event.ContainerName, err)
+		}
+
+		for _, sink := range cs.sinks {
+			err := sink.WritePoints(ctx, points)
+			if err != nil {
+				log.Printf("failed to forward %s event for %q: %v", event.EventType, event.ContainerName, err)
+			}
+		}
+	}
+}
+
+// countOomEvent keeps the number of OOM events per container for as long as
+// the container exists.
+func (cs *cadvisorService) countOomEvent(event *cadvisorapi.Event) {
+	cs.oomEventsLock.Lock()
+	defer cs.oomEventsLock.Unlock()
+
+	switch event.EventType {
+	case cadvisorapi.EventOom:
+		cs.oomEvents[event.ContainerName]++
+	case cadvisorapi.EventContainerDeletion:
+		delete(cs.oomEvents, event.ContainerName)
+	}
+}
+
+func (cs *cadvisorService) GetEvents(ctx context.Context, start, stop time.Time, eventTypes []string) (models.EventList, error) {
+	return cs.cadvisorRepository.GetEvents(ctx, start, stop, eventTypes)
+}
+
+func (cs *cadvisorService) GetAnomalies(ctx context.Context, start, stop time.Time, series []string) (models.AnomalyList, error) {
+	return cs.cadvisorRepository.GetAnomalies(ctx, start, stop, series)
+}
+
+// machineInfoSnapshot returns the machine info and CPU topology points when
+// the machine description changed since the last snapshot, and nil otherwise.
+func (cs *cadvisorService) machineInfoSnapshot() ([]*write.Point, error) {
+	machineInfo, err := cs.cadvisorInterface.MachineInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	// The timestamp changes on every refresh and is not part of the description.
+	described := *machineInfo
+	described.Timestamp = time.Time{}
+	current, err := json.Marshal(described)
+	if err != nil {
+		return nil, err
+	}
+
+	cs.machineInfoLock.Lock()
+	defer cs.machineInfoLock.Unlock()
+
+	if string(current) == string(cs.lastMachineInfo) {
+		return nil, nil
+	}
+
+	now := time.Now()
+	point, err := cs.pointConverter.MachineInfoToPoint(machineInfo, now)
+	if err != nil {
+		return nil, err
+	}
+	cs.lastMachineInfo = current
+
+	return append([]*write.Point{point}, cs.pointConverter.MachineTopologyToPoints(machineInfo, now)...), nil
+}
+
+func (cs *cadvisorService) GetMachineInfo(ctx context.Context) (models.MachineInfo, error) {
+	machineInfo, err := cs.cadvisorInterface.MachineInfo()
+	if err != nil {
+		return models.MachineInfo{}, err
+	}
+
+	topology := []models.NumaNode{}
+	for _, node := range machineInfo.Topology {
+		cores := []models.CpuCore{}
+		for _, core := range node.Cores {
+			threads := append([]int{}, core.Threads...)
+			cores = append(cores, models.CpuCore{
+				Id:       core.Id,
+				SocketId: core.SocketID,
+				Threads:  &threads,
+			})
+		}
+		topology = append(topology, models.NumaNode{
+			Id:     node.Id,
+			Memory: int64(node.Memory),
+			Cores:  &cores,
+		})
+	}
+
+	filesystems := []models.MachineFilesystem{}
+	for _, fs := range machineInfo.Filesystems {
+		filesystem := models.MachineFilesystem{
+			Device:   fs.Device,
+			Capacity: int64(fs.Capacity),
+			Type:     stringPtr(fs.Type),
+		}
+		if fs.HasInodes {
+			inodes := int64(fs.Inodes)
+			filesystem.Inodes = &inodes
+		}
+		filesystems = append(filesystems, filesystem)
+	}
+
+	networkDevices := []models.NetworkDevice{}
+	for _, device := range machineInfo.NetworkDevices {
+		speed := device.Speed
+		mtu := device.Mtu
+		networkDevices = append(networkDevices, models.NetworkDevice{
+			Name:       device.Name,
+			MacAddress: stringPtr(device.MacAddress),
+			Speed:      &speed,
+			Mtu:        &mtu,
+		})
+	}
+
+	timestamp := machineInfo.Timestamp
+	return models.MachineInfo{
+		Timestamp:        &timestamp,
+		CpuVendorId:      stringPtr(machineInfo.CPUVendorID),
+		NumCores:         machineInfo.NumCores,
+		NumPhysicalCores: machineInfo.NumPhysicalCores,
+		NumSockets:       machineInfo.NumSockets,
+		CpuFrequencyKhz:  int64(machineInfo.CpuFrequency),
+		MemoryCapacity:   int64(machineInfo.MemoryCapacity),
+		MachineId:        stringPtr(machineInfo.MachineID),
+		SystemUuid:       stringPtr(machineInfo.SystemUUID),
+		BootId:           stringPtr(machineInfo.BootID),
+		NumaNodes:        len(machineInfo.Topology),
+		Topology:         &topology,
+		Filesystems:      &filesystems,
+		NetworkDevices:   &networkDevices,
+		CloudProvider:    stringPtr(string(machineInfo.CloudProvider)),
+		InstanceType:     stringPtr(string(machineInfo.InstanceType)),
+	}, nil
+}
+
+// GetVersionInfo returns the cadvisor and kernel versions. Stalker's own build
+// information is filled in by the caller.
+func (cs *cadvisorService) GetVersionInfo(ctx context.Context) (models.VersionInfo, error) {
+	versionInfo, err := cs.cadvisorInterface.VersionInfo()
+	if err != nil {
+		return models.VersionInfo{}, err
+	}
+
+	return models.VersionInfo{
+		GoVersion:          runtime.Version(),
+		KernelVersion:      stringPtr(versionInfo.KernelVersion),
+		ContainerOsVersion: stringPtr(versionInfo.ContainerOsVersion),
+		CadvisorVersion:    stringPtr(versionInfo.CadvisorVersion),
+		CadvisorRevision:   stringPtr(versionInfo.CadvisorRevision),
+	}, nil
+}
+
+func (cs *cadvisorService) GetRootFsInfo(ctx context.Context) (models.FsInfo, error) {
+	fsInfo, err := cs.cadvisorInterface.RootFsInfo()
+	if err != nil {
+		return models.FsInfo{}, err
+	}
+
+	return fsInfoToModel(fsInfo), nil
+}
+
+func (cs *cadvisorService) GetImagesFsInfo(ctx context.Context) (models.FsInfoList, error) {
+	fsInfos, err := cs.cadvisorInterface.ImagesFsInfo()
+	if err != nil {
+		return models.FsInfoList{}, err
+	}
+
+	filesystems := []models.FsInfo{}
+	for _, fsInfo := range fsInfos {
+		filesystems = append(filesystems, fsInfoToModel(fsInfo))
+	}
+
+	return models.FsInfoList{
+		Filesystems: &filesystems,
+	}, nil
+}
+
+func (cs *cadvisorService) imagesFsPoints() ([]*write.Point, error) {
+	fsInfos, err := cs.cadvisorInterface.ImagesFsInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	points := []*write.Point{}
+	for _, fsInfo := range fsInfos {
+		points = append(points, cs.pointConverter.ImageFsInfoToPoints(fsInfo, fsInfo.Timestamp)...)
+	}
+
+	return points, nil
+}
+
+func fsInfoToModel(fsInfo cadvisorapiv2.FsInfo) models.FsInfo {
+	timestamp := fsInfo.Timestamp
+	labels := append([]string{}, fsInfo.Labels...)
+	result := models.FsInfo{
+		Timestamp:  &timestamp,
+		Device:     fsInfo.Device,
+		Mountpoint: fsInfo.Mountpoint,
+		Capacity:   int64(fsInfo.Capacity),
+		Available:  int64(fsInfo.Available),
+		Usage:      int64(fsInfo.Usage),
+		Labels:     &labels,
+	}
+	if fsInfo.Inodes != nil {
+		inodes := int64(*fsInfo.Inodes)
+		result.Inodes = &inodes
+	}
+	if fsInfo.InodesFree != nil {
+		inodesFree := int64(*fsInfo.InodesFree)
+		result.InodesFree = &inodesFree
+	}
+	return result
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// GetCardinality reports the limit containers producing the most series.
+func (cs *cadvisorService) GetCardinality(ctx context.Context, limit int) (models.CardinalityReport, error) {
+	report := cs.series.Top(limit)
+
+	containers := []models.ContainerCardinality{}
+	for _, c := range report.Containers {
+		tags := []models.TagCardinality{}
+		for _, t := range c.Tags {
+			tags = append(tags, models.TagCardinality{
+				Name:   t.Name,
+				Values: t.Values,
+			})
+		}
+		containers = append(containers, models.ContainerCardinality{
+			Cgroup:        c.Cgroup,
+			ContainerName: stringPtr(c.ContainerName),
+			Series:        c.Series,
+			DroppedPoints: c.DroppedPoints,
+			OverBudget:    c.OverBudget,
+			Tags:          &tags,
+		})
+	}
+
+	return models.CardinalityReport{
+		SeriesBudget: report.SeriesBudget,
+		Containers:   containers,
+	}, nil
+}
+
+// GetAlerts lists the alerts of the alerting rules, only those in state
+// unless it is empty.
+func (cs *cadvisorService) GetAlerts(ctx context.Context, state string) (models.AlertList, error) {
+	alerts := []models.Alert{}
+	for _, alert := range cs.alerts.Alerts() {
+		if state != "" && alert.State != state {
+			continue
+		}
+
+		a := models.Alert{
+			Rule:     alert.Rule,
+			Expr:     alert.Expr,
+			State:    models.AlertState(alert.State),
+			Labels:   models.Alert_Labels{AdditionalProperties: alert.Labels},
+			Value:    alert.Value,
+			ActiveAt: alert.ActiveAt,
+		}
+		if len(alert.Annotations) > 0 {
+			a.Annotations = &models.Alert_Annotations{AdditionalProperties: alert.Annotations}
+		}
+		if !alert.FiredAt.IsZero() {
+			firedAt := alert.FiredAt
+			a.FiredAt = &firedAt
+		}
+		if !alert.ResolvedAt.IsZero() {
+			resolvedAt := alert.ResolvedAt
+			a.ResolvedAt = &resolvedAt
+		}
+		alerts = append(alerts, a)
+	}
+
+	return models.AlertList{Alerts: alerts}, nil
 }
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/internal/repositories"
+)
+
+// storedSeries is a repository serving one stored series, its other methods
+// are not implemented.
+type storedSeries struct {
+	repositories.CAdvisorRepository
+	posted int
+}
+
+func (r *storedSeries) PostPoints(ctx context.Context, points []*write.Point) error {
+	r.posted += len(points)
+	return nil
+}
+
+func (r *storedSeries) GetSeries(ctx context.Context) (models.SeriesList, error) {
+	return models.SeriesList{Series: []models.Series{testSeries("memory_usage", "/web", 1)}}, nil
+}
+
+func (r *storedSeries) GetMetricsList(ctx context.Context) (models.MetricsList, error) {
+	return models.MetricsList{}, nil
+}
+
+// pointSinkFunc forwards the points of a scrape to a function.
+type pointSinkFunc func([]*write.Point)
+
+func (f pointSinkFunc) WritePoints(ctx context.Context, points []*write.Point) error {
+	f(points)
+	return nil
+}
+
+func TestReadsDoNotScrape(t *testing.T) {
+	repo := &storedSeries{}
+	forwarded := 0
+	// Without a cadvisor interface a scrape panics.
+	cs := &cadvisorService{
+		cadvisorRepository: repo,
+		sinks:              []PointSink{pointSinkFunc(func(points []*write.Point) { forwarded++ })},
+	}
+
+	for i := 0; i < 3; i++ {
+		list, err := cs.GetSeries(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Series) != 1 {
+			t.Errorf("got %d series, want the stored one", len(list.Series))
+		}
+		_, err = cs.GetMetricsList(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if repo.posted != 0 || forwarded != 0 {
+		t.Errorf("reads stored %d points and forwarded %d scrapes, want none", repo.posted, forwarded)
+	}
+}
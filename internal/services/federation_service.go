@@ -0,0 +1,391 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/pkg/client"
+)
+
+// TagNode tags the series merged by an aggregator with the agent they were
+// queried from.
+const TagNode = "node"
+
+// DefaultAgentTimeout bounds the queries of an aggregator to an agent.
+const DefaultAgentTimeout = 10 * time.Second
+
+// Values series are ranked by.
+const (
+	TopByLast = "last"
+	TopByMean = "mean"
+	TopByMax  = "max"
+	TopByRate = "rate"
+)
+
+// ErrNoAgentAnswered is returned by federated queries when every agent
+// failed.
+var ErrNoAgentAnswered = errors.New("no agent answered")
+
+// Agent is a stalker queried by an aggregator.
+type Agent struct {
+	// Node tags the series of the agent.
+	Node string
+	// Address is the unix socket, host:port or http(s) url of the agent, as
+	// dialed by stalkerctl.
+	Address string
+}
+
+// ParseAgent parses an agent given as [node=]address, its node defaults to
+// the address.
+func ParseAgent(agent string) Agent {
+	i := strings.Index(agent, "=")
+	if i < 0 {
+		return Agent{Node: agent, Address: agent}
+	}
+	return Agent{Node: agent[:i], Address: agent[i+1:]}
+}
+
+// FederationService queries the agents of an aggregator in parallel and
+// merges their answers. Agents that fail are reported as node errors of the
+// merged answer, ErrNoAgentAnswered is returned when all of them fail.
+type FederationService interface {
+	// GetSeries returns the series of every agent tagged with its node,
+	// ordered by measurement, field, node and tags.
+	GetSeries(ctx context.Context, params client.GetV2MetricsListParams) (models.SeriesList, error)
+	// GetTop returns the limit series of field ranking highest by one of the
+	// TopBy values.
+	GetTop(ctx context.Context, params client.GetV2MetricsListParams, field string, limit int, by string) (models.TopList, error)
+	// GetSum sums the series of field of every agent, see RollupPods, grouped
+	// by the values of the groupBy tags.
+	GetSum(ctx context.Context, params client.GetV2MetricsListParams, field string, groupBy []string, step time.Duration) (models.SeriesList, error)
+}
+
+type FederationServiceParams struct {
+	Agents []Agent
+	// Token is the --api-token of the agents, sent as a bearer token.
+	Token string
+	// Timeout bounds the queries to an agent, DefaultAgentTimeout when zero.
+	Timeout time.Duration
+}
+
+// NewFederationService creates a federation service of the agents.
+func NewFederationService(params FederationServiceParams) (FederationService, error) {
+	if len(params.Agents) == 0 {
+		return nil, errors.New("an aggregator needs at least one agent")
+	}
+
+	opts := []client.ClientOption{}
+	if params.Token != "" {
+		opts = append(opts, client.WithBearerToken(params.Token))
+	}
+
+	nodes := map[string]bool{}
+	agents := make([]federatedAgent, 0, len(params.Agents))
+	for _, agent := range params.Agents {
+		if nodes[agent.Node] {
+			return nil, fmt.Errorf("node %q is given to more than one agent", agent.Node)
+		}
+		nodes[agent.Node] = true
+
+		c, err := client.Dial(agent.Address, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client of agent %s: %w", agent.Node, err)
+		}
+		agents = append(agents, federatedAgent{node: agent.Node, client: c})
+	}
+
+	timeout := params.Timeout
+	if timeout <= 0 {
+		timeout = DefaultAgentTimeout
+	}
+
+	return &federationService{
+		agents:  agents,
+		timeout: timeout,
+	}, nil
+}
+
+type federationService struct {
+	agents  []federatedAgent
+	timeout time.Duration
+}
+
+type federatedAgent struct {
+	node   string
+	client *client.ClientWithResponses
+}
+
+// series queries the series of the agent as json.
+func (a federatedAgent) series(ctx context.Context, params client.GetV2MetricsListParams) (models.SeriesList, error) {
+	format := client.GetV2MetricsListParamsFormat("json")
+	params.Format = &format
+
+	resp, err := a.client.GetV2MetricsList(ctx, &params)
+	if err != nil {
+		return models.SeriesList{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return models.SeriesList{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return models.SeriesList{}, client.CheckResponse(resp.Status, body, false)
+	}
+
+	list := models.SeriesList{}
+	err = json.Unmarshal(body, &list)
+	if err != nil {
+		return models.SeriesList{}, fmt.Errorf("failed to decode series: %w", err)
+	}
+	return list, nil
+}
+
+// query fans the query out to every agent and merges the series, tagged with
+// their node, in the order of the agents.
+func (fs *federationService) query(ctx context.Context, params client.GetV2MetricsListParams) ([]models.Series, []models.NodeError, error) {
+	type answer struct {
+		list models.SeriesList
+		err  error
+	}
+
+	answers := make([]answer, len(fs.agents))
+	var wg sync.WaitGroup
+	for i, agent := range fs.agents {
+		wg.Add(1)
+		go func(i int, agent federatedAgent) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, fs.timeout)
+			defer cancel()
+			answers[i].list, answers[i].err = agent.series(ctx, params)
+		}(i, agent)
+	}
+	wg.Wait()
+
+	series := []models.Series{}
+	nodeErrors := []models.NodeError{}
+	for i, answer := range answers {
+		node := fs.agents[i].node
+		if answer.err != nil {
+			nodeErrors = append(nodeErrors, models.NodeError{
+				Node:  node,
+				Error: answer.err.Error(),
+			})
+			continue
+		}
+
+		for _, s := range answer.list.Series {
+			tags := map[string]string{}
+			for k, v := range s.Tags.AdditionalProperties {
+				tags[k] = v
+			}
+			tags[TagNode] = node
+			s.Tags.AdditionalProperties = tags
+			series = append(series, s)
+		}
+	}
+
+	if len(nodeErrors) == len(fs.agents) {
+		messages := make([]string, 0, len(nodeErrors))
+		for _, nodeError := range nodeErrors {
+			messages = append(messages, nodeError.Node+": "+nodeError.Error)
+		}
+		return nil, nil, fmt.Errorf("%w: %s", ErrNoAgentAnswered, strings.Join(messages, "; "))
+	}
+	return series, nodeErrors, nil
+}
+
+func (fs *federationService) GetSeries(ctx context.Context, params client.GetV2MetricsListParams) (models.SeriesList, error) {
+	series, nodeErrors, err := fs.query(ctx, params)
+	if err != nil {
+		return models.SeriesList{}, err
+	}
+
+	sort.SliceStable(series, func(i, j int) bool {
+		a, b := series[i], series[j]
+		if a.Measurement != b.Measurement {
+			return a.Measurement < b.Measurement
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		if a.Tags.AdditionalProperties[TagNode] != b.Tags.AdditionalProperties[TagNode] {
+			return a.Tags.AdditionalProperties[TagNode] < b.Tags.AdditionalProperties[TagNode]
+		}
+		return tagsKey(a.Tags.AdditionalProperties) < tagsKey(b.Tags.AdditionalProperties)
+	})
+
+	return models.SeriesList{
+		Series: series,
+		Errors: nodeErrorList(nodeErrors),
+	}, nil
+}
+
+func (fs *federationService) GetTop(ctx context.Context, params client.GetV2MetricsListParams, field string, limit int, by string) (models.TopList, error) {
+	rank, ok := rankings[by]
+	if !ok {
+		return models.TopList{}, fmt.Errorf("unknown ranking %q", by)
+	}
+
+	series, nodeErrors, err := fs.query(ctx, params)
+	if err != nil {
+		return models.TopList{}, err
+	}
+
+	entries := []models.TopEntry{}
+	for _, s := range series {
+		if s.Field != field {
+			continue
+		}
+		value, ok := rank(s.Points)
+		if !ok {
+			continue
+		}
+
+		tags := s.Tags.AdditionalProperties
+		node := tags[TagNode]
+		delete(tags, TagNode)
+		entries = append(entries, models.TopEntry{
+			Node:        node,
+			Measurement: s.Measurement,
+			Field:       s.Field,
+			Tags:        models.TopEntry_Tags{AdditionalProperties: tags},
+			Value:       value,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		if a.Node != b.Node {
+			return a.Node < b.Node
+		}
+		return tagsKey(a.Tags.AdditionalProperties) < tagsKey(b.Tags.AdditionalProperties)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return models.TopList{
+		Entries: entries,
+		Errors:  nodeErrorList(nodeErrors),
+	}, nil
+}
+
+func (fs *federationService) GetSum(ctx context.Context, params client.GetV2MetricsListParams, field string, groupBy []string, step time.Duration) (models.SeriesList, error) {
+	series, nodeErrors, err := fs.query(ctx, params)
+	if err != nil {
+		return models.SeriesList{}, err
+	}
+
+	selected := []models.Series{}
+	for _, s := range series {
+		if s.Field == field {
+			selected = append(selected, s)
+		}
+	}
+	summed := sumSeries(models.SeriesList{Series: selected}, step, groupBy, nil)
+	sort.SliceStable(summed.Series, func(i, j int) bool {
+		a, b := summed.Series[i], summed.Series[j]
+		if a.Measurement != b.Measurement {
+			return a.Measurement < b.Measurement
+		}
+		return tagsKey(a.Tags.AdditionalProperties) < tagsKey(b.Tags.AdditionalProperties)
+	})
+
+	return models.SeriesList{
+		Series: summed.Series,
+		Errors: nodeErrorList(nodeErrors),
+	}, nil
+}
+
+// rankings reduce the points of a series to the value it is ranked by,
+// reporting false for series without one.
+var rankings = map[string]func(points []models.Point) (float64, bool){
+	TopByLast: func(points []models.Point) (float64, bool) {
+		if len(points) == 0 {
+			return 0, false
+		}
+		return points[len(points)-1].Value, true
+	},
+	TopByMean: func(points []models.Point) (float64, bool) {
+		if len(points) == 0 {
+			return 0, false
+		}
+		sum := 0.0
+		for _, point := range points {
+			sum += point.Value
+		}
+		return sum / float64(len(points)), true
+	},
+	TopByMax: func(points []models.Point) (float64, bool) {
+		if len(points) == 0 {
+			return 0, false
+		}
+		max := points[0].Value
+		for _, point := range points[1:] {
+			if point.Value > max {
+				max = point.Value
+			}
+		}
+		return max, true
+	},
+	// The per second increase of a counter, which restarts from zero when it
+	// is reset.
+	TopByRate: func(points []models.Point) (float64, bool) {
+		if len(points) < 2 {
+			return 0, false
+		}
+		elapsed := points[len(points)-1].Timestamp.Sub(points[0].Timestamp).Seconds()
+		if elapsed <= 0 {
+			return 0, false
+		}
+		increase := 0.0
+		for i := 1; i < len(points); i++ {
+			delta := points[i].Value - points[i-1].Value
+			if delta < 0 {
+				delta = points[i].Value
+			}
+			increase += delta
+		}
+		return increase / elapsed, true
+	},
+}
+
+// tagsKey orders series with the same measurement, field and node.
+func tagsKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(tags[k])
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+func nodeErrorList(nodeErrors []models.NodeError) *[]models.NodeError {
+	if len(nodeErrors) == 0 {
+		return nil
+	}
+	return &nodeErrors
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/pkg/client"
+)
+
+var testStart = time.Unix(1600000000, 0).UTC()
+
+func testSeries(measurement, container string, values ...float64) models.Series {
+	points := []models.Point{}
+	for i, value := range values {
+		points = append(points, models.Point{Timestamp: testStart.Add(time.Duration(i) * 10 * time.Second), Value: value})
+	}
+	return models.Series{
+		Measurement: measurement,
+		Field:       "value",
+		Tags:        models.Series_Tags{AdditionalProperties: map[string]string{"container_name": container}},
+		Points:      points,
+	}
+}
+
+// startAgent serves the series of an in-process agent on /v2/metricsList.
+func startAgent(t *testing.T, series ...models.Series) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/metricsList" || r.URL.Query().Get("format") != "json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SeriesList{Series: series})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// failingAgent answers every query with 500.
+func failingAgent(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("database is down"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestFederation(t *testing.T, agents ...string) FederationService {
+	t.Helper()
+	parsed := []Agent{}
+	for _, agent := range agents {
+		parsed = append(parsed, ParseAgent(agent))
+	}
+	fs, err := NewFederationService(FederationServiceParams{
+		Agents:  parsed,
+		Token:   "secret",
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func testQuery() client.GetV2MetricsListParams {
+	start, end := int(testStart.Unix()), int(testStart.Add(time.Minute).Unix())
+	return client.GetV2MetricsListParams{StartTime: &start, EndTime: &end}
+}
+
+func TestFederationGetSeries(t *testing.T) {
+	b := startAgent(t,
+		testSeries("memory_usage", "/web", 5),
+		testSeries("cpu_usage_total", "/web", 1),
+	)
+	a := startAgent(t,
+		testSeries("memory_usage", "/db", 7),
+		testSeries("memory_usage", "/api", 3),
+	)
+	failed := failingAgent(t)
+	fs := newTestFederation(t, "b="+b.URL, "a="+a.URL, "c="+failed.URL)
+
+	list, err := fs.GetSeries(context.Background(), testQuery())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, s := range list.Series {
+		tags := s.Tags.AdditionalProperties
+		got = append(got, s.Measurement+" "+tags[TagNode]+" "+tags["container_name"])
+	}
+	want := []string{
+		"cpu_usage_total b /web",
+		"memory_usage a /api",
+		"memory_usage a /db",
+		"memory_usage b /web",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got series %v, want %v", got, want)
+	}
+
+	if list.Errors == nil || len(*list.Errors) != 1 {
+		t.Fatalf("got errors %v, want the error of node c", list.Errors)
+	}
+	nodeError := (*list.Errors)[0]
+	if nodeError.Node != "c" || !strings.Contains(nodeError.Error, "database is down") {
+		t.Errorf("got error %+v, want the error of node c", nodeError)
+	}
+}
+
+func TestFederationNoAgentAnswered(t *testing.T) {
+	fs := newTestFederation(t, "a="+failingAgent(t).URL, "b="+failingAgent(t).URL)
+
+	_, err := fs.GetSeries(context.Background(), testQuery())
+	if !errors.Is(err, ErrNoAgentAnswered) {
+		t.Errorf("got %v, want ErrNoAgentAnswered", err)
+	}
+	_, err = fs.GetTop(context.Background(), testQuery(), "value", 10, TopByLast)
+	if !errors.Is(err, ErrNoAgentAnswered) {
+		t.Errorf("got %v, want ErrNoAgentAnswered", err)
+	}
+	_, err = fs.GetSum(context.Background(), testQuery(), "value", nil, DefaultRollupStep)
+	if !errors.Is(err, ErrNoAgentAnswered) {
+		t.Errorf("got %v, want ErrNoAgentAnswered", err)
+	}
+}
+
+func TestFederationGetTop(t *testing.T) {
+	a := startAgent(t,
+		testSeries("cpu_usage_total", "/web", 10, 20, 30),
+		// Reset after 60, increasing by 40 in 20s.
+		testSeries("cpu_usage_total", "/db", 40, 60, 20),
+	)
+	b := startAgent(t,
+		testSeries("cpu_usage_total", "/api", 100, 100, 100),
+	)
+	fs := newTestFederation(t, "a="+a.URL, "b="+b.URL)
+
+	tests := []struct {
+		by    string
+		limit int
+		want  []string
+	}{
+		{by: TopByLast, limit: 10, want: []string{"b /api 100", "a /web 30", "a /db 20"}},
+		{by: TopByMean, limit: 2, want: []string{"b /api 100", "a /db 40"}},
+		{by: TopByMax, limit: 1, want: []string{"b /api 100"}},
+		{by: TopByRate, limit: 10, want: []string{"a /db 2", "a /web 1", "b /api 0"}},
+	}
+	for _, tt := range tests {
+		top, err := fs.GetTop(context.Background(), testQuery(), "value", tt.limit, tt.by)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := []string{}
+		for _, entry := range top.Entries {
+			value, _ := json.Marshal(entry.Value)
+			got = append(got, entry.Node+" "+entry.Tags.AdditionalProperties["container_name"]+" "+string(value))
+			if _, ok := entry.Tags.AdditionalProperties[TagNode]; ok {
+				t.Errorf("%s: entry %+v repeats its node as a tag", tt.by, entry)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.by, got, tt.want)
+		}
+	}
+}
+
+func TestFederationGetSum(t *testing.T) {
+	a := startAgent(t,
+		testSeries("memory_usage", "/web", 1, 2),
+		testSeries("memory_usage", "/db", 10, 20),
+	)
+	b := startAgent(t,
+		testSeries("memory_usage", "/web", 100, 200),
+	)
+	fs := newTestFederation(t, "a="+a.URL, "b="+b.URL)
+
+	tests := []struct {
+		groupBy []string
+		want    []string
+	}{
+		{groupBy: nil, want: []string{"=[111 222]"}},
+		{groupBy: []string{TagNode}, want: []string{"a=[11 22]", "b=[100 200]"}},
+		{groupBy: []string{"container_name"}, want: []string{"/db=[10 20]", "/web=[101 202]"}},
+	}
+	for _, tt := range tests {
+		sum, err := fs.GetSum(context.Background(), testQuery(), "value", tt.groupBy, 10*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := []string{}
+		for _, s := range sum.Series {
+			group := ""
+			for _, tag := range tt.groupBy {
+				group += s.Tags.AdditionalProperties[tag]
+			}
+			values := []float64{}
+			for _, point := range s.Points {
+				values = append(values, point.Value)
+			}
+			encoded, _ := json.Marshal(values)
+			got = append(got, group+"="+strings.ReplaceAll(string(encoded), ",", " "))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("group by %v: got %v, want %v", tt.groupBy, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/pkg/push"
+)
+
+// PushService stores the batches of points agents push to a central stalker.
+type PushService interface {
+	// Authenticate returns the agent whose bearer token is token.
+	Authenticate(token string) (push.AgentConfig, bool)
+	// Push stores a batch of points of the agent tagged with its node.
+	// Batches accepted before are reported as duplicates and not stored
+	// again, push.ErrPending is returned for batches another push of the
+	// agent is storing and a *push.QuotaError when the agent exceeded its
+	// quota.
+	Push(ctx context.Context, agent push.AgentConfig, batch string, points []*write.Point) (models.PushResult, error)
+}
+
+// PointStore stores points, such as the CAdvisorService.
+type PointStore interface {
+	PostPoints(context.Context, []*write.Point) error
+}
+
+type PushServiceParams struct {
+	Agents *push.AgentsFile
+	Points PointStore
+}
+
+// NewPushService creates a push service.
+func NewPushService(params PushServiceParams) PushService {
+	return &pushService{
+		agents:   params.Agents,
+		points:   params.Points,
+		receiver: push.NewReceiver(),
+	}
+}
+
+type pushService struct {
+	agents   *push.AgentsFile
+	points   PointStore
+	receiver *push.Receiver
+}
+
+func (ps *pushService) Authenticate(token string) (push.AgentConfig, bool) {
+	return ps.agents.Authenticate(token)
+}
+
+func (ps *pushService) Push(ctx context.Context, agent push.AgentConfig, batch string, points []*write.Point) (models.PushResult, error) {
+	err := ps.receiver.Admit(agent, batch, len(points))
+	if err == push.ErrDuplicate {
+		return models.PushResult{Duplicate: true}, nil
+	}
+	if err != nil {
+		return models.PushResult{}, err
+	}
+
+	for _, point := range points {
+		point.AddTag(TagNode, agent.Name)
+	}
+	err = ps.points.PostPoints(ctx, points)
+	if err != nil {
+		ps.receiver.Release(agent.Name, batch, len(points))
+		return models.PushResult{}, err
+	}
+	ps.receiver.Accepted(agent.Name, batch)
+
+	return models.PushResult{Points: int64(len(points))}, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/push"
+)
+
+// blockingStore stores points once unblock is closed, failing while fail is
+// set. Every push entering PostPoints is sent to entered.
+type blockingStore struct {
+	entered chan struct{}
+	unblock chan struct{}
+
+	mu     sync.Mutex
+	fail   bool
+	stored int
+}
+
+func (s *blockingStore) PostPoints(ctx context.Context, points []*write.Point) error {
+	s.entered <- struct{}{}
+	<-s.unblock
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("storage is down")
+	}
+	s.stored += len(points)
+	return nil
+}
+
+func TestPushStoresBatchesOnce(t *testing.T) {
+	store := &blockingStore{entered: make(chan struct{}, 4), unblock: make(chan struct{}), fail: true}
+	ps := &pushService{points: store, receiver: push.NewReceiver()}
+	agent := push.AgentConfig{Name: "node-1"}
+	points := func() []*write.Point {
+		return []*write.Point{write.NewPoint("memory_usage", nil, map[string]interface{}{"value": 1.0}, testStart)}
+	}
+
+	// A push of a batch being stored is refused until the first is done.
+	first := make(chan error)
+	go func() {
+		_, err := ps.Push(context.Background(), agent, "a", points())
+		first <- err
+	}()
+	<-store.entered
+	if _, err := ps.Push(context.Background(), agent, "a", points()); err != push.ErrPending {
+		t.Fatalf("got %v while the batch is being stored, want ErrPending", err)
+	}
+	close(store.unblock)
+	if err := <-first; err == nil {
+		t.Fatal("stored the batch while the storage is down")
+	}
+
+	// A batch that failed to be stored is stored when retried, then reported
+	// as a duplicate.
+	store.mu.Lock()
+	store.fail = false
+	store.mu.Unlock()
+	for i, want := range []bool{false, true, true} {
+		result, err := ps.Push(context.Background(), agent, "a", points())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Duplicate != want {
+			t.Errorf("push %d: got duplicate %v, want %v", i, result.Duplicate, want)
+		}
+	}
+	if store.stored != 1 {
+		t.Errorf("stored %d points, want the batch once", store.stored)
+	}
+}
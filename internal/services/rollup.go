@@ -0,0 +1,164 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+// DefaultRollupStep is the window of pod rollups, the default housekeeping
+// interval of cadvisor.
+const DefaultRollupStep = 10 * time.Second
+
+// rollupTags are the tags of the pod kept by pod rollups, along with the
+// tags telling apart the series of a container.
+var rollupTags = []string{influx.TagMachineName, influx.TagNamespace, influx.TagPod, influx.TagQOSClass}
+
+// podTags identify the pod of a series, on an agent or, with TagNode, in a
+// federation.
+var podTags = []string{TagNode, influx.TagMachineName, influx.TagNamespace, influx.TagPod}
+
+// RollupPods sums the series of the containers of every pod into a series
+// per pod, measurement, field and series tags, see influx.IsSeriesTag. Series
+// of cgroups outside of pods and limits, which unlimited containers leave
+// out, are left out.
+func RollupPods(list models.SeriesList, step time.Duration) models.SeriesList {
+	selected := []models.Series{}
+	for _, series := range list.Series {
+		if !influx.IsLimit(series.Measurement) {
+			selected = append(selected, series)
+		}
+	}
+	list.Series = selected
+
+	return sumSeries(list, step, rollupTags, func(tags map[string]string) bool {
+		return tags[influx.TagPod] != ""
+	})
+}
+
+// sumSeries sums the series selected by keep into a series per measurement,
+// field, values of tags and series tags, every series selected when keep is
+// nil. Series are sampled at different times, so the last sample of every
+// series in a window of step is summed and stamped with the start of the
+// window. The containers of a pod share the series of its network, which are
+// counted once per pod. Steps that are not positive fall back to
+// DefaultRollupStep.
+func sumSeries(list models.SeriesList, step time.Duration, tags []string, keep func(tags map[string]string) bool) models.SeriesList {
+	if step <= 0 {
+		step = DefaultRollupStep
+	}
+
+	type sum struct {
+		series models.Series
+		sums   map[int64]float64
+		// pods holds the last samples of the windows of every pod of a pod
+		// shared series, added to sums once all series are read.
+		pods map[string]map[int64]float64
+	}
+
+	sums := []*sum{}
+	index := map[string]*sum{}
+	for _, series := range list.Series {
+		seriesTags := series.Tags.AdditionalProperties
+		if keep != nil && !keep(seriesTags) {
+			continue
+		}
+
+		kept := map[string]string{}
+		fields := []string{series.Measurement, series.Field}
+		for _, tag := range tags {
+			if value, ok := seriesTags[tag]; ok {
+				kept[tag] = value
+			}
+			fields = append(fields, seriesTags[tag])
+		}
+		distinct := []string{}
+		for tag := range seriesTags {
+			if influx.IsSeriesTag(tag) {
+				distinct = append(distinct, tag)
+			}
+		}
+		sort.Strings(distinct)
+		for _, tag := range distinct {
+			kept[tag] = seriesTags[tag]
+			fields = append(fields, tag+"="+seriesTags[tag])
+		}
+		key := strings.Join(fields, "\xff")
+
+		s, ok := index[key]
+		if !ok {
+			s = &sum{
+				series: models.Series{
+					Measurement: series.Measurement,
+					Field:       series.Field,
+					Tags:        models.Series_Tags{AdditionalProperties: kept},
+				},
+				sums: map[int64]float64{},
+				pods: map[string]map[int64]float64{},
+			}
+			index[key] = s
+			sums = append(sums, s)
+		}
+
+		// Points are oldest first, the last one of a window wins.
+		last := map[int64]float64{}
+		for _, point := range series.Points {
+			window := point.Timestamp.UnixNano()
+			window -= window % int64(step)
+			last[window] = point.Value
+		}
+		// The containers of a pod report the same samples, the last one
+		// read wins.
+		if seriesTags[influx.TagPod] != "" && influx.IsPodShared(series.Measurement) {
+			pod := []string{}
+			for _, tag := range podTags {
+				pod = append(pod, seriesTags[tag])
+			}
+			podKey := strings.Join(pod, "\xff")
+			if _, ok := s.pods[podKey]; !ok {
+				s.pods[podKey] = map[int64]float64{}
+			}
+			for window, value := range last {
+				s.pods[podKey][window] = value
+			}
+			continue
+		}
+		for window, value := range last {
+			s.sums[window] += value
+		}
+	}
+	for _, s := range sums {
+		for _, last := range s.pods {
+			for window, value := range last {
+				s.sums[window] += value
+			}
+		}
+	}
+
+	summed := models.SeriesList{
+		Series: []models.Series{},
+	}
+	for _, s := range sums {
+		windows := make([]int64, 0, len(s.sums))
+		for window := range s.sums {
+			windows = append(windows, window)
+		}
+		sort.Slice(windows, func(i, j int) bool {
+			return windows[i] < windows[j]
+		})
+
+		s.series.Points = make([]models.Point, 0, len(windows))
+		for _, window := range windows {
+			s.series.Points = append(s.series.Points, models.Point{
+				Timestamp: time.Unix(0, window).UTC(),
+				Value:     s.sums[window],
+			})
+		}
+		summed.Series = append(summed.Series, s.series)
+	}
+
+	return summed
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+func TestRollupPods(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	container := func(measurement, name string, tags map[string]string, values ...float64) models.Series {
+		points := []models.Point{}
+		for i, value := range values {
+			points = append(points, models.Point{Timestamp: start.Add(time.Duration(i) * 5 * time.Second), Value: value})
+		}
+		seriesTags := map[string]string{
+			influx.TagContainerName: name,
+			influx.TagNamespace:     "default",
+			influx.TagPod:           "web",
+			// Labels differ between the containers of a pod.
+			"io.kubernetes.container.name": strings.TrimPrefix(name, "/kubepods/web/"),
+		}
+		for k, v := range tags {
+			seriesTags[k] = v
+		}
+		return models.Series{
+			Measurement: measurement,
+			Field:       "value",
+			Tags:        models.Series_Tags{AdditionalProperties: seriesTags},
+			Points:      points,
+		}
+	}
+	failure := func(failureType, scope string) map[string]string {
+		return map[string]string{"failure_type": failureType, "scope": scope}
+	}
+	list := models.SeriesList{Series: []models.Series{
+		container("memory_usage", "/kubepods/web/a", nil, 1, 2, 3, 4),
+		container("memory_usage", "/kubepods/web/b", nil, 10, 20, 30, 40),
+		{Measurement: "memory_usage", Field: "value", Points: []models.Point{{Timestamp: start, Value: 100}}},
+		container("memory_failure", "/kubepods/web/a", failure("pgfault", "container"), 1, 2, 3, 4),
+		container("memory_failure", "/kubepods/web/a", failure("pgmajfault", "container"), 0, 0, 0, 1),
+		container("memory_failure", "/kubepods/web/a", failure("pgfault", "hierarchical"), 5, 6, 7, 8),
+		container("memory_failure", "/kubepods/web/b", failure("pgfault", "container"), 10, 20, 30, 40),
+		container("cpu_usage_per_cpu", "/kubepods/web/a", map[string]string{"instance": "0"}, 1, 2, 3, 4),
+		container("cpu_usage_per_cpu", "/kubepods/web/a", map[string]string{"instance": "1"}, 5, 6, 7, 8),
+		container("cpu_usage_per_cpu", "/kubepods/web/b", map[string]string{"instance": "0"}, 10, 20, 30, 40),
+		// Both containers report the network of the pod.
+		container("rx_bytes", "/kubepods/web/a", nil, 100, 200, 300, 400),
+		container("rx_bytes", "/kubepods/web/b", nil, 100, 200, 300, 400),
+		container("memory_limit", "/kubepods/web/a", nil, 1<<30, 1<<30, 1<<30, 1<<30),
+	}}
+
+	perStep := map[string]string{
+		"cpu_usage_per_cpu instance=0":                           "[22 44]",
+		"cpu_usage_per_cpu instance=1":                           "[6 8]",
+		"memory_failure failure_type=pgfault,scope=container":    "[22 44]",
+		"memory_failure failure_type=pgfault,scope=hierarchical": "[6 8]",
+		"memory_failure failure_type=pgmajfault,scope=container": "[0 1]",
+		"memory_usage ": "[22 44]",
+		"rx_bytes ":     "[200 400]",
+	}
+	tests := []struct {
+		step time.Duration
+		want map[string]string
+	}{
+		{step: 10 * time.Second, want: perStep},
+		// Steps that are not positive fall back to the default step.
+		{step: 0, want: perStep},
+		{step: -time.Second, want: perStep},
+		{step: time.Minute, want: map[string]string{
+			"cpu_usage_per_cpu instance=0":                           "[44]",
+			"cpu_usage_per_cpu instance=1":                           "[8]",
+			"memory_failure failure_type=pgfault,scope=container":    "[44]",
+			"memory_failure failure_type=pgfault,scope=hierarchical": "[8]",
+			"memory_failure failure_type=pgmajfault,scope=container": "[1]",
+			"memory_usage ": "[44]",
+			"rx_bytes ":     "[400]",
+		}},
+	}
+
+	for _, tt := range tests {
+		rolled := RollupPods(list, tt.step)
+		got := map[string]string{}
+		for _, series := range rolled.Series {
+			seriesTags := series.Tags.AdditionalProperties
+			if seriesTags[influx.TagPod] != "web" || seriesTags[influx.TagNamespace] != "default" {
+				t.Errorf("step %s: %s tags %v", tt.step, series.Measurement, seriesTags)
+			}
+			distinct := []string{}
+			for k, v := range seriesTags {
+				if k != influx.TagPod && k != influx.TagNamespace {
+					distinct = append(distinct, k+"="+v)
+				}
+			}
+			sort.Strings(distinct)
+			values := []float64{}
+			for _, point := range series.Points {
+				values = append(values, point.Value)
+			}
+			key := series.Measurement + " " + strings.Join(distinct, ",")
+			if _, ok := got[key]; ok {
+				t.Errorf("step %s: %s rolled up twice", tt.step, key)
+			}
+			got[key] = fmt.Sprint(values)
+		}
+
+		// Maps are printed in key order.
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("step %s: got %v, want %v", tt.step, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,500 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	cadvisorapiv2 "github.com/google/cadvisor/info/v2"
+	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+// summaryLookback is the period before the time of a summary whose stored
+// samples it is built from.
+const summaryLookback = time.Minute
+
+// Measurements a summary is built from.
+var summaryMeasurements = []string{
+	"cpu_usage_total",
+	"memory_usage",
+	"memory_working_set",
+	"memory_rss",
+	"memory_limit",
+	"memory_failure",
+	"rx_bytes",
+	"rx_errors",
+	"tx_bytes",
+	"tx_errors",
+	"fs_usage",
+	"fs_base_usage",
+	"fs_inodes_usage",
+}
+
+// sandboxContainer is the container name dockershim gives pod sandboxes,
+// other runtimes leave it out.
+const sandboxContainer = "POD"
+
+// cgroupUsage is the resource usage of a cgroup at an instant, read from
+// cadvisor or from stored samples.
+type cgroupUsage struct {
+	name      string
+	pod       influx.PodMetadata
+	inPod     bool
+	startTime *time.Time
+	time      time.Time
+
+	cpuTotal     *uint64
+	cpuNanoCores *uint64
+
+	memoryUsage      *uint64
+	memoryWorkingSet *uint64
+	memoryRss        *uint64
+	memoryLimit      *uint64
+	pageFaults       *uint64
+	majorPageFaults  *uint64
+
+	interfaces []models.InterfaceStats
+
+	// fsUsage counts the logs and writable layer of a container, fsBaseUsage
+	// its writable layer only.
+	fsUsage       *uint64
+	fsBaseUsage   *uint64
+	fsInodesUsage *uint64
+}
+
+// GetSummary returns the usage of the node and its pods in the shape of the
+// kubelet Summary API, at the time or else now.
+func (cs *cadvisorService) GetSummary(ctx context.Context, at *time.Time) (models.Summary, error) {
+	machineInfo, err := cs.cadvisorInterface.MachineInfo()
+	if err != nil {
+		return models.Summary{}, err
+	}
+	nodeName, err := os.Hostname()
+	if err != nil {
+		return models.Summary{}, err
+	}
+
+	if at != nil {
+		list, err := cs.cadvisorRepository.GetSeriesInPeriod(ctx, at.Add(-summaryLookback), *at, influx.StatsFilter{
+			Measurements: summaryMeasurements,
+		})
+		if err != nil {
+			return models.Summary{}, err
+		}
+
+		return buildSummary(nodeName, seriesToCgroupUsages(list), machineInfo.MemoryCapacity, nil, nil), nil
+	}
+
+	infos, err := cs.cadvisorInterface.ContainerInfoV2("/", cadvisorapiv2.RequestOptions{
+		IdType:    cadvisorapiv2.TypeName,
+		Count:     2, // 2 samples are needed to compute "instantaneous" CPU
+		Recursive: true,
+	})
+	if err != nil {
+		return models.Summary{}, err
+	}
+
+	rootFs, err := cs.GetRootFsInfo(ctx)
+	if err != nil {
+		return models.Summary{}, err
+	}
+	var imageFs *models.FsInfo
+	imageFsList, err := cs.GetImagesFsInfo(ctx)
+	if err != nil {
+		log.Printf("failed to read image filesystem usage: %v", err)
+	} else if imageFsList.Filesystems != nil && len(*imageFsList.Filesystems) > 0 {
+		imageFs = &(*imageFsList.Filesystems)[0]
+	}
+
+	usages := []cgroupUsage{}
+	for name, info := range infos {
+		usage, ok := containerInfoToCgroupUsage(name, &info)
+		if ok {
+			usages = append(usages, usage)
+		}
+	}
+
+	return buildSummary(nodeName, usages, machineInfo.MemoryCapacity, &rootFs, imageFs), nil
+}
+
+// containerInfoToCgroupUsage reads the latest sample of a cgroup.
+func containerInfoToCgroupUsage(name string, info *cadvisorapiv2.ContainerInfo) (cgroupUsage, bool) {
+	stat, ok := latestContainerStats(info)
+	if !ok {
+		return cgroupUsage{}, false
+	}
+
+	startTime := info.Spec.CreationTime
+	usage := cgroupUsage{
+		name:      name,
+		startTime: &startTime,
+		time:      stat.Timestamp,
+	}
+	usage.pod, usage.inPod = influx.ParsePodMetadata(name, info.Spec.Labels)
+
+	if stat.Cpu != nil {
+		usage.cpuTotal = &stat.Cpu.Usage.Total
+	}
+	if stat.CpuInst != nil {
+		usage.cpuNanoCores = &stat.CpuInst.Usage.Total
+	}
+
+	if stat.Memory != nil {
+		usage.memoryUsage = &stat.Memory.Usage
+		usage.memoryWorkingSet = &stat.Memory.WorkingSet
+		usage.memoryRss = &stat.Memory.RSS
+		usage.pageFaults = &stat.Memory.ContainerData.Pgfault
+		usage.majorPageFaults = &stat.Memory.ContainerData.Pgmajfault
+	}
+	if info.Spec.HasMemory && info.Spec.Memory.Limit > 0 && info.Spec.Memory.Limit < influx.UnlimitedMemory {
+		usage.memoryLimit = &info.Spec.Memory.Limit
+	}
+
+	if stat.Network != nil {
+		for _, iface := range stat.Network.Interfaces {
+			usage.interfaces = append(usage.interfaces, models.InterfaceStats{
+				Name:     iface.Name,
+				RxBytes:  int64Ptr(iface.RxBytes),
+				RxErrors: int64Ptr(iface.RxErrors),
+				TxBytes:  int64Ptr(iface.TxBytes),
+				TxErrors: int64Ptr(iface.TxErrors),
+			})
+		}
+	}
+
+	if stat.Filesystem != nil {
+		usage.fsUsage = stat.Filesystem.TotalUsageBytes
+		usage.fsBaseUsage = stat.Filesystem.BaseUsageBytes
+		usage.fsInodesUsage = stat.Filesystem.InodeUsage
+	}
+
+	return usage, true
+}
+
+// seriesToCgroupUsages reads the latest stored sample of every cgroup from
+// its series. The root cgroup and pod containers are told apart by their
+// tags, other cgroups are left out.
+func seriesToCgroupUsages(list models.SeriesList) []cgroupUsage {
+	usages := map[string]*cgroupUsage{}
+	keys := []string{}
+	for _, series := range list.Series {
+		if len(series.Points) == 0 {
+			continue
+		}
+		tags := series.Tags.AdditionalProperties
+
+		name := tags[influx.TagContainerName]
+		pod := influx.PodMetadata{
+			Namespace: tags[influx.TagNamespace],
+			Pod:       tags[influx.TagPod],
+			Container: tags[influx.TagContainer],
+			QOSClass:  tags[influx.TagQOSClass],
+		}
+		if name != influx.RootContainerName && pod.Pod == "" {
+			continue
+		}
+
+		key := strings.Join([]string{name, pod.Namespace, pod.Pod, pod.Container}, "\xff")
+		usage, ok := usages[key]
+		if !ok {
+			usage = &cgroupUsage{
+				name:  name,
+				pod:   pod,
+				inPod: pod.Pod != "",
+			}
+			usages[key] = usage
+			keys = append(keys, key)
+		}
+
+		last := series.Points[len(series.Points)-1]
+		if last.Timestamp.After(usage.time) {
+			usage.time = last.Timestamp
+		}
+		value := uint64Ptr(last.Value)
+
+		switch series.Measurement {
+		case "cpu_usage_total":
+			usage.cpuTotal = value
+			if len(series.Points) > 1 {
+				previous := series.Points[len(series.Points)-2]
+				elapsed := last.Timestamp.Sub(previous.Timestamp).Seconds()
+				if elapsed > 0 && last.Value >= previous.Value {
+					usage.cpuNanoCores = uint64Ptr((last.Value - previous.Value) / elapsed)
+				}
+			}
+		case "memory_usage":
+			usage.memoryUsage = value
+		case "memory_working_set":
+			usage.memoryWorkingSet = value
+		case "memory_rss":
+			usage.memoryRss = value
+		case "memory_limit":
+			usage.memoryLimit = value
+		case "memory_failure":
+			if tags["scope"] != "container" {
+				continue
+			}
+			if tags["failure_type"] == "pgmajfault" {
+				usage.majorPageFaults = value
+			} else {
+				usage.pageFaults = value
+			}
+		case "rx_bytes", "rx_errors", "tx_bytes", "tx_errors":
+			// Only the first interface is stored, under no name.
+			if len(usage.interfaces) == 0 {
+				usage.interfaces = []models.InterfaceStats{{}}
+			}
+			iface := &usage.interfaces[0]
+			switch series.Measurement {
+			case "rx_bytes":
+				iface.RxBytes = int64Ptr(*value)
+			case "rx_errors":
+				iface.RxErrors = int64Ptr(*value)
+			case "tx_bytes":
+				iface.TxBytes = int64Ptr(*value)
+			case "tx_errors":
+				iface.TxErrors = int64Ptr(*value)
+			}
+		case "fs_usage":
+			usage.fsUsage = value
+		case "fs_base_usage":
+			usage.fsBaseUsage = value
+		case "fs_inodes_usage":
+			usage.fsInodesUsage = value
+		}
+	}
+
+	cgroups := make([]cgroupUsage, 0, len(keys))
+	for _, key := range keys {
+		cgroups = append(cgroups, *usages[key])
+	}
+	return cgroups
+}
+
+// buildSummary assembles the node from the root cgroup and a pod from the
+// cgroups of its containers, including the sandbox. Filesystem capacities
+// are only known for the current instant, rootFs and imageFs may be nil.
+func buildSummary(nodeName string, usages []cgroupUsage, memoryCapacity uint64, rootFs, imageFs *models.FsInfo) models.Summary {
+	summary := models.Summary{
+		Node: models.NodeStats{
+			NodeName: nodeName,
+		},
+		Pods: []models.PodStats{},
+	}
+
+	type pod struct {
+		stats  models.PodStats
+		usages []cgroupUsage
+	}
+	pods := map[string]*pod{}
+	podKeys := []string{}
+	for _, usage := range usages {
+		if usage.name == influx.RootContainerName {
+			node := &summary.Node
+			node.StartTime = usage.startTime
+			node.Cpu = cpuStats(usage)
+			node.Memory = memoryStats(usage, &memoryCapacity)
+			node.Network = networkStats(usage.time, usage.interfaces)
+			if rootFs != nil {
+				node.Fs = fsInfoStats(usage.time, rootFs, nil, nil)
+			}
+			if imageFs != nil {
+				node.Runtime = &models.RuntimeStats{
+					ImageFs: fsInfoStats(usage.time, imageFs, nil, nil),
+				}
+			}
+			continue
+		}
+		if !usage.inPod || usage.pod.Pod == "" {
+			continue
+		}
+
+		key := usage.pod.Namespace + "/" + usage.pod.Pod
+		p, ok := pods[key]
+		if !ok {
+			p = &pod{
+				stats: models.PodStats{
+					PodRef: models.PodReference{
+						Name:      usage.pod.Pod,
+						Namespace: usage.pod.Namespace,
+						Uid:       usage.pod.UID,
+					},
+					Containers: []models.ContainerStats{},
+				},
+			}
+			pods[key] = p
+			podKeys = append(podKeys, key)
+		}
+		p.usages = append(p.usages, usage)
+	}
+
+	sort.Strings(podKeys)
+	for _, key := range podKeys {
+		p := pods[key]
+		total := cgroupUsage{}
+		var fsUsed, fsInodesUsed *uint64
+		for _, usage := range p.usages {
+			if usage.startTime != nil && (p.stats.StartTime == nil || usage.startTime.Before(*p.stats.StartTime)) {
+				p.stats.StartTime = usage.startTime
+			}
+			if usage.time.After(total.time) {
+				total.time = usage.time
+			}
+			total.cpuTotal = addUint64(total.cpuTotal, usage.cpuTotal)
+			total.cpuNanoCores = addUint64(total.cpuNanoCores, usage.cpuNanoCores)
+			total.memoryUsage = addUint64(total.memoryUsage, usage.memoryUsage)
+			total.memoryWorkingSet = addUint64(total.memoryWorkingSet, usage.memoryWorkingSet)
+			total.memoryRss = addUint64(total.memoryRss, usage.memoryRss)
+			total.pageFaults = addUint64(total.pageFaults, usage.pageFaults)
+			total.majorPageFaults = addUint64(total.majorPageFaults, usage.majorPageFaults)
+			fsUsed = addUint64(fsUsed, usage.fsUsage)
+			fsInodesUsed = addUint64(fsInodesUsed, usage.fsInodesUsage)
+
+			// The containers of a pod share the network namespace of its
+			// sandbox.
+			if len(total.interfaces) == 0 {
+				total.interfaces = usage.interfaces
+			}
+
+			if usage.pod.Container == "" || usage.pod.Container == sandboxContainer {
+				continue
+			}
+			container := models.ContainerStats{
+				Name:      usage.pod.Container,
+				StartTime: usage.startTime,
+				Cpu:       cpuStats(usage),
+				Memory:    memoryStats(usage, usage.memoryLimit),
+			}
+			if usage.fsBaseUsage != nil || usage.fsInodesUsage != nil {
+				container.Rootfs = fsInfoStats(usage.time, imageFs, usage.fsBaseUsage, usage.fsInodesUsage)
+			}
+			p.stats.Containers = append(p.stats.Containers, container)
+		}
+		sort.Slice(p.stats.Containers, func(i, j int) bool {
+			return p.stats.Containers[i].Name < p.stats.Containers[j].Name
+		})
+
+		p.stats.Cpu = cpuStats(total)
+		p.stats.Memory = memoryStats(total, nil)
+		p.stats.Network = networkStats(total.time, total.interfaces)
+		if fsUsed != nil || fsInodesUsed != nil {
+			p.stats.EphemeralStorage = fsInfoStats(total.time, rootFs, fsUsed, fsInodesUsed)
+		}
+		summary.Pods = append(summary.Pods, p.stats)
+	}
+
+	return summary
+}
+
+func cpuStats(usage cgroupUsage) *models.CpuStats {
+	if usage.cpuTotal == nil && usage.cpuNanoCores == nil {
+		return nil
+	}
+	return &models.CpuStats{
+		Time:                 usage.time,
+		UsageCoreNanoSeconds: toInt64Ptr(usage.cpuTotal),
+		UsageNanoCores:       toInt64Ptr(usage.cpuNanoCores),
+	}
+}
+
+// memoryStats reports the memory available below limit, like the kubelet
+// the limit minus the working set.
+func memoryStats(usage cgroupUsage, limit *uint64) *models.MemoryStats {
+	if usage.memoryUsage == nil && usage.memoryWorkingSet == nil {
+		return nil
+	}
+	stats := &models.MemoryStats{
+		Time:            usage.time,
+		UsageBytes:      toInt64Ptr(usage.memoryUsage),
+		WorkingSetBytes: toInt64Ptr(usage.memoryWorkingSet),
+		RssBytes:        toInt64Ptr(usage.memoryRss),
+		PageFaults:      toInt64Ptr(usage.pageFaults),
+		MajorPageFaults: toInt64Ptr(usage.majorPageFaults),
+	}
+	if limit != nil && usage.memoryWorkingSet != nil {
+		available := uint64(0)
+		if *limit > *usage.memoryWorkingSet {
+			available = *limit - *usage.memoryWorkingSet
+		}
+		stats.AvailableBytes = toInt64Ptr(&available)
+	}
+	return stats
+}
+
+// networkStats reports the first interface as the default one.
+func networkStats(at time.Time, interfaces []models.InterfaceStats) *models.NetworkStats {
+	if len(interfaces) == 0 {
+		return nil
+	}
+	stats := &models.NetworkStats{
+		Time:     at,
+		RxBytes:  interfaces[0].RxBytes,
+		RxErrors: interfaces[0].RxErrors,
+		TxBytes:  interfaces[0].TxBytes,
+		TxErrors: interfaces[0].TxErrors,
+	}
+	if interfaces[0].Name != "" {
+		stats.Name = &interfaces[0].Name
+		stats.Interfaces = &interfaces
+	}
+	return stats
+}
+
+// fsInfoStats reports the usage of a filesystem, or the bytes and inodes
+// used on it when used is set. fsInfo may be nil.
+func fsInfoStats(at time.Time, fsInfo *models.FsInfo, used, inodesUsed *uint64) *models.FsStats {
+	stats := &models.FsStats{
+		Time:       at,
+		UsedBytes:  toInt64Ptr(used),
+		InodesUsed: toInt64Ptr(inodesUsed),
+	}
+	if fsInfo == nil {
+		return stats
+	}
+
+	if used == nil {
+		stats.UsedBytes = &fsInfo.Usage
+	}
+	stats.CapacityBytes = &fsInfo.Capacity
+	stats.AvailableBytes = &fsInfo.Available
+	stats.Inodes = fsInfo.Inodes
+	stats.InodesFree = fsInfo.InodesFree
+	if inodesUsed == nil && fsInfo.Inodes != nil && fsInfo.InodesFree != nil {
+		inodesUsed := *fsInfo.Inodes - *fsInfo.InodesFree
+		stats.InodesUsed = &inodesUsed
+	}
+	return stats
+}
+
+func addUint64(sum, value *uint64) *uint64 {
+	if value == nil {
+		return sum
+	}
+	total := *value
+	if sum != nil {
+		total += *sum
+	}
+	return &total
+}
+
+func uint64Ptr(value float64) *uint64 {
+	v := uint64(value)
+	return &v
+}
+
+func toInt64Ptr(value *uint64) *int64 {
+	if value == nil {
+		return nil
+	}
+	v := int64(*value)
+	return &v
+}
+
+func int64Ptr(value uint64) *int64 {
+	v := int64(value)
+	return &v
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
+	"log"
 	"math/rand"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path"
 	"strings"
 	"syscall"
 	"time"
@@ -14,22 +19,78 @@ import (
 
 	"github.com/spf13/pflag"
 	"github.com/zawachte/stalker/internal/api"
-	"github.com/zawachte/stalker/internal/models"
+	"github.com/zawachte/stalker/internal/cli"
 	"github.com/zawachte/stalker/internal/providers"
+	"github.com/zawachte/stalker/internal/services"
+	"github.com/zawachte/stalker/pkg/alerting"
+	"github.com/zawachte/stalker/pkg/anomaly"
+	"github.com/zawachte/stalker/pkg/cadvisor"
+	"github.com/zawachte/stalker/pkg/filter"
 	"github.com/zawachte/stalker/pkg/influx"
 	"github.com/zawachte/stalker/pkg/influx_cli"
 	"github.com/zawachte/stalker/pkg/influxd"
-	"github.com/zawachte/stalker/pkg/responsewriter"
+	"github.com/zawachte/stalker/pkg/notify"
+	"github.com/zawachte/stalker/pkg/otlp"
+	"github.com/zawachte/stalker/pkg/procs"
+	"github.com/zawachte/stalker/pkg/push"
+	"github.com/zawachte/stalker/pkg/remotewrite"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Set through ldflags by the Makefile.
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
 )
 
 func main() {
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	var retention time.Duration
 	var backupFrequency time.Duration
 	var metricsScrapeFrequency time.Duration
 
 	var unixSocket string
+	var listenAddress string
+	var apiToken string
 	var backupPath string
 
+	var agents []string
+	var agentToken string
+	var agentTimeout time.Duration
+
+	var remoteWriteUrl string
+	var remoteWriteBearerToken string
+	var remoteWriteQueueCapacity int
+	var remoteWriteMaxRetries int
+
+	var pushUrl string
+	var pushToken string
+	var pushBufferPath string
+	var pushMaxBufferBytes int64
+	var pushAgentsPath string
+
+	var otlpEndpoint string
+	var otlpProtocol string
+	var otlpInsecure bool
+	var otlpHeaders map[string]string
+
+	var containerFilterPath string
+	var labelPolicyPath string
+	var seriesBudget int
+
+	var runtimeEndpoint string
+	var topProcesses int
+	var topProcessesBy []string
+	var alertRulesPath string
+	var alertNotifiersPath string
+	var anomalyOptions anomaly.Options
+	cadvisorOptions := cadvisor.DefaultOptions()
+
 	fs := pflag.CommandLine
 	fs.DurationVar(&retention,
 		"retention",
@@ -51,6 +112,32 @@ func main() {
 		"stalker.sock",
 		"unix socket path",
 	)
+	fs.StringVar(&listenAddress,
+		"listen-address",
+		"",
+		"optional tcp address (host:port) to serve the api on, e.g. for prometheus scrapes",
+	)
+	fs.StringVar(&apiToken,
+		"api-token",
+		"",
+		"optional bearer token required by the api served on the listen address, which refuses backups, restores and imports without it; the unix socket is guarded by its file permissions",
+	)
+
+	fs.StringSliceVar(&agents,
+		"agents",
+		[]string{},
+		"run as an aggregator of the stalker agents at these [node=]addresses, unix sockets, host:ports or http(s) urls, instead of collecting metrics",
+	)
+	fs.StringVar(&agentToken,
+		"agent-token",
+		"",
+		"bearer token sent to the agents, their --api-token",
+	)
+	fs.DurationVar(&agentTimeout,
+		"agent-timeout",
+		services.DefaultAgentTimeout,
+		"timeout of the queries of an aggregator to an agent",
+	)
 
 	fs.StringVar(&backupPath,
 		"backup-path",
@@ -58,9 +145,270 @@ func main() {
 		"path for database backups",
 	)
 
+	fs.StringVar(&remoteWriteUrl,
+		"remote-write-url",
+		"",
+		"optional prometheus remote_write endpoint to forward collected metrics to",
+	)
+	fs.StringVar(&remoteWriteBearerToken,
+		"remote-write-bearer-token",
+		"",
+		"bearer token sent to the remote_write endpoint",
+	)
+	fs.IntVar(&remoteWriteQueueCapacity,
+		"remote-write-queue-capacity",
+		100,
+		"number of remote_write batches buffered before metrics are dropped",
+	)
+	fs.IntVar(&remoteWriteMaxRetries,
+		"remote-write-max-retries",
+		5,
+		"number of retries for a failed remote_write batch",
+	)
+
+	fs.StringVar(&pushUrl,
+		"push-url",
+		"",
+		"optional url of a central stalker, its --listen-address, to push collected metrics to",
+	)
+	fs.StringVar(&pushToken,
+		"push-token",
+		"",
+		"bearer token of this agent in the --push-agents file of the central stalker",
+	)
+	fs.StringVar(&pushBufferPath,
+		"push-buffer-path",
+		"push-buffer",
+		"directory metrics are buffered in until the central stalker accepted them",
+	)
+	fs.Int64Var(&pushMaxBufferBytes,
+		"push-max-buffer-bytes",
+		256<<20,
+		"size of the push buffer beyond which the oldest metrics are dropped",
+	)
+	fs.StringVar(&pushAgentsPath,
+		"push-agents",
+		"",
+		"optional yaml file with the names, tokens and quotas of the agents allowed to push to this stalker, reloaded on SIGHUP",
+	)
+
+	fs.StringVar(&otlpEndpoint,
+		"otlp-endpoint",
+		"",
+		"optional opentelemetry collector endpoint to export collected metrics to",
+	)
+	fs.StringVar(&otlpProtocol,
+		"otlp-protocol",
+		otlp.ProtocolHTTP,
+		"otlp transport, http/protobuf or grpc",
+	)
+	fs.BoolVar(&otlpInsecure,
+		"otlp-insecure",
+		false,
+		"disable tls for the otlp grpc connection",
+	)
+	fs.StringToStringVar(&otlpHeaders,
+		"otlp-headers",
+		map[string]string{},
+		"headers sent with every otlp export, e.g. api-key=secret",
+	)
+
+	fs.StringVar(&containerFilterPath,
+		"container-filter",
+		"",
+		"optional yaml file with container include/exclude rules, reloaded on SIGHUP",
+	)
+
+	fs.StringVar(&labelPolicyPath,
+		"label-policy",
+		"",
+		"optional yaml file selecting and renaming the container labels stored as tags, reloaded on SIGHUP",
+	)
+	fs.IntVar(&seriesBudget,
+		"series-budget",
+		2000,
+		"maximum number of series per container, excess series are dropped; 0 disables the limit",
+	)
+
+	fs.StringSliceVar(&cadvisorOptions.Metrics,
+		"metrics",
+		cadvisorOptions.Metrics,
+		"cadvisor metric sets to collect, e.g. cpu,memory,disk,tcp,hugetlb,perf_event,resctrl,cpu_topology,oom_event",
+	)
+	fs.DurationVar(&cadvisorOptions.HousekeepingInterval,
+		"housekeeping-interval",
+		cadvisorOptions.HousekeepingInterval,
+		"interval between container stats collections",
+	)
+	fs.DurationVar(&cadvisorOptions.MaxHousekeepingInterval,
+		"max-housekeeping-interval",
+		cadvisorOptions.MaxHousekeepingInterval,
+		"largest interval between container stats collections when dynamic housekeeping backs off",
+	)
+	fs.BoolVar(&cadvisorOptions.AllowDynamicHousekeeping,
+		"allow-dynamic-housekeeping",
+		cadvisorOptions.AllowDynamicHousekeeping,
+		"back off the collection of containers whose stats do not change",
+	)
+	fs.DurationVar(&cadvisorOptions.StatsCacheDuration,
+		"stats-cache-duration",
+		cadvisorOptions.StatsCacheDuration,
+		"how long collected container stats are kept in memory",
+	)
+	fs.StringVar(&cadvisorOptions.PerfEventsFile,
+		"perf-events-config",
+		"",
+		"perf events configuration file, required by the perf_event metric set",
+	)
+
+	fs.StringVar(&runtimeEndpoint,
+		"container-runtime-endpoint",
+		"",
+		"container runtime socket used to locate the image filesystem, detected from docker, containerd and cri-o when empty",
+	)
+
+	fs.IntVar(&topProcesses,
+		"top-processes",
+		0,
+		"number of processes per container to report by cpu and memory usage, 0 disables the report",
+	)
+	fs.StringSliceVar(&topProcessesBy,
+		"top-processes-by",
+		[]string{procs.ByCPU, procs.ByRSS},
+		"orders the top processes are reported in, cpu and/or rss",
+	)
+
+	fs.StringVar(&alertRulesPath,
+		"alert-rules",
+		"",
+		"optional yaml file with alerting rules evaluated on every scrape, reloaded on SIGHUP",
+	)
+	fs.StringVar(&alertNotifiersPath,
+		"alert-notifiers",
+		"",
+		"optional yaml file with the webhook, slack and email receivers alerts are routed to, reloaded on SIGHUP",
+	)
+
+	fs.StringVar(&anomalyOptions.Method,
+		"anomaly-detection",
+		"",
+		"method learning the baseline anomalies are flagged against, ewma or rolling-mad; empty disables anomaly detection",
+	)
+	fs.Float64Var(&anomalyOptions.Threshold,
+		"anomaly-threshold",
+		3,
+		"number of standard deviations from the baseline a sample is flagged at",
+	)
+	fs.IntVar(&anomalyOptions.Window,
+		"anomaly-window",
+		60,
+		"number of samples the baseline of a series covers",
+	)
+	fs.StringSliceVar(&anomalyOptions.Series,
+		"anomaly-series",
+		anomaly.DefaultSeries,
+		"series analyzed for anomalies, cumulative series are analyzed as per second rates",
+	)
+
 	fs.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 
+	if len(agents) > 0 {
+		federated := []services.Agent{}
+		for _, agent := range agents {
+			federated = append(federated, services.ParseAgent(agent))
+		}
+
+		aggregator, err := providers.NewFederationProvider(providers.FederationProviderParams{
+			Agents:  federated,
+			Token:   agentToken,
+			Timeout: agentTimeout,
+			Build: providers.BuildInfo{
+				Version: version,
+				Commit:  commit,
+				Date:    date,
+			},
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		serve(api.Handler(aggregator), unixSocket, listenAddress, apiToken, false)
+		return
+	}
+
+	err := cadvisorOptions.Validate()
+	if err != nil {
+		panic(err)
+	}
+
+	containerFilter, err := filter.NewFile(containerFilterPath)
+	if err != nil {
+		panic(err)
+	}
+
+	labelPolicy, err := influx.NewLabelPolicyFile(labelPolicyPath)
+	if err != nil {
+		panic(err)
+	}
+
+	var pushAgents *push.AgentsFile
+	if pushAgentsPath != "" {
+		pushAgents, err = push.NewAgentsFile(pushAgentsPath)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	alerts, err := alerting.NewEngine(alertRulesPath)
+	if err != nil {
+		panic(err)
+	}
+
+	notifiers, err := notify.NewDispatcher(alertNotifiersPath)
+	if err != nil {
+		panic(err)
+	}
+	defer notifiers.Close()
+	alerts.SetNotifier(notifiers)
+
+	var anomalies *anomaly.Detector
+	if anomalyOptions.Method != "" {
+		anomalies, err = anomaly.NewDetector(anomalyOptions)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	go func() {
+		hangups := make(chan os.Signal, 1)
+		signal.Notify(hangups, syscall.SIGHUP)
+		for range hangups {
+			err := containerFilter.Reload()
+			if err != nil {
+				log.Printf("failed to reload container filter: %v", err)
+			}
+			err = labelPolicy.Reload()
+			if err != nil {
+				log.Printf("failed to reload label policy: %v", err)
+			}
+			err = alerts.Reload()
+			if err != nil {
+				log.Printf("failed to reload alert rules: %v", err)
+			}
+			err = notifiers.Reload()
+			if err != nil {
+				log.Printf("failed to reload alert notifiers: %v", err)
+			}
+			if pushAgents != nil {
+				err = pushAgents.Reload()
+				if err != nil {
+					log.Printf("failed to reload push agents: %v", err)
+				}
+			}
+		}
+	}()
+
 	abortCh := make(chan error, 1)
 	go func() {
 		err := influxd.RunInfluxD(abortCh)
@@ -96,52 +444,188 @@ func main() {
 	}
 
 	fmt.Println("setup")
-	syscall.Unlink(unixSocket)
 
-	// remove when we get there
-	unixListener, err := net.Listen("unix", unixSocket)
-	if err != nil {
-		panic(err)
+	sinks := []services.PointSink{}
+	if remoteWriteUrl != "" {
+		sender, err := remotewrite.NewSender(remotewrite.SenderParams{
+			Url:           remoteWriteUrl,
+			BearerToken:   remoteWriteBearerToken,
+			QueueCapacity: remoteWriteQueueCapacity,
+			MaxRetries:    remoteWriteMaxRetries,
+		})
+		if err != nil {
+			panic(err)
+		}
+		defer sender.Close()
+
+		sinks = append(sinks, sender)
 	}
-	defer unixListener.Close()
+
+	if pushUrl != "" {
+		pusher, err := push.NewPusher(push.PusherParams{
+			Url:            pushUrl,
+			Token:          pushToken,
+			BufferPath:     pushBufferPath,
+			MaxBufferBytes: pushMaxBufferBytes,
+		})
+		if err != nil {
+			panic(err)
+		}
+		defer pusher.Close()
+
+		sinks = append(sinks, pusher)
+	}
+
+	if otlpEndpoint != "" {
+		exporter, err := otlp.NewExporter(otlp.ExporterParams{
+			Endpoint: otlpEndpoint,
+			Protocol: otlpProtocol,
+			Insecure: otlpInsecure,
+			Headers:  otlpHeaders,
+		})
+		if err != nil {
+			panic(err)
+		}
+		defer exporter.Close()
+
+		sinks = append(sinks, exporter)
+	}
+
+	backups := services.NewBackupService(services.BackupServiceParams{
+		InfluxCli: influxCli,
+		Org:       influx.DefaultOrgName,
+		Bucket:    influx.DefaultBucketName,
+		Path:      backupPath,
+	})
 
 	metricsProvider, err := providers.NewProvider(context.Background(), providers.ProviderParams{
-		DatabaseUrl:   "http://localhost:8086",
-		DatabaseToken: token,
+		DatabaseUrl:     "http://localhost:8086",
+		DatabaseToken:   token,
+		Sinks:           sinks,
+		Containers:      containerFilter,
+		LabelPolicy:     labelPolicy,
+		SeriesBudget:    seriesBudget,
+		CAdvisor:        cadvisorOptions,
+		RuntimeEndpoint: runtimeEndpoint,
+		TopProcesses:    topProcesses,
+		TopProcessesBy:  topProcessesBy,
+		Alerts:          alerts,
+		Anomalies:       anomalies,
+		Backups:         backups,
+		PushAgents:      pushAgents,
+		Build: providers.BuildInfo{
+			Version: version,
+			Commit:  commit,
+			Date:    date,
+		},
 	})
 	if err != nil {
 		panic(err)
 	}
 
-	backupParams := influx_cli.BackupInfluxParams{
-		Org:    influx.DefaultOrgName,
-		Bucket: influx.DefaultBucketName,
-		Path:   backupPath,
-	}
-
 	go func() {
 		for {
 			time.Sleep(backupFrequency)
-			err = influxCli.BackupInflux(backupParams)
+			_, err = backups.Backup(context.Background())
 			if err != nil {
 				panic(err)
 			}
 		}
 	}()
 
+	// Reads are served from the stored points, only this loop scrapes.
 	go func() {
-		for {
-			time.Sleep(metricsScrapeFrequency)
-			w := responsewriter.NewCustomResponseWriter()
-			r := &http.Request{}
-			metricsProvider.GetMetricsList(w, r, models.GetMetricsListParams{})
+		ticker := time.NewTicker(metricsScrapeFrequency)
+		defer ticker.Stop()
+		for range ticker.C {
+			err := metricsProvider.Scrape(context.Background())
+			if err != nil {
+				log.Printf("failed to scrape the containers: %v", err)
+			}
 		}
 	}()
 
-	server := http.Server{Handler: api.Handler(metricsProvider)}
+	serve(api.Handler(metricsProvider), unixSocket, listenAddress, apiToken, pushAgents != nil)
+}
+
+// serve serves the api on the unix socket and, when given, on the listen
+// address guarded by the api token. The listen address accepts cleartext
+// HTTP/2 for pushing agents, whose pushes are authenticated with their own
+// tokens instead. Without an api token it refuses backups, restores and
+// imports.
+func serve(handler http.Handler, unixSocket, listenAddress, apiToken string, acceptPushes bool) {
+	syscall.Unlink(unixSocket)
+
+	// remove when we get there
+	unixListener, err := net.Listen("unix", unixSocket)
+	if err != nil {
+		panic(err)
+	}
+	defer unixListener.Close()
+
+	server := http.Server{Handler: handler}
+	if listenAddress != "" {
+		tcpListener, err := net.Listen("tcp", listenAddress)
+		if err != nil {
+			panic(err)
+		}
+		defer tcpListener.Close()
+
+		tcpHandler := requireBearerToken(apiToken, handler)
+		if apiToken == "" {
+			tcpHandler = refusePaths(tokenOnlyPaths, tcpHandler)
+		}
+		if acceptPushes {
+			mux := http.NewServeMux()
+			mux.Handle("/push", handler)
+			mux.Handle("/", tcpHandler)
+			tcpHandler = mux
+		}
+
+		tcpServer := http.Server{Handler: h2c.NewHandler(tcpHandler, &http2.Server{})}
+		go tcpServer.Serve(tcpListener)
+	}
 	server.Serve(unixListener)
 }
 
+// tokenOnlyPaths replace or bulk load the stored metrics, they are only
+// served on the listen address when it is guarded by an api token.
+var tokenOnlyPaths = []string{"/backup", "/restore", "/import"}
+
+// refusePaths answers requests of the paths with 403.
+func refusePaths(paths []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested := path.Clean(r.URL.Path)
+		for _, p := range paths {
+			if requested == p {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(p + " is only served on the listen address when --api-token is set"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireBearerToken rejects requests without the token, it passes every
+// request when the token is empty.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func generateToken() string {
 	rand.Seed(time.Now().UnixNano())
 	chars := []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZÅÄÖ" +
@@ -0,0 +1,444 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/influx"
+	"gopkg.in/yaml.v2"
+)
+
+// States an alert moves through.
+const (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// DefaultResolvedRetention is how long resolved alerts are still listed.
+const DefaultResolvedRetention = 15 * time.Minute
+
+// Config is the content of a rule file.
+type Config struct {
+	Rules []RuleConfig `yaml:"rules"`
+}
+
+// RuleConfig describes a rule, e.g.
+//
+//	name: MemoryNearLimit
+//	expr: memory_working_set / memory_limit > 0.9
+//	for: 2m
+type RuleConfig struct {
+	Name string `yaml:"name"`
+	// Expr compares series collected on every scrape, see Expr.
+	Expr string `yaml:"expr"`
+	// For is how long the expression must hold before the alert fires, as a
+	// duration like 30s or 2m. Alerts without it fire on the first scrape.
+	For         string            `yaml:"for"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// Rule is a compiled RuleConfig.
+type Rule struct {
+	Name        string
+	Expr        string
+	For         time.Duration
+	Labels      map[string]string
+	Annotations map[string]string
+
+	expr   Expr
+	series []string
+}
+
+// Alert is the state of a rule for one label set.
+type Alert struct {
+	Rule        string
+	Expr        string
+	State       string
+	Labels      map[string]string
+	Annotations map[string]string
+	// Value is the value of the series the expression compared, as of the
+	// last scrape the expression held.
+	Value      float64
+	ActiveAt   time.Time
+	FiredAt    time.Time
+	ResolvedAt time.Time
+}
+
+// NewRules compiles a rule configuration.
+func NewRules(config Config) ([]*Rule, error) {
+	rules := []*Rule{}
+	names := map[string]struct{}{}
+	for i, rc := range config.Rules {
+		if rc.Name == "" {
+			return nil, fmt.Errorf("rule %d: missing name", i)
+		}
+		if _, ok := names[rc.Name]; ok {
+			return nil, fmt.Errorf("rule %s: duplicate name", rc.Name)
+		}
+		names[rc.Name] = struct{}{}
+
+		expr, err := ParseExpr(rc.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rc.Name, err)
+		}
+		series := expr.names(nil)
+		if len(series) == 0 {
+			return nil, fmt.Errorf("rule %s: expression references no series", rc.Name)
+		}
+
+		var forDuration time.Duration
+		if rc.For != "" {
+			forDuration, err = time.ParseDuration(rc.For)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", rc.Name, err)
+			}
+			if forDuration < 0 {
+				return nil, fmt.Errorf("rule %s: negative for duration", rc.Name)
+			}
+		}
+
+		rules = append(rules, &Rule{
+			Name:        rc.Name,
+			Expr:        rc.Expr,
+			For:         forDuration,
+			Labels:      rc.Labels,
+			Annotations: rc.Annotations,
+			expr:        expr,
+			series:      series,
+		})
+	}
+
+	return rules, nil
+}
+
+// LoadRules reads and compiles a YAML rule file.
+func LoadRules(path string) ([]*Rule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := Config{}
+	err = yaml.UnmarshalStrict(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	rules, err := NewRules(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+// Notifier is handed the alerts after every evaluation, so it can deliver
+// new states and repeat the notifications of alerts that keep firing.
+type Notifier interface {
+	Notify(now time.Time, alerts []Alert)
+}
+
+// Engine evaluates the rules of a rule file against the points of every
+// scrape. A nil Engine has no rules.
+type Engine struct {
+	path              string
+	resolvedRetention time.Duration
+
+	mu       sync.Mutex
+	rules    []*Rule
+	alerts   map[string]map[string]*Alert
+	notifier Notifier
+}
+
+// NewEngine loads the rule file at path. An empty path yields an engine
+// without rules.
+func NewEngine(path string) (*Engine, error) {
+	engine := &Engine{
+		path:              path,
+		resolvedRetention: DefaultResolvedRetention,
+		alerts:            map[string]map[string]*Alert{},
+	}
+	if path == "" {
+		return engine, nil
+	}
+
+	err := engine.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return engine, nil
+}
+
+// Reload re-reads the rule file. The previous rules stay in effect when the
+// new file is invalid. Alerts of rules that were kept carry over, the alerts
+// of removed rules are dropped.
+func (e *Engine) Reload() error {
+	if e == nil || e.path == "" {
+		return nil
+	}
+
+	rules, err := LoadRules(e.path)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	kept := map[string]map[string]*Alert{}
+	for _, rule := range rules {
+		if alerts, ok := e.alerts[rule.Name]; ok {
+			kept[rule.Name] = alerts
+		}
+	}
+	e.rules = rules
+	e.alerts = kept
+
+	return nil
+}
+
+// SetNotifier sets the notifier told about the alerts after every evaluation.
+func (e *Engine) SetNotifier(notifier Notifier) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.notifier = notifier
+}
+
+// WritePoints evaluates the rules against a scrape and notifies the alerts.
+func (e *Engine) WritePoints(ctx context.Context, points []*write.Point) error {
+	if e == nil {
+		return nil
+	}
+
+	now := time.Now()
+	e.Evaluate(now, points)
+
+	e.mu.Lock()
+	notifier := e.notifier
+	e.mu.Unlock()
+
+	if notifier != nil {
+		notifier.Notify(now, e.Alerts())
+	}
+	return nil
+}
+
+// Evaluate updates the alerts of every rule whose series are part of points
+// and returns the alerts that changed state. Rules referencing none of the
+// series are skipped, so batches holding only events leave alerts untouched.
+func (e *Engine) Evaluate(now time.Time, points []*write.Point) []Alert {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.rules) == 0 {
+		return nil
+	}
+
+	wanted := map[string]struct{}{}
+	for _, rule := range e.rules {
+		for _, name := range rule.series {
+			wanted[name] = struct{}{}
+		}
+	}
+	series := samples(points, wanted)
+
+	changed := []Alert{}
+	for _, rule := range e.rules {
+		if !referenced(rule, series) {
+			continue
+		}
+
+		result, err := rule.expr.eval(series)
+		if err != nil {
+			log.Printf("failed to evaluate rule %s: %v", rule.Name, err)
+			continue
+		}
+		changed = append(changed, e.update(rule, result.vector, now)...)
+	}
+
+	e.expire(now)
+
+	return changed
+}
+
+// update moves the alerts of a rule through their states given the samples
+// the expression held for.
+func (e *Engine) update(rule *Rule, active map[string]sample, now time.Time) []Alert {
+	alerts, ok := e.alerts[rule.Name]
+	if !ok {
+		alerts = map[string]*Alert{}
+		e.alerts[rule.Name] = alerts
+	}
+
+	changed := []Alert{}
+	for key, s := range active {
+		alert, ok := alerts[key]
+		if !ok || alert.State == StateResolved {
+			alert = &Alert{
+				Rule:        rule.Name,
+				Expr:        rule.Expr,
+				State:       StatePending,
+				Labels:      mergeLabels(s.tags, rule.Labels),
+				Annotations: rule.Annotations,
+				Value:       s.value,
+				ActiveAt:    now,
+			}
+			alerts[key] = alert
+			if rule.For > 0 {
+				changed = append(changed, alert.copy())
+			}
+		}
+		alert.Value = s.value
+
+		if alert.State == StatePending && now.Sub(alert.ActiveAt) >= rule.For {
+			alert.State = StateFiring
+			alert.FiredAt = now
+			changed = append(changed, alert.copy())
+		}
+	}
+
+	for key, alert := range alerts {
+		if _, ok := active[key]; ok {
+			continue
+		}
+		switch alert.State {
+		case StatePending:
+			delete(alerts, key)
+		case StateFiring:
+			alert.State = StateResolved
+			alert.ResolvedAt = now
+			changed = append(changed, alert.copy())
+		}
+	}
+
+	return changed
+}
+
+// expire drops the resolved alerts older than the retention.
+func (e *Engine) expire(now time.Time) {
+	deadline := now.Add(-e.resolvedRetention)
+	for _, alerts := range e.alerts {
+		for key, alert := range alerts {
+			if alert.State == StateResolved && alert.ResolvedAt.Before(deadline) {
+				delete(alerts, key)
+			}
+		}
+	}
+}
+
+// Alerts returns the pending, firing and recently resolved alerts, ordered by
+// rule and then by labels.
+func (e *Engine) Alerts() []Alert {
+	alerts := []Alert{}
+	if e == nil {
+		return alerts
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	type keyedAlert struct {
+		key   string
+		alert *Alert
+	}
+	sorted := []keyedAlert{}
+	for _, ruleAlerts := range e.alerts {
+		for key, alert := range ruleAlerts {
+			sorted = append(sorted, keyedAlert{key: key, alert: alert})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].alert.Rule != sorted[j].alert.Rule {
+			return sorted[i].alert.Rule < sorted[j].alert.Rule
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	for _, a := range sorted {
+		alerts = append(alerts, a.alert.copy())
+	}
+	return alerts
+}
+
+// Rules returns the rules currently in effect.
+func (e *Engine) Rules() []*Rule {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.rules
+}
+
+// Key identifies an alert by its rule and labels.
+func (a Alert) Key() string {
+	return a.Rule + "\xff" + labelKey(a.Labels)
+}
+
+func (a *Alert) copy() Alert {
+	c := *a
+	return c
+}
+
+// samples groups the value of the wanted series by name.
+func samples(points []*write.Point, wanted map[string]struct{}) map[string][]sample {
+	series := map[string][]sample{}
+	for _, point := range points {
+		if _, ok := wanted[point.Name()]; !ok {
+			continue
+		}
+
+		for _, field := range point.FieldList() {
+			if field.Key != influx.ValueField {
+				continue
+			}
+			value, ok := influx.ToFloat(field.Value)
+			if !ok {
+				continue
+			}
+
+			tags := map[string]string{}
+			for _, tag := range point.TagList() {
+				tags[tag.Key] = tag.Value
+			}
+			series[point.Name()] = append(series[point.Name()], sample{tags: tags, value: value})
+		}
+	}
+	return series
+}
+
+func referenced(rule *Rule, series map[string][]sample) bool {
+	for _, name := range rule.series {
+		if _, ok := series[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeLabels(tags, labels map[string]string) map[string]string {
+	merged := make(map[string]string, len(tags)+len(labels))
+	for k, v := range tags {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,212 @@
+package alerting
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+func memoryPoint(container string, value float64) *write.Point {
+	return write.NewPoint("memory_usage", map[string]string{"container_name": container}, map[string]interface{}{"value": value}, time.Unix(1600000000, 0))
+}
+
+func newTestEngine(t *testing.T, config Config) *Engine {
+	t.Helper()
+	rules, err := NewRules(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Engine{
+		resolvedRetention: DefaultResolvedRetention,
+		rules:             rules,
+		alerts:            map[string]map[string]*Alert{},
+	}
+}
+
+// states lists the alerts as sorted container=state strings.
+func states(alerts []Alert) string {
+	got := []string{}
+	for _, alert := range alerts {
+		got = append(got, alert.Labels["container_name"]+"="+alert.State)
+	}
+	return strings.Join(got, ",")
+}
+
+func TestEngineStates(t *testing.T) {
+	e := newTestEngine(t, Config{Rules: []RuleConfig{{
+		Name:   "high_memory",
+		Expr:   "memory_usage > 80",
+		For:    "1m",
+		Labels: map[string]string{"severity": "page"},
+	}}})
+
+	start := time.Unix(1600000000, 0)
+	steps := []struct {
+		name    string
+		after   time.Duration
+		points  []*write.Point
+		changed string
+		alerts  string
+	}{
+		{name: "below the threshold", after: 0, points: []*write.Point{memoryPoint("web", 50)}, changed: "", alerts: ""},
+		{name: "pending", after: 10 * time.Second, points: []*write.Point{memoryPoint("web", 90)}, changed: "web=pending", alerts: "web=pending"},
+		{name: "still pending", after: 30 * time.Second, points: []*write.Point{memoryPoint("web", 95)}, changed: "", alerts: "web=pending"},
+		{name: "firing after for", after: 70 * time.Second, points: []*write.Point{memoryPoint("web", 95)}, changed: "web=firing", alerts: "web=firing"},
+		{name: "other series leave alerts untouched", after: 80 * time.Second, points: []*write.Point{write.NewPoint("cpu_usage_total", nil, map[string]interface{}{"value": 1.0}, start)}, changed: "", alerts: "web=firing"},
+		{name: "second container pending", after: 90 * time.Second, points: []*write.Point{memoryPoint("web", 95), memoryPoint("db", 85)}, changed: "db=pending", alerts: "db=pending,web=firing"},
+		{name: "pending alert dropped", after: 100 * time.Second, points: []*write.Point{memoryPoint("web", 95), memoryPoint("db", 10)}, changed: "", alerts: "web=firing"},
+		{name: "resolved", after: 110 * time.Second, points: []*write.Point{memoryPoint("web", 10)}, changed: "web=resolved", alerts: "web=resolved"},
+		{name: "pending again", after: 120 * time.Second, points: []*write.Point{memoryPoint("web", 90)}, changed: "web=pending", alerts: "web=pending"},
+		{name: "back below", after: 130 * time.Second, points: []*write.Point{memoryPoint("web", 10)}, changed: "", alerts: ""},
+	}
+
+	for _, step := range steps {
+		changed := e.Evaluate(start.Add(step.after), step.points)
+		if got := states(changed); got != step.changed {
+			t.Errorf("%s: changed %q, want %q", step.name, got, step.changed)
+		}
+		if got := states(e.Alerts()); got != step.alerts {
+			t.Errorf("%s: alerts %q, want %q", step.name, got, step.alerts)
+		}
+	}
+}
+
+func TestEngineAlertFields(t *testing.T) {
+	e := newTestEngine(t, Config{Rules: []RuleConfig{{
+		Name:        "high_memory",
+		Expr:        "memory_usage > 80",
+		For:         "30s",
+		Labels:      map[string]string{"severity": "page", "container_name": "overridden"},
+		Annotations: map[string]string{"summary": "memory is high"},
+	}}})
+
+	start := time.Unix(1600000000, 0)
+	e.Evaluate(start, []*write.Point{memoryPoint("web", 90)})
+	e.Evaluate(start.Add(30*time.Second), []*write.Point{memoryPoint("web", 95)})
+	e.Evaluate(start.Add(40*time.Second), []*write.Point{memoryPoint("web", 97)})
+	e.Evaluate(start.Add(50*time.Second), []*write.Point{memoryPoint("web", 10)})
+
+	alerts := e.Alerts()
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.Labels["severity"] != "page" || alert.Labels["container_name"] != "overridden" || alert.Annotations["summary"] != "memory is high" {
+		t.Errorf("got labels %v annotations %v", alert.Labels, alert.Annotations)
+	}
+	// The value is the one of the last scrape the expression held.
+	if alert.Value != 97 {
+		t.Errorf("got value %v, want 97", alert.Value)
+	}
+	if !alert.ActiveAt.Equal(start) || !alert.FiredAt.Equal(start.Add(30*time.Second)) || !alert.ResolvedAt.Equal(start.Add(50*time.Second)) {
+		t.Errorf("got active %v fired %v resolved %v", alert.ActiveAt, alert.FiredAt, alert.ResolvedAt)
+	}
+
+	// Resolved alerts are listed until the retention passed.
+	e.Evaluate(start.Add(50*time.Second+DefaultResolvedRetention), []*write.Point{memoryPoint("web", 10)})
+	if len(e.Alerts()) != 1 {
+		t.Errorf("resolved alert expired before the retention")
+	}
+	e.Evaluate(start.Add(time.Minute+DefaultResolvedRetention), []*write.Point{memoryPoint("web", 10)})
+	if len(e.Alerts()) != 0 {
+		t.Errorf("resolved alert kept after the retention")
+	}
+}
+
+func TestEngineFiresWithoutFor(t *testing.T) {
+	e := newTestEngine(t, Config{Rules: []RuleConfig{{Name: "high_memory", Expr: "memory_usage > 80"}}})
+
+	changed := e.Evaluate(time.Unix(1600000000, 0), []*write.Point{memoryPoint("web", 90)})
+	// Without a for duration the alert skips the pending state.
+	if got := states(changed); got != "web=firing" {
+		t.Errorf("changed %q, want web=firing", got)
+	}
+}
+
+func TestNewRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    RuleConfig
+		wantFor time.Duration
+		error   string
+	}{
+		{name: "seconds", rule: RuleConfig{Name: "a", Expr: "memory_usage > 1", For: "30s"}, wantFor: 30 * time.Second},
+		{name: "compound", rule: RuleConfig{Name: "a", Expr: "memory_usage > 1", For: "1h30m"}, wantFor: 90 * time.Minute},
+		{name: "no for", rule: RuleConfig{Name: "a", Expr: "memory_usage > 1"}, wantFor: 0},
+		{name: "zero", rule: RuleConfig{Name: "a", Expr: "memory_usage > 1", For: "0s"}, wantFor: 0},
+		{name: "without unit", rule: RuleConfig{Name: "a", Expr: "memory_usage > 1", For: "30"}, error: "rule a: time: missing unit in duration"},
+		{name: "negative", rule: RuleConfig{Name: "a", Expr: "memory_usage > 1", For: "-1m"}, error: "rule a: negative for duration"},
+		{name: "missing name", rule: RuleConfig{Expr: "memory_usage > 1"}, error: "rule 0: missing name"},
+		{name: "invalid expression", rule: RuleConfig{Name: "a", Expr: "memory_usage"}, error: "rule a: expression must be a comparison"},
+		{name: "no series", rule: RuleConfig{Name: "a", Expr: "1 > 0"}, error: "rule a: expression references no series"},
+	}
+
+	for _, tt := range tests {
+		rules, err := NewRules(Config{Rules: []RuleConfig{tt.rule}})
+		if tt.error != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.error) {
+				t.Errorf("%s: got error %v, want %s", tt.name, err, tt.error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if rules[0].For != tt.wantFor {
+			t.Errorf("%s: got for %v, want %v", tt.name, rules[0].For, tt.wantFor)
+		}
+	}
+
+	_, err := NewRules(Config{Rules: []RuleConfig{{Name: "a", Expr: "memory_usage > 1"}, {Name: "a", Expr: "memory_usage > 2"}}})
+	if err == nil || err.Error() != "rule a: duplicate name" {
+		t.Errorf("got error %v, want a duplicate name", err)
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules := func(rules string) {
+		err := os.WriteFile(path, []byte(rules), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRules(`
+rules:
+  - name: high_memory
+    expr: memory_usage > 80
+  - name: low_memory
+    expr: memory_usage < 20
+`)
+	e, err := NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Evaluate(time.Unix(1600000000, 0), []*write.Point{memoryPoint("web", 90), memoryPoint("db", 10)})
+
+	writeRules("rules: [")
+	if err := e.Reload(); err == nil {
+		t.Error("reloaded an invalid rule file")
+	}
+	if len(e.Rules()) != 2 {
+		t.Errorf("got %d rules after a failed reload, want 2", len(e.Rules()))
+	}
+
+	writeRules(`
+rules:
+  - name: high_memory
+    expr: memory_usage > 85
+`)
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	// The alerts of the kept rule carry over.
+	if got := states(e.Alerts()); got != "web=firing" {
+		t.Errorf("alerts %q after the reload, want web=firing", got)
+	}
+}
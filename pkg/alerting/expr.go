@@ -0,0 +1,483 @@
+package alerting
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed rule expression. Series selectors evaluate to one sample
+// per label set, arithmetic between two selectors matches samples with
+// identical label sets and a comparison keeps the samples it holds for.
+//
+//	memory_working_set / memory_limit > 0.9
+//	cpu_cfs_throttled_ratio{container_name!="batch"} > 0.25
+type Expr interface {
+	eval(series map[string][]sample) (value, error)
+	// names appends the series referenced by the expression.
+	names([]string) []string
+}
+
+type sample struct {
+	tags  map[string]string
+	value float64
+}
+
+// value is either a scalar or a vector of samples keyed by their label set.
+type value struct {
+	scalar bool
+	number float64
+	vector map[string]sample
+}
+
+type numberExpr struct {
+	number float64
+}
+
+type matcher struct {
+	key   string
+	value string
+	equal bool
+}
+
+type selectorExpr struct {
+	name     string
+	matchers []matcher
+}
+
+type binaryExpr struct {
+	op          string
+	left, right Expr
+}
+
+type negateExpr struct {
+	expr Expr
+}
+
+// ParseExpr parses a rule expression, which must end in a comparison.
+func ParseExpr(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if b, ok := expr.(*binaryExpr); !ok || !isComparison(b.op) {
+		return nil, fmt.Errorf("expression must be a comparison")
+	}
+
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var operators = []string{">=", "<=", "==", "!=", ">", "<", "+", "-", "*", "/", "(", ")", "{", "}", ",", "="}
+
+func tokenize(input string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(input); {
+		c := rune(input[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(input) && (input[i] == '_' || input[i] == '.' || unicode.IsLetter(rune(input[i])) || unicode.IsDigit(rune(input[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[start:i]})
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(input) && (unicode.IsDigit(rune(input[i])) || input[i] == '.' || input[i] == 'e' || input[i] == 'E' ||
+				((input[i] == '+' || input[i] == '-') && (input[i-1] == 'e' || input[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[start:i]})
+		case c == '"':
+			end := strings.IndexByte(input[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: input[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					tokens = append(tokens, token{kind: tokenOp, text: op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) acceptOp(ops ...string) (string, bool) {
+	t, ok := p.peek()
+	if !ok || t.kind != tokenOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expectOp(op string) error {
+	if _, ok := p.acceptOp(op); !ok {
+		return p.unexpected(fmt.Sprintf("%q", op))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	t, ok := p.peek()
+	if !ok {
+		return fmt.Errorf("expected %s, got end of expression", expected)
+	}
+	return fmt.Errorf("expected %s, got %q", expected, t.text)
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp(">=", "<=", "==", "!=", ">", "<")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryExpr{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseAdditive() (Expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if _, ok := p.acceptOp("-"); ok {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateExpr{expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	if _, ok := p.acceptOp("("); ok {
+		expr, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expectOp(")")
+	}
+
+	t, ok := p.peek()
+	if !ok {
+		return nil, p.unexpected("a series or number")
+	}
+	switch t.kind {
+	case tokenNumber:
+		p.pos++
+		number, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return &numberExpr{number: number}, nil
+	case tokenIdent:
+		p.pos++
+		return p.parseSelector(t.text)
+	}
+	return nil, p.unexpected("a series or number")
+}
+
+func (p *parser) parseSelector(name string) (Expr, error) {
+	selector := &selectorExpr{name: name}
+	if _, ok := p.acceptOp("{"); !ok {
+		return selector, nil
+	}
+
+	for {
+		if _, ok := p.acceptOp("}"); ok {
+			return selector, nil
+		}
+
+		key, ok := p.peek()
+		if !ok || key.kind != tokenIdent {
+			return nil, p.unexpected("a tag name")
+		}
+		p.pos++
+
+		op, ok := p.acceptOp("=", "!=")
+		if !ok {
+			return nil, p.unexpected(`"=" or "!="`)
+		}
+
+		value, ok := p.peek()
+		if !ok || value.kind != tokenString {
+			return nil, p.unexpected("a quoted tag value")
+		}
+		p.pos++
+
+		selector.matchers = append(selector.matchers, matcher{key: key.text, value: value.text, equal: op == "="})
+
+		if _, ok := p.acceptOp(","); !ok {
+			return selector, p.expectOp("}")
+		}
+	}
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case ">", ">=", "<", "<=", "==", "!=":
+		return true
+	}
+	return false
+}
+
+func (e *numberExpr) eval(map[string][]sample) (value, error) {
+	return value{scalar: true, number: e.number}, nil
+}
+
+func (e *numberExpr) names(names []string) []string {
+	return names
+}
+
+func (e *selectorExpr) eval(series map[string][]sample) (value, error) {
+	vector := map[string]sample{}
+	for _, s := range series[e.name] {
+		if e.matches(s.tags) {
+			vector[labelKey(s.tags)] = s
+		}
+	}
+	return value{vector: vector}, nil
+}
+
+func (e *selectorExpr) matches(tags map[string]string) bool {
+	for _, m := range e.matchers {
+		if (tags[m.key] == m.value) != m.equal {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *selectorExpr) names(names []string) []string {
+	return append(names, e.name)
+}
+
+func (e *negateExpr) eval(series map[string][]sample) (value, error) {
+	v, err := e.expr.eval(series)
+	if err != nil {
+		return value{}, err
+	}
+	return apply(v, value{scalar: true, number: -1}, "*")
+}
+
+func (e *negateExpr) names(names []string) []string {
+	return e.expr.names(names)
+}
+
+func (e *binaryExpr) eval(series map[string][]sample) (value, error) {
+	left, err := e.left.eval(series)
+	if err != nil {
+		return value{}, err
+	}
+	right, err := e.right.eval(series)
+	if err != nil {
+		return value{}, err
+	}
+	return apply(left, right, e.op)
+}
+
+func (e *binaryExpr) names(names []string) []string {
+	return e.right.names(e.left.names(names))
+}
+
+// apply combines two values. Comparisons filter vectors, keeping the values
+// of the vector side, or the left side when both are vectors.
+func apply(left, right value, op string) (value, error) {
+	if left.scalar && right.scalar {
+		result, keep := compute(left.number, right.number, op)
+		if isComparison(op) {
+			// A scalar comparison holds for a single sample without labels.
+			vector := map[string]sample{}
+			if keep {
+				vector[""] = sample{tags: map[string]string{}, value: left.number}
+			}
+			return value{vector: vector}, nil
+		}
+		return value{scalar: true, number: result}, nil
+	}
+
+	vector := map[string]sample{}
+	switch {
+	case left.scalar:
+		for key, s := range right.vector {
+			result, keep := compute(left.number, s.value, op)
+			if isComparison(op) {
+				result = s.value
+			}
+			if keep {
+				vector[key] = sample{tags: s.tags, value: result}
+			}
+		}
+	case right.scalar:
+		for key, s := range left.vector {
+			result, keep := compute(s.value, right.number, op)
+			if isComparison(op) {
+				result = s.value
+			}
+			if keep {
+				vector[key] = sample{tags: s.tags, value: result}
+			}
+		}
+	default:
+		for key, l := range left.vector {
+			r, ok := right.vector[key]
+			if !ok {
+				continue
+			}
+			result, keep := compute(l.value, r.value, op)
+			if isComparison(op) {
+				result = l.value
+			}
+			if keep {
+				vector[key] = sample{tags: l.tags, value: result}
+			}
+		}
+	}
+
+	return value{vector: vector}, nil
+}
+
+// compute returns the result of an arithmetic operation, or whether a
+// comparison holds. Operations without a finite result are dropped.
+func compute(l, r float64, op string) (float64, bool) {
+	switch op {
+	case "+":
+		return l + r, true
+	case "-":
+		return l - r, true
+	case "*":
+		return l * r, true
+	case "/":
+		if r == 0 {
+			return 0, false
+		}
+		result := l / r
+		return result, !math.IsNaN(result) && !math.IsInf(result, 0)
+	case ">":
+		return 0, l > r
+	case ">=":
+		return 0, l >= r
+	case "<":
+		return 0, l < r
+	case "<=":
+		return 0, l <= r
+	case "==":
+		return 0, l == r
+	case "!=":
+		return 0, l != r
+	}
+	return 0, false
+}
+
+// labelKey identifies a label set independent of the order of its tags.
+func labelKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte(0xff)
+		b.WriteString(tags[k])
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
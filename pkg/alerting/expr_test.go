@@ -0,0 +1,160 @@
+package alerting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testSeries holds the samples of two containers, web and db.
+var testSeries = map[string][]sample{
+	"memory_usage": {
+		{tags: map[string]string{"container_name": "web"}, value: 90},
+		{tags: map[string]string{"container_name": "db"}, value: 40},
+	},
+	"memory_limit": {
+		{tags: map[string]string{"container_name": "web"}, value: 100},
+		{tags: map[string]string{"container_name": "db"}, value: 0},
+	},
+	"cpu_usage_rate": {
+		{tags: map[string]string{"container_name": "web"}, value: 10},
+	},
+}
+
+// evalExpr returns the samples an expression holds for as sorted
+// container=value strings.
+func evalExpr(t *testing.T, input string) string {
+	t.Helper()
+	expr, err := ParseExpr(input)
+	if err != nil {
+		t.Fatalf("%s: %v", input, err)
+	}
+	result, err := expr.eval(testSeries)
+	if err != nil {
+		t.Fatalf("%s: %v", input, err)
+	}
+
+	got := []string{}
+	for _, s := range result.vector {
+		got = append(got, fmt.Sprintf("%s=%g", s.tags["container_name"], s.value))
+	}
+	sort.Strings(got)
+	return strings.Join(got, ",")
+}
+
+func TestExprPrecedence(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "2 + 3 * 4 == 14", want: "=14"},
+		{expr: "(2 + 3) * 4 == 20", want: "=20"},
+		{expr: "10 - 4 - 3 == 3", want: "=3"},
+		{expr: "24 / 4 / 2 == 3", want: "=3"},
+		{expr: "-2 * 3 == -6", want: "=-6"},
+		{expr: "- -2 == 2", want: "=2"},
+		{expr: "1.5e2 == 150", want: "=150"},
+		{expr: "2 + 3 * 4 == 20", want: ""},
+		{expr: "memory_usage + cpu_usage_rate * 2 > 100", want: "web=110"},
+		{expr: "(memory_usage + cpu_usage_rate) * 2 > 100", want: "web=200"},
+		{expr: "memory_usage - cpu_usage_rate - 10 == 70", want: "web=70"},
+		{expr: "-memory_usage < -50", want: "web=-90"},
+	}
+
+	for _, tt := range tests {
+		if got := evalExpr(t, tt.expr); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExprVectors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		// Comparisons keep the value of the vector side.
+		{expr: "memory_usage > 50", want: "web=90"},
+		{expr: "50 < memory_usage", want: "web=90"},
+		{expr: "memory_usage >= 40", want: "db=40,web=90"},
+		{expr: "memory_usage != 40", want: "web=90"},
+		// Label sets are matched, db divides by zero and is dropped.
+		{expr: "memory_usage / memory_limit > 0.5", want: "web=0.9"},
+		{expr: "memory_usage / memory_limit >= 0", want: "web=0.9"},
+		// Samples without a match on the other side are dropped.
+		{expr: "memory_usage + cpu_usage_rate > 0", want: "web=100"},
+		// Comparing two vectors keeps the left values.
+		{expr: "memory_limit > memory_usage", want: "web=100"},
+		{expr: "unknown_series > 0", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := evalExpr(t, tt.expr); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExprLabelMatchers(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: `memory_usage{container_name="db"} > 0`, want: "db=40"},
+		{expr: `memory_usage{container_name!="db"} > 0`, want: "web=90"},
+		{expr: `memory_usage{container_name="web", container_name!="db"} > 0`, want: "web=90"},
+		{expr: `memory_usage{container_name="web",} > 0`, want: "web=90"},
+		{expr: `memory_usage{} > 0`, want: "db=40,web=90"},
+		// A missing tag matches the empty value.
+		{expr: `memory_usage{namespace=""} > 0`, want: "db=40,web=90"},
+		{expr: `memory_usage{namespace!=""} > 0`, want: ""},
+		{expr: `memory_usage{container_name="api"} > 0`, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := evalExpr(t, tt.expr); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExprNames(t *testing.T) {
+	expr, err := ParseExpr(`memory_usage{container_name="web"} / memory_limit > 0.9 * 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(expr.names(nil), ","); got != "memory_usage,memory_limit" {
+		t.Errorf("got names %s", got)
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{expr: "", err: "expected a series or number, got end of expression"},
+		{expr: "memory_usage", err: "expression must be a comparison"},
+		{expr: "memory_usage + 1", err: "expression must be a comparison"},
+		{expr: "(memory_usage > 1)", err: `expected ")", got ">"`},
+		{expr: "memory_usage > 1 > 2", err: `unexpected ">"`},
+		{expr: "memory_usage >", err: "expected a series or number, got end of expression"},
+		{expr: "(memory_usage + 1 > 2", err: `expected ")", got ">"`},
+		{expr: "memory_usage * > 2", err: `expected a series or number, got ">"`},
+		{expr: "memory_usage # 2", err: "unexpected character '#' at offset 13"},
+		{expr: `memory_usage{container_name="web} > 0`, err: "unterminated string at offset 28"},
+		{expr: `memory_usage{container_name=web} > 0`, err: `expected a quoted tag value, got "web"`},
+		{expr: `memory_usage{container_name} > 0`, err: `expected "=" or "!=", got "}"`},
+		{expr: `memory_usage{"web"} > 0`, err: `expected a tag name, got "web"`},
+		{expr: `memory_usage{container_name="web" > 0`, err: `expected "}", got ">"`},
+		{expr: "1.2.3 > 0", err: `invalid number "1.2.3"`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseExpr(tt.expr)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%q: got error %v, want %s", tt.expr, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+// Methods learning the baseline of a series.
+const (
+	// MethodEWMA tracks an exponentially weighted moving mean and variance.
+	MethodEWMA = "ewma"
+	// MethodRollingMAD compares samples to the median of a rolling window of
+	// the latest samples, scaled by its median absolute deviation. The window
+	// is not seasonal, a daily pattern is part of the baseline only when the
+	// window spans a day.
+	MethodRollingMAD = "rolling-mad"
+)
+
+const (
+	defaultThreshold = 3
+	defaultWindow    = 60
+	defaultWarmup    = 10
+	defaultMaxSeries = 10000
+	defaultSeriesTTL = time.Hour
+
+	// madScale turns a median absolute deviation into an estimate of the
+	// standard deviation of normally distributed samples.
+	madScale = 1.4826
+)
+
+// DefaultSeries are the series analyzed unless configured otherwise.
+// Cumulative series are analyzed as per second rates.
+var DefaultSeries = []string{
+	"cpu_usage_total",
+	"memory_working_set",
+	"rx_bytes",
+	"tx_bytes",
+	"disk_io_bytes",
+	"cpu_cfs_throttled_periods",
+}
+
+// Options configures a Detector.
+type Options struct {
+	// Method is MethodEWMA or MethodRollingMAD.
+	Method string
+	// Threshold is how many standard deviations a sample must be away from
+	// the baseline to be flagged.
+	Threshold float64
+	// Window is the number of samples the baseline covers, the size of the
+	// rolling window of MethodRollingMAD and the span of the EWMA, whose
+	// smoothing factor is 2/(Window+1).
+	Window int
+	// Warmup is the number of samples a series needs before it is flagged.
+	Warmup int
+	// Series names the analyzed series.
+	Series []string
+	// MaxSeries bounds the number of series with a baseline, samples of new
+	// series are ignored beyond it.
+	MaxSeries int
+	// SeriesTTL is how long the baseline of a series without samples is kept.
+	SeriesTTL time.Duration
+}
+
+// Detector learns a baseline per series and flags the samples deviating from
+// it. The memory held per series is bounded by the window. A nil Detector
+// flags nothing.
+type Detector struct {
+	options Options
+	alpha   float64
+	series  map[string]struct{}
+
+	mu        sync.Mutex
+	baselines map[string]*baseline
+}
+
+type baseline struct {
+	// The previous sample of cumulative series, from which rates are derived.
+	previous     float64
+	previousTime time.Time
+
+	count int
+
+	// MethodEWMA state.
+	mean     float64
+	variance float64
+
+	// MethodRollingMAD ring buffer of the latest samples.
+	window []float64
+	next   int
+
+	lastSeen time.Time
+}
+
+// NewDetector validates the options, filling in defaults for unset ones.
+func NewDetector(options Options) (*Detector, error) {
+	if options.Method != MethodEWMA && options.Method != MethodRollingMAD {
+		return nil, fmt.Errorf("anomaly detection method must be %s or %s, not %q", MethodEWMA, MethodRollingMAD, options.Method)
+	}
+	if options.Threshold == 0 {
+		options.Threshold = defaultThreshold
+	}
+	if options.Threshold < 0 {
+		return nil, fmt.Errorf("anomaly threshold must be positive")
+	}
+	if options.Window == 0 {
+		options.Window = defaultWindow
+	}
+	if options.Warmup == 0 {
+		options.Warmup = defaultWarmup
+	}
+	if options.Window < 2 || options.Warmup < 2 {
+		return nil, fmt.Errorf("anomaly window and warmup must hold at least 2 samples")
+	}
+	if options.Method == MethodRollingMAD && options.Warmup > options.Window {
+		return nil, fmt.Errorf("anomaly warmup of %d samples exceeds the window of %d", options.Warmup, options.Window)
+	}
+	if options.Series == nil {
+		options.Series = DefaultSeries
+	}
+	if options.MaxSeries <= 0 {
+		options.MaxSeries = defaultMaxSeries
+	}
+	if options.SeriesTTL <= 0 {
+		options.SeriesTTL = defaultSeriesTTL
+	}
+
+	series := map[string]struct{}{}
+	for _, name := range options.Series {
+		series[name] = struct{}{}
+	}
+
+	return &Detector{
+		options:   options,
+		alpha:     2 / (float64(options.Window) + 1),
+		series:    series,
+		baselines: map[string]*baseline{},
+	}, nil
+}
+
+// Detect feeds the samples of the analyzed series to their baselines and
+// returns those that deviate from them.
+func (d *Detector) Detect(points []*write.Point) []influx.Anomaly {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	anomalies := []influx.Anomaly{}
+	for _, point := range points {
+		if _, ok := d.series[point.Name()]; !ok {
+			continue
+		}
+
+		value, ok := pointValue(point)
+		if !ok {
+			continue
+		}
+
+		key := seriesKey(point)
+		b, ok := d.baselines[key]
+		if !ok {
+			if len(d.baselines) >= d.options.MaxSeries {
+				continue
+			}
+			b = &baseline{}
+			d.baselines[key] = b
+		}
+		b.lastSeen = now
+
+		value, ok = b.sample(point.Name(), value, point.Time())
+		if !ok {
+			continue
+		}
+
+		expected, deviation, ok := d.score(b, value)
+		d.update(b, value)
+		if !ok || math.Abs(deviation) < d.options.Threshold {
+			continue
+		}
+
+		tags := map[string]string{}
+		for _, tag := range point.TagList() {
+			tags[tag.Key] = tag.Value
+		}
+		anomalies = append(anomalies, influx.Anomaly{
+			Timestamp: point.Time(),
+			Series:    point.Name(),
+			Method:    d.options.Method,
+			Tags:      tags,
+			Value:     value,
+			Expected:  expected,
+			Deviation: deviation,
+		})
+	}
+
+	d.expire(now)
+
+	return anomalies
+}
+
+// sample returns the value analyzed for a point, the per second rate of
+// cumulative series. Repeated samples of the same time carry no information.
+func (b *baseline) sample(name string, value float64, at time.Time) (float64, bool) {
+	if !at.After(b.previousTime) {
+		return 0, false
+	}
+
+	previous, previousTime := b.previous, b.previousTime
+	b.previous, b.previousTime = value, at
+	if !influx.IsCumulative(name) {
+		return value, true
+	}
+
+	// The first sample and counter resets leave nothing to derive a rate from.
+	if previousTime.IsZero() || value < previous {
+		return 0, false
+	}
+	return (value - previous) / at.Sub(previousTime).Seconds(), true
+}
+
+// score returns the baseline of a series and how many standard deviations
+// value is away from it, once the series is warmed up and varies.
+func (d *Detector) score(b *baseline, value float64) (float64, float64, bool) {
+	if b.count < d.options.Warmup {
+		return 0, 0, false
+	}
+
+	var expected, deviation float64
+	switch d.options.Method {
+	case MethodEWMA:
+		expected, deviation = b.mean, math.Sqrt(b.variance)
+	case MethodRollingMAD:
+		expected = median(b.window)
+		absolute := make([]float64, len(b.window))
+		for i, v := range b.window {
+			absolute[i] = math.Abs(v - expected)
+		}
+		deviation = madScale * median(absolute)
+	}
+	if deviation == 0 {
+		return expected, 0, false
+	}
+
+	return expected, (value - expected) / deviation, true
+}
+
+func (d *Detector) update(b *baseline, value float64) {
+	b.count++
+
+	switch d.options.Method {
+	case MethodEWMA:
+		if b.count == 1 {
+			b.mean = value
+			return
+		}
+		diff := value - b.mean
+		increment := d.alpha * diff
+		b.mean += increment
+		b.variance = (1 - d.alpha) * (b.variance + diff*increment)
+	case MethodRollingMAD:
+		if len(b.window) < d.options.Window {
+			b.window = append(b.window, value)
+			return
+		}
+		b.window[b.next] = value
+		b.next = (b.next + 1) % len(b.window)
+	}
+}
+
+// expire forgets the baselines of series without samples within the ttl.
+func (d *Detector) expire(now time.Time) {
+	deadline := now.Add(-d.options.SeriesTTL)
+	for key, b := range d.baselines {
+		if b.lastSeen.Before(deadline) {
+			delete(d.baselines, key)
+		}
+	}
+}
+
+func pointValue(point *write.Point) (float64, bool) {
+	for _, field := range point.FieldList() {
+		if field.Key == influx.ValueField {
+			return influx.ToFloat(field.Value)
+		}
+	}
+	return 0, false
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// seriesKey identifies the series of a point by its measurement and tags.
+func seriesKey(point *write.Point) string {
+	tags := point.TagList()
+	keys := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		keys = append(keys, tag.Key+"\xff"+tag.Value)
+	}
+	sort.Strings(keys)
+
+	return point.Name() + "\xff" + strings.Join(keys, "\xff")
+}
@@ -0,0 +1,230 @@
+package anomaly
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+var testStart = time.Unix(1600000000, 0)
+
+func memoryPoint(container string, i int, value float64) *write.Point {
+	return write.NewPoint("memory_working_set", map[string]string{"container_name": container}, map[string]interface{}{"value": value}, testStart.Add(time.Duration(i)*10*time.Second))
+}
+
+func newTestDetector(t *testing.T, options Options) *Detector {
+	t.Helper()
+	d, err := NewDetector(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// warmUp feeds n samples alternating between 10 and 12 to the series of
+// container and fails when one is flagged.
+func warmUp(t *testing.T, d *Detector, container string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		value := 10.0
+		if i%2 == 1 {
+			value = 12
+		}
+		if anomalies := d.Detect([]*write.Point{memoryPoint(container, i, value)}); len(anomalies) != 0 {
+			t.Fatalf("sample %d flagged during the warm up: %+v", i, anomalies)
+		}
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	for _, method := range []string{MethodEWMA, MethodRollingMAD} {
+		d := newTestDetector(t, Options{Method: method, Window: 10, Warmup: 6})
+		warmUp(t, d, "/web", 5)
+		// The sixth sample is compared to a baseline of five samples.
+		if anomalies := d.Detect([]*write.Point{memoryPoint("/web", 5, 1000)}); len(anomalies) != 0 {
+			t.Errorf("%s: flagged the sixth sample before the warm up: %+v", method, anomalies)
+		}
+
+		d = newTestDetector(t, Options{Method: method, Window: 10, Warmup: 6})
+		warmUp(t, d, "/web", 6)
+		if anomalies := d.Detect([]*write.Point{memoryPoint("/web", 6, 1000)}); len(anomalies) != 1 {
+			t.Errorf("%s: got %d anomalies after the warm up, want 1", method, len(anomalies))
+		}
+	}
+}
+
+func TestEWMAThreshold(t *testing.T) {
+	tests := []struct {
+		name  string
+		sigma float64
+		want  bool
+	}{
+		{name: "at the mean", sigma: 0, want: false},
+		{name: "below the threshold", sigma: 2.9, want: false},
+		{name: "above the threshold", sigma: 3.1, want: true},
+		{name: "below the negative threshold", sigma: -3.1, want: true},
+		{name: "far away", sigma: 50, want: true},
+	}
+
+	for _, tt := range tests {
+		d := newTestDetector(t, Options{Method: MethodEWMA, Window: 10, Warmup: 10, Threshold: 3})
+		warmUp(t, d, "/web", 20)
+
+		b := d.baselines[seriesKey(memoryPoint("/web", 0, 0))]
+		mean, sd := b.mean, math.Sqrt(b.variance)
+		if mean < 10 || mean > 12 || sd <= 0 || sd > 2 {
+			t.Fatalf("baseline of mean %v and deviation %v after alternating 10 and 12", mean, sd)
+		}
+
+		value := mean + tt.sigma*sd
+		anomalies := d.Detect([]*write.Point{memoryPoint("/web", 20, value)})
+		if (len(anomalies) == 1) != tt.want {
+			t.Errorf("%s: got %+v, want flagged %v", tt.name, anomalies, tt.want)
+			continue
+		}
+		if tt.want {
+			a := anomalies[0]
+			if a.Method != MethodEWMA || a.Value != value || a.Expected != mean || math.Abs(a.Deviation-tt.sigma) > 1e-9 {
+				t.Errorf("%s: got %+v, want expected %v deviation %v", tt.name, a, mean, tt.sigma)
+			}
+		}
+	}
+}
+
+func TestRollingMADThreshold(t *testing.T) {
+	// The window of 10 and 12 has a median of 11 and a median absolute
+	// deviation of 1, a standard deviation of madScale.
+	tests := []struct {
+		value     float64
+		want      bool
+		deviation float64
+	}{
+		{value: 11, want: false},
+		{value: 15, want: false},
+		{value: 11 + 3*madScale - 0.01, want: false},
+		{value: 11 + 3*madScale + 0.01, want: true, deviation: (3*madScale + 0.01) / madScale},
+		{value: 0, want: true, deviation: -11 / madScale},
+	}
+
+	for _, tt := range tests {
+		d := newTestDetector(t, Options{Method: MethodRollingMAD, Window: 10, Warmup: 10, Threshold: 3})
+		warmUp(t, d, "/web", 20)
+
+		anomalies := d.Detect([]*write.Point{memoryPoint("/web", 20, tt.value)})
+		if (len(anomalies) == 1) != tt.want {
+			t.Errorf("%v: got %+v, want flagged %v", tt.value, anomalies, tt.want)
+			continue
+		}
+		if tt.want {
+			a := anomalies[0]
+			if a.Method != MethodRollingMAD || a.Expected != 11 || math.Abs(a.Deviation-tt.deviation) > 1e-9 {
+				t.Errorf("%v: got %+v, want expected 11 deviation %v", tt.value, a, tt.deviation)
+			}
+		}
+	}
+}
+
+func TestRollingMADWindow(t *testing.T) {
+	d := newTestDetector(t, Options{Method: MethodRollingMAD, Window: 4, Warmup: 4})
+	warmUp(t, d, "/web", 4)
+
+	// A level shift is flagged until it fills the window.
+	flagged := 0
+	for i := 4; i < 12; i++ {
+		flagged += len(d.Detect([]*write.Point{memoryPoint("/web", i, 100+float64(i%2))}))
+	}
+	if flagged == 0 || flagged > 3 {
+		t.Errorf("flagged %d samples of the level shift, want the first ones until the window holds it", flagged)
+	}
+	b := d.baselines[seriesKey(memoryPoint("/web", 0, 0))]
+	if len(b.window) != 4 {
+		t.Errorf("window holds %d samples, want 4", len(b.window))
+	}
+}
+
+func TestDetectSkips(t *testing.T) {
+	d := newTestDetector(t, Options{Method: MethodEWMA, Window: 10, Warmup: 2, MaxSeries: 1})
+
+	constant := []*write.Point{}
+	for i := 0; i < 10; i++ {
+		constant = append(constant, memoryPoint("/web", i, 10))
+	}
+	// A constant series has no deviation to compare to.
+	if anomalies := d.Detect(constant); len(anomalies) != 0 {
+		t.Errorf("flagged a constant series: %+v", anomalies)
+	}
+	// Repeated timestamps carry no information.
+	if anomalies := d.Detect([]*write.Point{memoryPoint("/web", 9, 1000)}); len(anomalies) != 0 {
+		t.Errorf("flagged a repeated sample: %+v", anomalies)
+	}
+	// Series beyond MaxSeries get no baseline.
+	for i := 0; i < 10; i++ {
+		d.Detect([]*write.Point{memoryPoint("/db", i, float64(i%2))})
+	}
+	if len(d.baselines) != 1 {
+		t.Errorf("got %d baselines, want MaxSeries 1", len(d.baselines))
+	}
+	// Unlisted series are ignored.
+	other := write.NewPoint("memory_usage", nil, map[string]interface{}{"value": 1.0}, testStart)
+	d.Detect([]*write.Point{other})
+	if len(d.baselines) != 1 {
+		t.Errorf("got %d baselines after an unlisted series", len(d.baselines))
+	}
+}
+
+func TestDetectRates(t *testing.T) {
+	d := newTestDetector(t, Options{Method: MethodRollingMAD, Window: 10, Warmup: 10, Series: []string{"cpu_usage_total"}})
+
+	// The counter grows by 100 or 120 every 10s, a rate of 10 or 12.
+	counter := 0.0
+	cpuPoint := func(i int) *write.Point {
+		return write.NewPoint("cpu_usage_total", nil, map[string]interface{}{"value": counter}, testStart.Add(time.Duration(i)*10*time.Second))
+	}
+	for i := 0; i < 21; i++ {
+		if i > 0 {
+			counter += 100 + 20*float64(i%2)
+		}
+		if anomalies := d.Detect([]*write.Point{cpuPoint(i)}); len(anomalies) != 0 {
+			t.Fatalf("sample %d flagged: %+v", i, anomalies)
+		}
+	}
+
+	// A reset leaves no rate to analyze.
+	counter = 0
+	if anomalies := d.Detect([]*write.Point{cpuPoint(21)}); len(anomalies) != 0 {
+		t.Errorf("flagged a counter reset: %+v", anomalies)
+	}
+
+	counter += 1000
+	anomalies := d.Detect([]*write.Point{cpuPoint(22)})
+	if len(anomalies) != 1 || anomalies[0].Value != 100 || anomalies[0].Expected != 11 {
+		t.Errorf("got %+v, want the rate 100 flagged against 11", anomalies)
+	}
+}
+
+func TestNewDetectorRejects(t *testing.T) {
+	tests := []struct {
+		options Options
+		err     string
+	}{
+		{options: Options{Method: "mad"}, err: "anomaly detection method must be"},
+		{options: Options{Method: MethodEWMA, Threshold: -1}, err: "anomaly threshold must be positive"},
+		{options: Options{Method: MethodEWMA, Window: 1}, err: "at least 2 samples"},
+		{options: Options{Method: MethodRollingMAD, Window: 5, Warmup: 10}, err: "exceeds the window"},
+	}
+
+	for _, tt := range tests {
+		_, err := NewDetector(tt.options)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%+v: got error %v, want %s", tt.options, err, tt.err)
+		}
+	}
+
+	d := newTestDetector(t, Options{Method: MethodEWMA})
+	if d.options.Threshold != defaultThreshold || d.options.Window != defaultWindow || d.options.Warmup != defaultWarmup || len(d.options.Series) != len(DefaultSeries) {
+		t.Errorf("got options %+v, want the defaults", d.options)
+	}
+}
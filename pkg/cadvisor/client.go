@@ -6,16 +6,19 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	// Register supported container handlers.
 	_ "github.com/google/cadvisor/container/containerd/install"
 	_ "github.com/google/cadvisor/container/crio/install"
+	_ "github.com/google/cadvisor/container/docker/install"
 	_ "github.com/google/cadvisor/container/systemd/install"
 
 	"github.com/google/cadvisor/cache/memory"
 	cadvisormetrics "github.com/google/cadvisor/container"
 	"github.com/google/cadvisor/events"
+	cadvisorfs "github.com/google/cadvisor/fs"
 	cadvisorapi "github.com/google/cadvisor/info/v1"
 	cadvisorapiv2 "github.com/google/cadvisor/info/v2"
 	"github.com/google/cadvisor/manager"
@@ -27,6 +30,27 @@ type cadvisorClient struct {
 	imageFsInfoProvider ImageFsInfoProvider
 	rootPath            string
 	man                 manager.Manager
+	includedMetrics     cadvisormetrics.MetricSet
+}
+
+// Container runtime sockets, used to pick the image filesystem label.
+const (
+	DockerSocketSuffix     = "docker.sock"
+	CrioSocketSuffix       = "crio.sock"
+	ContainerdSocketSuffix = "containerd.sock"
+)
+
+// LabelContainerdImages identifies the containerd image filesystem. Unlike the
+// docker and cri-o labels it is not known to cAdvisor.
+const LabelContainerdImages = "containerd-images"
+
+const containerdRootDir = "/var/lib/containerd"
+const containerdSnapshotterDir = containerdRootDir + "/io.containerd.snapshotter.v1.overlayfs"
+
+var defaultRuntimeEndpoints = []string{
+	"unix:///var/run/docker.sock",
+	"unix:///run/containerd/containerd.sock",
+	"unix:///var/run/crio/crio.sock",
 }
 
 const statsCacheDuration = 2 * time.Minute
@@ -39,7 +63,8 @@ func init() {
 	flagOverrides := map[string]string{
 		// Override the default cAdvisor housekeeping interval.
 		"housekeeping_interval": defaultHousekeepingInterval.String(),
-		// Disable event storage by default.
+		// Disable event storage by default. Events are consumed through
+		// WatchEvents, which does not depend on the in-memory event store.
 		"event_storage_event_limit": "default=0",
 		"event_storage_age_limit":   "default=0",
 	}
@@ -82,32 +107,34 @@ type Interface interface {
 
 	// Get filesystem information for the filesystem that contains the given file.
 	//	GetDirFsInfo(path string) (cadvisorapiv2.FsInfo, error)
+
+	// Returns the metric sets being collected.
+	IncludedMetrics() cadvisormetrics.MetricSet
 }
 
 // New creates a new cAdvisor Interface for linux systems.
-func New(imageFsInfoProvider ImageFsInfoProvider, rootPath string, cgroupRoots []string, usingLegacyStats bool) (Interface, error) {
+func New(imageFsInfoProvider ImageFsInfoProvider, rootPath string, cgroupRoots []string, options Options) (Interface, error) {
 	sysFs := sysfs.NewRealSysFs()
 
-	includedMetrics := cadvisormetrics.MetricSet{
-		cadvisormetrics.CpuUsageMetrics:     struct{}{},
-		cadvisormetrics.MemoryUsageMetrics:  struct{}{},
-		cadvisormetrics.CpuLoadMetrics:      struct{}{},
-		cadvisormetrics.DiskIOMetrics:       struct{}{},
-		cadvisormetrics.NetworkUsageMetrics: struct{}{},
-		cadvisormetrics.AppMetrics:          struct{}{},
-		cadvisormetrics.ProcessMetrics:      struct{}{},
+	err := options.Validate()
+	if err != nil {
+		return nil, err
 	}
 
-	// includedMetrics[cadvisormetrics.DiskUsageMetrics] = struct{}{}
+	includedMetrics, err := options.MetricSet()
+	if err != nil {
+		return nil, err
+	}
 
-	duration := maxHousekeepingInterval
+	*manager.HousekeepingInterval = options.HousekeepingInterval
+	duration := options.MaxHousekeepingInterval
 	housekeepingConfig := manager.HouskeepingConfig{
 		Interval:     &duration,
-		AllowDynamic: pointer.BoolPtr(allowDynamicHousekeeping),
+		AllowDynamic: pointer.BoolPtr(options.AllowDynamicHousekeeping),
 	}
 
 	// Create the cAdvisor container manager.
-	m, err := manager.New(memory.New(statsCacheDuration, nil), sysFs, housekeepingConfig, includedMetrics, http.DefaultClient, cgroupRoots, []string{}, "", time.Second)
+	m, err := manager.New(memory.New(options.StatsCacheDuration, nil), sysFs, housekeepingConfig, includedMetrics, http.DefaultClient, cgroupRoots, []string{}, options.PerfEventsFile, time.Second)
 	if err != nil {
 		return nil, err
 	}
@@ -126,6 +153,7 @@ func New(imageFsInfoProvider ImageFsInfoProvider, rootPath string, cgroupRoots [
 		imageFsInfoProvider: imageFsInfoProvider,
 		rootPath:            rootPath,
 		man:                 m,
+		includedMetrics:     includedMetrics,
 	}, nil
 }
 
@@ -167,6 +195,17 @@ func (cc *cadvisorClient) ImagesFsInfo() ([]cadvisorapiv2.FsInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// cAdvisor does not label the containerd image filesystem, resolve it
+	// through the snapshotter directory instead.
+	if label == LabelContainerdImages {
+		fsInfo, err := cc.man.GetDirFsInfo(containerdImagesDir())
+		if err != nil {
+			return nil, err
+		}
+		return []cadvisorapiv2.FsInfo{fsInfo}, nil
+	}
+
 	return cc.man.GetFsInfo(label)
 }
 
@@ -190,6 +229,10 @@ func (cc *cadvisorClient) getFsInfo(label string) (cadvisorapiv2.FsInfo, error)
 	return res[0], nil
 }
 
+func (cc *cadvisorClient) IncludedMetrics() cadvisormetrics.MetricSet {
+	return cc.includedMetrics
+}
+
 func (cc *cadvisorClient) WatchEvents(request *events.Request) (*events.EventChannel, error) {
 	return cc.man.WatchForEvents(request)
 }
@@ -203,10 +246,37 @@ type imageFsInfoProvider struct {
 // ImageFsInfoLabel returns the image fs label for the configured runtime.
 // For remote runtimes, it handles additional runtimes natively understood by cAdvisor.
 func (i *imageFsInfoProvider) ImageFsInfoLabel() (string, error) {
-	return "", fmt.Errorf("no imagefs label for configured runtime")
+	switch {
+	case strings.HasSuffix(i.runtimeEndpoint, DockerSocketSuffix):
+		return cadvisorfs.LabelDockerImages, nil
+	case strings.HasSuffix(i.runtimeEndpoint, CrioSocketSuffix):
+		return cadvisorfs.LabelCrioImages, nil
+	case strings.HasSuffix(i.runtimeEndpoint, ContainerdSocketSuffix):
+		return LabelContainerdImages, nil
+	}
+	return "", fmt.Errorf("no imagefs label for configured runtime %q", i.runtimeEndpoint)
 }
 
 // NewImageFsInfoProvider returns a provider for the specified runtime configuration.
 func NewImageFsInfoProvider(runtimeEndpoint string) ImageFsInfoProvider {
 	return &imageFsInfoProvider{runtimeEndpoint: runtimeEndpoint}
 }
+
+// DetectRuntimeEndpoint returns the first well known container runtime socket
+// present on the host, or an empty string when there is none.
+func DetectRuntimeEndpoint() string {
+	for _, endpoint := range defaultRuntimeEndpoints {
+		if _, err := os.Stat(strings.TrimPrefix(endpoint, "unix://")); err == nil {
+			return endpoint
+		}
+	}
+	return ""
+}
+
+// containerdImagesDir returns the directory holding unpacked containerd images.
+func containerdImagesDir() string {
+	if _, err := os.Stat(containerdSnapshotterDir); err == nil {
+		return containerdSnapshotterDir
+	}
+	return containerdRootDir
+}
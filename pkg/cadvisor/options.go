@@ -0,0 +1,119 @@
+package cadvisor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	cadvisormetrics "github.com/google/cadvisor/container"
+)
+
+// DefaultMetrics are the metric sets collected when none are configured. Disk
+// usage walks the filesystem of every container and must be enabled
+// explicitly.
+var DefaultMetrics = []string{
+	string(cadvisormetrics.CpuUsageMetrics),
+	string(cadvisormetrics.MemoryUsageMetrics),
+	string(cadvisormetrics.CpuLoadMetrics),
+	string(cadvisormetrics.DiskIOMetrics),
+	string(cadvisormetrics.NetworkUsageMetrics),
+	string(cadvisormetrics.AppMetrics),
+	string(cadvisormetrics.ProcessMetrics),
+}
+
+// Metric sets that only make sense together with another one.
+var metricDependencies = map[cadvisormetrics.MetricKind]cadvisormetrics.MetricKind{
+	cadvisormetrics.PerCpuUsageMetrics:             cadvisormetrics.CpuUsageMetrics,
+	cadvisormetrics.CpuLoadMetrics:                 cadvisormetrics.CpuUsageMetrics,
+	cadvisormetrics.MemoryNumaMetrics:              cadvisormetrics.MemoryUsageMetrics,
+	cadvisormetrics.ReferencedMemoryMetrics:        cadvisormetrics.MemoryUsageMetrics,
+	cadvisormetrics.NetworkTcpUsageMetrics:         cadvisormetrics.NetworkUsageMetrics,
+	cadvisormetrics.NetworkAdvancedTcpUsageMetrics: cadvisormetrics.NetworkUsageMetrics,
+	cadvisormetrics.NetworkUdpUsageMetrics:         cadvisormetrics.NetworkUsageMetrics,
+}
+
+// Options configures which metrics cadvisor collects and how often.
+type Options struct {
+	// Metrics names the metric sets to collect, e.g. cpu, memory, disk, tcp,
+	// hugetlb, perf_event, resctrl, cpu_topology or oom_event.
+	Metrics []string
+	// HousekeepingInterval is how often container stats are collected.
+	HousekeepingInterval time.Duration
+	// MaxHousekeepingInterval bounds the interval when dynamic housekeeping
+	// backs off for containers whose stats do not change.
+	MaxHousekeepingInterval  time.Duration
+	AllowDynamicHousekeeping bool
+	// StatsCacheDuration is how long collected stats are kept in memory.
+	StatsCacheDuration time.Duration
+	// PerfEventsFile is the perf events configuration required by perf_event.
+	PerfEventsFile string
+}
+
+// DefaultOptions returns the options stalker runs with unless configured otherwise.
+func DefaultOptions() Options {
+	return Options{
+		Metrics:                  DefaultMetrics,
+		HousekeepingInterval:     defaultHousekeepingInterval,
+		MaxHousekeepingInterval:  maxHousekeepingInterval,
+		AllowDynamicHousekeeping: allowDynamicHousekeeping,
+		StatsCacheDuration:       statsCacheDuration,
+	}
+}
+
+// MetricSet parses the configured metric set names.
+func (o Options) MetricSet() (cadvisormetrics.MetricSet, error) {
+	metrics := cadvisormetrics.MetricSet{}
+	err := metrics.Set(strings.Join(o.Metrics, ","))
+	if err != nil {
+		return nil, fmt.Errorf("%w, supported metrics are %s", err, cadvisormetrics.AllMetrics.String())
+	}
+
+	return metrics, nil
+}
+
+// Validate checks that the metric sets exist, that their dependencies are
+// enabled and that the intervals are consistent.
+func (o Options) Validate() error {
+	metrics, err := o.MetricSet()
+	if err != nil {
+		return err
+	}
+	if len(metrics) == 0 {
+		return fmt.Errorf("no metrics enabled")
+	}
+
+	missing := []string{}
+	for kind, dependency := range metricDependencies {
+		if metrics.Has(kind) && !metrics.Has(dependency) {
+			missing = append(missing, fmt.Sprintf("%s requires %s", kind, dependency))
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		return fmt.Errorf("invalid metrics: %s", strings.Join(missing, ", "))
+	}
+
+	if metrics.Has(cadvisormetrics.PerfMetrics) && o.PerfEventsFile == "" {
+		return fmt.Errorf("%s requires a perf events configuration file", cadvisormetrics.PerfMetrics)
+	}
+	if !metrics.Has(cadvisormetrics.PerfMetrics) && o.PerfEventsFile != "" {
+		return fmt.Errorf("a perf events configuration file is set but %s is not enabled", cadvisormetrics.PerfMetrics)
+	}
+
+	if o.HousekeepingInterval <= 0 {
+		return fmt.Errorf("housekeeping interval must be positive")
+	}
+	if o.MaxHousekeepingInterval < o.HousekeepingInterval {
+		return fmt.Errorf("max housekeeping interval %s is shorter than the housekeeping interval %s",
+			o.MaxHousekeepingInterval, o.HousekeepingInterval)
+	}
+	// Instantaneous values are derived from the two latest samples, both must
+	// still be cached.
+	if o.StatsCacheDuration < 2*o.MaxHousekeepingInterval {
+		return fmt.Errorf("stats cache duration %s must hold at least two samples at the max housekeeping interval %s",
+			o.StatsCacheDuration, o.MaxHousekeepingInterval)
+	}
+
+	return nil
+}
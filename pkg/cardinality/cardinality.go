@@ -0,0 +1,245 @@
+package cardinality
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+// DefaultSeriesTTL is how long a series is kept without receiving points
+// before it no longer counts against the budget of its container.
+const DefaultSeriesTTL = time.Hour
+
+// Tracker counts the series every container produces and enforces a
+// per-container series budget.
+type Tracker struct {
+	budget int
+	ttl    time.Duration
+
+	mu         sync.Mutex
+	containers map[string]*container
+}
+
+type container struct {
+	name          string
+	series        map[string]*series
+	droppedPoints int64
+	overBudget    bool
+	lastSeen      time.Time
+}
+
+type series struct {
+	tags     []*tag
+	lastSeen time.Time
+}
+
+type tag struct {
+	key   string
+	value string
+}
+
+// NewTracker creates a tracker allowing budget series per container, 0
+// tracks series without limiting them. A ttl of 0 uses DefaultSeriesTTL.
+func NewTracker(budget int, ttl time.Duration) *Tracker {
+	if ttl <= 0 {
+		ttl = DefaultSeriesTTL
+	}
+
+	return &Tracker{
+		budget:     budget,
+		ttl:        ttl,
+		containers: map[string]*container{},
+	}
+}
+
+// Admit returns the points of a container that fit its series budget. Points
+// of known series are always admitted, new series are admitted until the
+// budget is exhausted and dropped afterwards.
+func (t *Tracker) Admit(cgroup string, points []*write.Point) []*write.Point {
+	if t == nil {
+		return points
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	c, ok := t.containers[cgroup]
+	if !ok {
+		c = &container{series: map[string]*series{}}
+		t.containers[cgroup] = c
+	}
+	c.lastSeen = now
+
+	admitted := make([]*write.Point, 0, len(points))
+	dropped := 0
+	for _, point := range points {
+		key, tags := seriesKey(point)
+		s, ok := c.series[key]
+		if !ok {
+			if t.budget > 0 && len(c.series) >= t.budget {
+				dropped++
+				continue
+			}
+			s = &series{tags: tags}
+			c.series[key] = s
+			if name := tagValue(tags, influx.TagContainerName); name != "" {
+				c.name = name
+			}
+		}
+		s.lastSeen = now
+		admitted = append(admitted, point)
+	}
+
+	if dropped > 0 && !c.overBudget {
+		log.Printf("container %s exceeded its budget of %d series, dropping new series", cgroup, t.budget)
+	}
+	c.droppedPoints += int64(dropped)
+	c.overBudget = dropped > 0
+
+	return admitted
+}
+
+// Expire forgets series and containers that received no points within the ttl.
+func (t *Tracker) Expire() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	deadline := time.Now().Add(-t.ttl)
+	for cgroup, c := range t.containers {
+		if c.lastSeen.Before(deadline) {
+			delete(t.containers, cgroup)
+			continue
+		}
+		for key, s := range c.series {
+			if s.lastSeen.Before(deadline) {
+				delete(c.series, key)
+			}
+		}
+	}
+}
+
+// Report describes the series tracked per container.
+type Report struct {
+	SeriesBudget int
+	Containers   []ContainerReport
+}
+
+// ContainerReport describes the series of one container.
+type ContainerReport struct {
+	Cgroup        string
+	ContainerName string
+	Series        int
+	DroppedPoints int64
+	OverBudget    bool
+	Tags          []TagReport
+}
+
+// TagReport is the number of distinct values of a tag within a container.
+type TagReport struct {
+	Name   string
+	Values int
+}
+
+// Top returns the limit containers with the most series, highest first.
+func (t *Tracker) Top(limit int) Report {
+	report := Report{Containers: []ContainerReport{}}
+	if t == nil {
+		return report
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report.SeriesBudget = t.budget
+	for cgroup, c := range t.containers {
+		report.Containers = append(report.Containers, ContainerReport{
+			Cgroup:        cgroup,
+			ContainerName: c.name,
+			Series:        len(c.series),
+			DroppedPoints: c.droppedPoints,
+			OverBudget:    c.overBudget,
+		})
+	}
+
+	sort.Slice(report.Containers, func(i, j int) bool {
+		a, b := report.Containers[i], report.Containers[j]
+		if a.Series != b.Series {
+			return a.Series > b.Series
+		}
+		return a.Cgroup < b.Cgroup
+	})
+	if limit > 0 && len(report.Containers) > limit {
+		report.Containers = report.Containers[:limit]
+	}
+
+	// Tag breakdowns are only computed for the reported containers.
+	for i := range report.Containers {
+		report.Containers[i].Tags = tagReports(t.containers[report.Containers[i].Cgroup])
+	}
+
+	return report
+}
+
+func tagReports(c *container) []TagReport {
+	values := map[string]map[string]struct{}{}
+	for _, s := range c.series {
+		for _, tag := range s.tags {
+			if values[tag.key] == nil {
+				values[tag.key] = map[string]struct{}{}
+			}
+			values[tag.key][tag.value] = struct{}{}
+		}
+	}
+
+	reports := []TagReport{}
+	for name, v := range values {
+		reports = append(reports, TagReport{Name: name, Values: len(v)})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Values != reports[j].Values {
+			return reports[i].Values > reports[j].Values
+		}
+		return reports[i].Name < reports[j].Name
+	})
+	return reports
+}
+
+// seriesKey identifies the series of a point by its measurement and tags.
+func seriesKey(point *write.Point) (string, []*tag) {
+	tags := []*tag{}
+	for _, t := range point.TagList() {
+		tags = append(tags, &tag{key: t.Key, value: t.Value})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].key < tags[j].key
+	})
+
+	var b strings.Builder
+	b.WriteString(point.Name())
+	for _, t := range tags {
+		b.WriteByte(0xff)
+		b.WriteString(t.key)
+		b.WriteByte(0xff)
+		b.WriteString(t.value)
+	}
+	return b.String(), tags
+}
+
+func tagValue(tags []*tag, key string) string {
+	for _, t := range tags {
+		if t.key == key {
+			return t.value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,4505 @@
+// Package client provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+)
+
+// Defines values for AlertState.
+const (
+	Firing   AlertState = "firing"
+	Pending  AlertState = "pending"
+	Resolved AlertState = "resolved"
+)
+
+// Defines values for AnomalyMethod.
+const (
+	Ewma       AnomalyMethod = "ewma"
+	RollingMad AnomalyMethod = "rolling-mad"
+)
+
+// Alert defines model for alert.
+type Alert struct {
+	// when the rule started to hold
+	ActiveAt    time.Time          `json:"activeAt"`
+	Annotations *Alert_Annotations `json:"annotations,omitempty"`
+	Expr        string             `json:"expr"`
+	FiredAt     *time.Time         `json:"firedAt,omitempty"`
+
+	// tags of the series the rule matched, merged with the labels of the rule
+	Labels     Alert_Labels `json:"labels"`
+	ResolvedAt *time.Time   `json:"resolvedAt,omitempty"`
+	Rule       string       `json:"rule"`
+	State      AlertState   `json:"state"`
+
+	// value of the compared series on the latest scrape the rule held
+	Value float64 `json:"value"`
+}
+
+// Alert_Annotations defines model for Alert.Annotations.
+type Alert_Annotations struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// tags of the series the rule matched, merged with the labels of the rule
+type Alert_Labels struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// AlertState defines model for Alert.State.
+type AlertState string
+
+// AlertList defines model for alertList.
+type AlertList struct {
+	Alerts []Alert `json:"alerts"`
+}
+
+// Anomaly defines model for anomaly.
+type Anomaly struct {
+	ContainerName *string `json:"containerName,omitempty"`
+
+	// distance between value and baseline in standard deviations
+	Deviation float64 `json:"deviation"`
+
+	// baseline of the series when the value was observed
+	Expected float64       `json:"expected"`
+	Method   AnomalyMethod `json:"method"`
+
+	// series name, analyzed as a per second rate when the series is cumulative
+	Series    string        `json:"series"`
+	Tags      *Anomaly_Tags `json:"tags,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+
+	// observed value
+	Value float64 `json:"value"`
+}
+
+// AnomalyMethod defines model for Anomaly.Method.
+type AnomalyMethod string
+
+// Anomaly_Tags defines model for Anomaly.Tags.
+type Anomaly_Tags struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// AnomalyList defines model for anomalyList.
+type AnomalyList struct {
+	Anomalies []Anomaly `json:"anomalies"`
+}
+
+// Backup defines model for backup.
+type Backup struct {
+	// directory holding the backups
+	Path      string    `json:"path"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CardinalityReport defines model for cardinalityReport.
+type CardinalityReport struct {
+	Containers []ContainerCardinality `json:"containers"`
+
+	// maximum number of series per container, 0 when unlimited
+	SeriesBudget int `json:"seriesBudget"`
+}
+
+// Container defines model for container.
+type Container struct {
+	Aliases   *[]string         `json:"aliases,omitempty"`
+	Cgroup    string            `json:"cgroup"`
+	CreatedAt *time.Time        `json:"createdAt,omitempty"`
+	Image     *string           `json:"image,omitempty"`
+	Labels    *Container_Labels `json:"labels,omitempty"`
+
+	// first alias of the container, or its cgroup when it has none
+	Name string `json:"name"`
+}
+
+// Container_Labels defines model for Container.Labels.
+type Container_Labels struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// ContainerCardinality defines model for containerCardinality.
+type ContainerCardinality struct {
+	Cgroup        string  `json:"cgroup"`
+	ContainerName *string `json:"containerName,omitempty"`
+
+	// points dropped because the container exceeded the series budget
+	DroppedPoints int64 `json:"droppedPoints"`
+
+	// whether points were dropped on the latest scrape
+	OverBudget bool `json:"overBudget"`
+
+	// number of series currently tracked for the container
+	Series int `json:"series"`
+
+	// distinct values per tag, highest first
+	Tags *[]TagCardinality `json:"tags,omitempty"`
+}
+
+// ContainerList defines model for containerList.
+type ContainerList struct {
+	Containers []Container `json:"containers"`
+}
+
+// ContainerStats defines model for containerStats.
+type ContainerStats struct {
+	Cpu       *CpuStats    `json:"cpu,omitempty"`
+	Memory    *MemoryStats `json:"memory,omitempty"`
+	Name      string       `json:"name"`
+	Rootfs    *FsStats     `json:"rootfs,omitempty"`
+	StartTime *time.Time   `json:"startTime,omitempty"`
+}
+
+// CpuCore defines model for cpuCore.
+type CpuCore struct {
+	Id       int    `json:"id"`
+	SocketId int    `json:"socketId"`
+	Threads  *[]int `json:"threads,omitempty"`
+}
+
+// CpuStats defines model for cpuStats.
+type CpuStats struct {
+	Time                 time.Time `json:"time"`
+	UsageCoreNanoSeconds *int64    `json:"usageCoreNanoSeconds,omitempty"`
+	UsageNanoCores       *int64    `json:"usageNanoCores,omitempty"`
+}
+
+// Event defines model for event.
+type Event struct {
+	ContainerName string `json:"containerName"`
+
+	// process id of the process killed by an oomKill event
+	Pid *int64 `json:"pid,omitempty"`
+
+	// name of the process killed by an oomKill event
+	ProcessName *string     `json:"processName,omitempty"`
+	Tags        *Event_Tags `json:"tags,omitempty"`
+	Timestamp   time.Time   `json:"timestamp"`
+	Type        string      `json:"type"`
+}
+
+// Event_Tags defines model for Event.Tags.
+type Event_Tags struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// EventList defines model for eventList.
+type EventList struct {
+	Events *[]Event `json:"events,omitempty"`
+}
+
+// FsInfo defines model for fsInfo.
+type FsInfo struct {
+	Available  int64      `json:"available"`
+	Capacity   int64      `json:"capacity"`
+	Device     string     `json:"device"`
+	Inodes     *int64     `json:"inodes,omitempty"`
+	InodesFree *int64     `json:"inodesFree,omitempty"`
+	Labels     *[]string  `json:"labels,omitempty"`
+	Mountpoint string     `json:"mountpoint"`
+	Timestamp  *time.Time `json:"timestamp,omitempty"`
+	Usage      int64      `json:"usage"`
+}
+
+// FsInfoList defines model for fsInfoList.
+type FsInfoList struct {
+	Filesystems *[]FsInfo `json:"filesystems,omitempty"`
+}
+
+// FsStats defines model for fsStats.
+type FsStats struct {
+	AvailableBytes *int64    `json:"availableBytes,omitempty"`
+	CapacityBytes  *int64    `json:"capacityBytes,omitempty"`
+	Inodes         *int64    `json:"inodes,omitempty"`
+	InodesFree     *int64    `json:"inodesFree,omitempty"`
+	InodesUsed     *int64    `json:"inodesUsed,omitempty"`
+	Time           time.Time `json:"time"`
+	UsedBytes      *int64    `json:"usedBytes,omitempty"`
+}
+
+// ImportResult defines model for importResult.
+type ImportResult struct {
+	// number of points stored
+	Points int64 `json:"points"`
+}
+
+// InterfaceStats defines model for interfaceStats.
+type InterfaceStats struct {
+	Name     string `json:"name"`
+	RxBytes  *int64 `json:"rxBytes,omitempty"`
+	RxErrors *int64 `json:"rxErrors,omitempty"`
+	TxBytes  *int64 `json:"txBytes,omitempty"`
+	TxErrors *int64 `json:"txErrors,omitempty"`
+}
+
+// MachineFilesystem defines model for machineFilesystem.
+type MachineFilesystem struct {
+	Capacity int64   `json:"capacity"`
+	Device   string  `json:"device"`
+	Inodes   *int64  `json:"inodes,omitempty"`
+	Type     *string `json:"type,omitempty"`
+}
+
+// MachineInfo defines model for machineInfo.
+type MachineInfo struct {
+	BootId           *string              `json:"bootId,omitempty"`
+	CloudProvider    *string              `json:"cloudProvider,omitempty"`
+	CpuFrequencyKhz  int64                `json:"cpuFrequencyKhz"`
+	CpuVendorId      *string              `json:"cpuVendorId,omitempty"`
+	Filesystems      *[]MachineFilesystem `json:"filesystems,omitempty"`
+	InstanceType     *string              `json:"instanceType,omitempty"`
+	MachineId        *string              `json:"machineId,omitempty"`
+	MemoryCapacity   int64                `json:"memoryCapacity"`
+	NetworkDevices   *[]NetworkDevice     `json:"networkDevices,omitempty"`
+	NumCores         int                  `json:"numCores"`
+	NumPhysicalCores int                  `json:"numPhysicalCores"`
+	NumSockets       int                  `json:"numSockets"`
+	NumaNodes        int                  `json:"numaNodes"`
+	SystemUuid       *string              `json:"systemUuid,omitempty"`
+	Timestamp        *time.Time           `json:"timestamp,omitempty"`
+	Topology         *[]NumaNode          `json:"topology,omitempty"`
+}
+
+// MemoryStats defines model for memoryStats.
+type MemoryStats struct {
+	AvailableBytes  *int64    `json:"availableBytes,omitempty"`
+	MajorPageFaults *int64    `json:"majorPageFaults,omitempty"`
+	PageFaults      *int64    `json:"pageFaults,omitempty"`
+	RssBytes        *int64    `json:"rssBytes,omitempty"`
+	Time            time.Time `json:"time"`
+	UsageBytes      *int64    `json:"usageBytes,omitempty"`
+	WorkingSetBytes *int64    `json:"workingSetBytes,omitempty"`
+}
+
+// MetricsList defines model for metricsList.
+type MetricsList struct {
+	Metrics *[]string `json:"metrics,omitempty"`
+}
+
+// NetworkDevice defines model for networkDevice.
+type NetworkDevice struct {
+	MacAddress *string `json:"macAddress,omitempty"`
+	Mtu        *int64  `json:"mtu,omitempty"`
+	Name       string  `json:"name"`
+
+	// link speed in Mbit/s
+	Speed *int64 `json:"speed,omitempty"`
+}
+
+// stats of the default interface and of every interface
+type NetworkStats struct {
+	Interfaces *[]InterfaceStats `json:"interfaces,omitempty"`
+	Name       *string           `json:"name,omitempty"`
+	RxBytes    *int64            `json:"rxBytes,omitempty"`
+	RxErrors   *int64            `json:"rxErrors,omitempty"`
+	Time       time.Time         `json:"time"`
+	TxBytes    *int64            `json:"txBytes,omitempty"`
+	TxErrors   *int64            `json:"txErrors,omitempty"`
+}
+
+// NodeError defines model for nodeError.
+type NodeError struct {
+	Error string `json:"error"`
+	Node  string `json:"node"`
+}
+
+// NodeStats defines model for nodeStats.
+type NodeStats struct {
+	Cpu    *CpuStats    `json:"cpu,omitempty"`
+	Fs     *FsStats     `json:"fs,omitempty"`
+	Memory *MemoryStats `json:"memory,omitempty"`
+
+	// stats of the default interface and of every interface
+	Network   *NetworkStats `json:"network,omitempty"`
+	NodeName  string        `json:"nodeName"`
+	Runtime   *RuntimeStats `json:"runtime,omitempty"`
+	StartTime *time.Time    `json:"startTime,omitempty"`
+}
+
+// NumaNode defines model for numaNode.
+type NumaNode struct {
+	Cores  *[]CpuCore `json:"cores,omitempty"`
+	Id     int        `json:"id"`
+	Memory int64      `json:"memory"`
+}
+
+// PodReference defines model for podReference.
+type PodReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Uid       string `json:"uid"`
+}
+
+// PodStats defines model for podStats.
+type PodStats struct {
+	Containers       []ContainerStats `json:"containers"`
+	Cpu              *CpuStats        `json:"cpu,omitempty"`
+	EphemeralStorage *FsStats         `json:"ephemeral-storage,omitempty"`
+	Memory           *MemoryStats     `json:"memory,omitempty"`
+
+	// stats of the default interface and of every interface
+	Network   *NetworkStats `json:"network,omitempty"`
+	PodRef    PodReference  `json:"podRef"`
+	StartTime *time.Time    `json:"startTime,omitempty"`
+}
+
+// Point defines model for point.
+type Point struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// PushResult defines model for pushResult.
+type PushResult struct {
+	// the batch was accepted before and was not stored again
+	Duplicate bool `json:"duplicate"`
+
+	// number of points stored, 0 for duplicates
+	Points int64 `json:"points"`
+}
+
+// RestoreRequest defines model for restoreRequest.
+type RestoreRequest struct {
+	// directory holding the backups, under the backup path of the daemon and relative to it unless absolute, defaults to the backup path
+	Path *string `json:"path,omitempty"`
+}
+
+// RuntimeStats defines model for runtimeStats.
+type RuntimeStats struct {
+	ImageFs *FsStats `json:"imageFs,omitempty"`
+}
+
+// Series defines model for series.
+type Series struct {
+	// field of the measurement holding the values, value for most measurements
+	Field       string `json:"field"`
+	Measurement string `json:"measurement"`
+
+	// numeric samples oldest first, fields with string values are left out
+	Points []Point     `json:"points"`
+	Tags   Series_Tags `json:"tags"`
+}
+
+// Series_Tags defines model for Series.Tags.
+type Series_Tags struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// SeriesList defines model for seriesList.
+type SeriesList struct {
+	// agents of an aggregator that failed to answer, their series are missing
+	Errors *[]NodeError `json:"errors,omitempty"`
+	Series []Series     `json:"series"`
+}
+
+// Summary defines model for summary.
+type Summary struct {
+	Node NodeStats  `json:"node"`
+	Pods []PodStats `json:"pods"`
+}
+
+// TagCardinality defines model for tagCardinality.
+type TagCardinality struct {
+	Name   string `json:"name"`
+	Values int    `json:"values"`
+}
+
+// TopEntry defines model for topEntry.
+type TopEntry struct {
+	Field       string        `json:"field"`
+	Measurement string        `json:"measurement"`
+	Node        string        `json:"node"`
+	Tags        TopEntry_Tags `json:"tags"`
+	Value       float64       `json:"value"`
+}
+
+// TopEntry_Tags defines model for TopEntry.Tags.
+type TopEntry_Tags struct {
+	AdditionalProperties map[string]string `json:"-"`
+}
+
+// TopList defines model for topList.
+type TopList struct {
+	Entries []TopEntry `json:"entries"`
+
+	// agents that failed to answer, their series are missing from the ranking
+	Errors *[]NodeError `json:"errors,omitempty"`
+}
+
+// VersionInfo defines model for versionInfo.
+type VersionInfo struct {
+	CadvisorRevision   *string `json:"cadvisorRevision,omitempty"`
+	CadvisorVersion    *string `json:"cadvisorVersion,omitempty"`
+	Commit             string  `json:"commit"`
+	ContainerOsVersion *string `json:"containerOsVersion,omitempty"`
+	Date               string  `json:"date"`
+	GoVersion          string  `json:"goVersion"`
+	KernelVersion      *string `json:"kernelVersion,omitempty"`
+	Version            string  `json:"version"`
+}
+
+// FederationContainer defines model for federationContainer.
+type FederationContainer = []string
+
+// FederationEndTime defines model for federationEndTime.
+type FederationEndTime = int
+
+// FederationField defines model for federationField.
+type FederationField = string
+
+// FederationMeasurement defines model for federationMeasurement.
+type FederationMeasurement = string
+
+// FederationNamespace defines model for federationNamespace.
+type FederationNamespace = []string
+
+// FederationPod defines model for federationPod.
+type FederationPod = []string
+
+// FederationStartTime defines model for federationStartTime.
+type FederationStartTime = int
+
+// GetAlertsParams defines parameters for GetAlerts.
+type GetAlertsParams struct {
+	// Only return alerts in this state
+	State *GetAlertsParamsState `form:"state,omitempty" json:"state,omitempty"`
+}
+
+// GetAlertsParamsState defines parameters for GetAlerts.
+type GetAlertsParamsState string
+
+// GetAnomaliesParams defines parameters for GetAnomalies.
+type GetAnomaliesParams struct {
+	// Start of the period in unix seconds, defaults to one hour before endTime
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Series to return anomalies of, defaults to all
+	Series *[]string `form:"series,omitempty" json:"series,omitempty"`
+}
+
+// GetCardinalityParams defines parameters for GetCardinality.
+type GetCardinalityParams struct {
+	// Number of containers to return, defaults to 10
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetEventsParams defines parameters for GetEvents.
+type GetEventsParams struct {
+	// Start of the period in unix seconds, defaults to one hour before endTime
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Event types to return, defaults to all
+	Type *[]GetEventsParamsType `form:"type,omitempty" json:"type,omitempty"`
+}
+
+// GetEventsParamsType defines parameters for GetEvents.
+type GetEventsParamsType string
+
+// GetFederationSumParams defines parameters for GetFederationSum.
+type GetFederationSumParams struct {
+	// Measurement of the series
+	Measurement FederationMeasurement `form:"measurement" json:"measurement"`
+
+	// Field of the measurement, defaults to value
+	Field *FederationField `form:"field,omitempty" json:"field,omitempty"`
+
+	// Start of the period in unix seconds, defaults to an hour before its end
+	StartTime *FederationStartTime `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *FederationEndTime `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Container names of the series, defaults to all
+	Container *FederationContainer `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces of the series, defaults to all
+	Namespace *FederationNamespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names of the series, defaults to all
+	Pod *FederationPod `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Tags the sums are grouped by, such as node or namespace, defaults to none
+	GroupBy *[]string `form:"groupBy,omitempty" json:"groupBy,omitempty"`
+
+	// Window of the sums in seconds, defaults to 10
+	Step *int `form:"step,omitempty" json:"step,omitempty"`
+}
+
+// GetFederationTopParams defines parameters for GetFederationTop.
+type GetFederationTopParams struct {
+	// Measurement of the series
+	Measurement FederationMeasurement `form:"measurement" json:"measurement"`
+
+	// Field of the measurement, defaults to value
+	Field *FederationField `form:"field,omitempty" json:"field,omitempty"`
+
+	// Start of the period in unix seconds, defaults to an hour before its end
+	StartTime *FederationStartTime `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *FederationEndTime `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Container names of the series, defaults to all
+	Container *FederationContainer `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces of the series, defaults to all
+	Namespace *FederationNamespace `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names of the series, defaults to all
+	Pod *FederationPod `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Number of series to return, defaults to 10
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Value a series is ranked by, its last sample, the mean or maximum of its samples or the per second rate of a counter over the period, defaults to last
+	By *GetFederationTopParamsBy `form:"by,omitempty" json:"by,omitempty"`
+}
+
+// GetFederationTopParamsBy defines parameters for GetFederationTop.
+type GetFederationTopParamsBy string
+
+// PostImportJSONBody defines parameters for PostImport.
+type PostImportJSONBody struct {
+	AdditionalProperties map[string]map[string]interface{} `json:"-"`
+}
+
+// PostImportParams defines parameters for PostImport.
+type PostImportParams struct {
+	// Format of the body, lp for line protocol or json for the container infos returned by cadvisor ContainerInfoV2, defaults to json for application/json bodies and lp otherwise
+	Format *PostImportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Precision of line protocol timestamps, defaults to ns
+	Precision *PostImportParamsPrecision `form:"precision,omitempty" json:"precision,omitempty"`
+
+	// machine_name tag of the points converted from json, defaults to the hostname of the daemon
+	Machine *string `form:"machine,omitempty" json:"machine,omitempty"`
+}
+
+// PostImportParamsFormat defines parameters for PostImport.
+type PostImportParamsFormat string
+
+// PostImportParamsPrecision defines parameters for PostImport.
+type PostImportParamsPrecision string
+
+// GetMetricsListParams defines parameters for GetMetricsList.
+type GetMetricsListParams struct {
+	// Start of the period in unix seconds. Without a period the last minute stored by the periodic scrapes is returned
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Measurements to return from the period, defaults to all
+	Measurement *[]string `form:"measurement,omitempty" json:"measurement,omitempty"`
+
+	// Container names to return from the period, defaults to all
+	Container *[]string `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces to return from the period, defaults to all
+	Namespace *[]string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names to return from the period, defaults to all
+	Pod *[]string `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Kubernetes QoS classes to return from the period, Guaranteed, Burstable or BestEffort, defaults to all
+	QosClass *[]string `form:"qosClass,omitempty" json:"qosClass,omitempty"`
+}
+
+// PostPushParams defines parameters for PostPush.
+type PostPushParams struct {
+	// Id of the batch, unique per agent
+	Batch string `form:"batch" json:"batch"`
+}
+
+// PostRestoreJSONBody defines parameters for PostRestore.
+type PostRestoreJSONBody = RestoreRequest
+
+// GetStatsSummaryParams defines parameters for GetStatsSummary.
+type GetStatsSummaryParams struct {
+	// Instant of the summary in unix seconds, defaults to now
+	Time *int `form:"time,omitempty" json:"time,omitempty"`
+}
+
+// GetStreamParams defines parameters for GetStream.
+type GetStreamParams struct {
+	// Container names or path globs to stream
+	Container *[]string `form:"container,omitempty" json:"container,omitempty"`
+
+	// Measurements to stream
+	Measurement *[]string `form:"measurement,omitempty" json:"measurement,omitempty"`
+}
+
+// GetV1MetricsListParams defines parameters for GetV1MetricsList.
+type GetV1MetricsListParams struct {
+	// Start of the period in unix seconds. Without a period the last minute stored by the periodic scrapes is returned
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Measurements to return from the period, defaults to all
+	Measurement *[]string `form:"measurement,omitempty" json:"measurement,omitempty"`
+
+	// Container names to return from the period, defaults to all
+	Container *[]string `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces to return from the period, defaults to all
+	Namespace *[]string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names to return from the period, defaults to all
+	Pod *[]string `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Kubernetes QoS classes to return from the period, Guaranteed, Burstable or BestEffort, defaults to all
+	QosClass *[]string `form:"qosClass,omitempty" json:"qosClass,omitempty"`
+}
+
+// GetV2MetricsListParams defines parameters for GetV2MetricsList.
+type GetV2MetricsListParams struct {
+	// Start of the period in unix seconds. Without a period the last minute stored by the periodic scrapes is returned
+	StartTime *int `form:"startTime,omitempty" json:"startTime,omitempty"`
+
+	// End of the period in unix seconds, defaults to now
+	EndTime *int `form:"endTime,omitempty" json:"endTime,omitempty"`
+
+	// Measurements to return from the period, defaults to all
+	Measurement *[]string `form:"measurement,omitempty" json:"measurement,omitempty"`
+
+	// Container names to return from the period, defaults to all
+	Container *[]string `form:"container,omitempty" json:"container,omitempty"`
+
+	// Kubernetes namespaces to return from the period, defaults to all
+	Namespace *[]string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Kubernetes pod names to return from the period, defaults to all
+	Pod *[]string `form:"pod,omitempty" json:"pod,omitempty"`
+
+	// Kubernetes QoS classes to return from the period, Guaranteed, Burstable or BestEffort, defaults to all
+	QosClass *[]string `form:"qosClass,omitempty" json:"qosClass,omitempty"`
+
+	// Format of the response, overriding the Accept header. Formats other than json stream the records of the period, which defaults to the last hour, and the columnar arrow and parquet formats hold numeric values only
+	Format *GetV2MetricsListParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Sum the series of the containers of every Kubernetes pod into pod series tagged with namespace, pod and qos_class along with the tags telling apart the series of a container, such as instance or failure_type. Network series are counted once per pod and limits are left out, json only
+	Rollup *GetV2MetricsListParamsRollup `form:"rollup,omitempty" json:"rollup,omitempty"`
+
+	// Window of a rollup in seconds, the last sample of every container in a window is summed, defaults to 10
+	Step *int `form:"step,omitempty" json:"step,omitempty"`
+}
+
+// GetV2MetricsListParamsFormat defines parameters for GetV2MetricsList.
+type GetV2MetricsListParamsFormat string
+
+// GetV2MetricsListParamsRollup defines parameters for GetV2MetricsList.
+type GetV2MetricsListParamsRollup string
+
+// PostImportJSONRequestBody defines body for PostImport for application/json ContentType.
+type PostImportJSONRequestBody PostImportJSONBody
+
+// PostRestoreJSONRequestBody defines body for PostRestore for application/json ContentType.
+type PostRestoreJSONRequestBody = PostRestoreJSONBody
+
+// Getter for additional properties for PostImportJSONBody. Returns the specified
+// element and whether it was found
+func (a PostImportJSONBody) Get(fieldName string) (value map[string]interface{}, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for PostImportJSONBody
+func (a *PostImportJSONBody) Set(fieldName string, value map[string]interface{}) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]map[string]interface{})
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for PostImportJSONBody to handle AdditionalProperties
+func (a *PostImportJSONBody) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]map[string]interface{})
+		for fieldName, fieldBuf := range object {
+			var fieldVal map[string]interface{}
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for PostImportJSONBody to handle AdditionalProperties
+func (a PostImportJSONBody) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Alert_Annotations. Returns the specified
+// element and whether it was found
+func (a Alert_Annotations) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Alert_Annotations
+func (a *Alert_Annotations) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Alert_Annotations to handle AdditionalProperties
+func (a *Alert_Annotations) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Alert_Annotations to handle AdditionalProperties
+func (a Alert_Annotations) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Alert_Labels. Returns the specified
+// element and whether it was found
+func (a Alert_Labels) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Alert_Labels
+func (a *Alert_Labels) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Alert_Labels to handle AdditionalProperties
+func (a *Alert_Labels) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Alert_Labels to handle AdditionalProperties
+func (a Alert_Labels) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Anomaly_Tags. Returns the specified
+// element and whether it was found
+func (a Anomaly_Tags) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Anomaly_Tags
+func (a *Anomaly_Tags) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Anomaly_Tags to handle AdditionalProperties
+func (a *Anomaly_Tags) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Anomaly_Tags to handle AdditionalProperties
+func (a Anomaly_Tags) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Container_Labels. Returns the specified
+// element and whether it was found
+func (a Container_Labels) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Container_Labels
+func (a *Container_Labels) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Container_Labels to handle AdditionalProperties
+func (a *Container_Labels) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Container_Labels to handle AdditionalProperties
+func (a Container_Labels) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Event_Tags. Returns the specified
+// element and whether it was found
+func (a Event_Tags) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Event_Tags
+func (a *Event_Tags) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Event_Tags to handle AdditionalProperties
+func (a *Event_Tags) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Event_Tags to handle AdditionalProperties
+func (a Event_Tags) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for Series_Tags. Returns the specified
+// element and whether it was found
+func (a Series_Tags) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Series_Tags
+func (a *Series_Tags) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Series_Tags to handle AdditionalProperties
+func (a *Series_Tags) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Series_Tags to handle AdditionalProperties
+func (a Series_Tags) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for TopEntry_Tags. Returns the specified
+// element and whether it was found
+func (a TopEntry_Tags) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for TopEntry_Tags
+func (a *TopEntry_Tags) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for TopEntry_Tags to handle AdditionalProperties
+func (a *TopEntry_Tags) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for TopEntry_Tags to handle AdditionalProperties
+func (a TopEntry_Tags) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// RequestEditorFn  is the function signature for the RequestEditor callback function
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Doer performs HTTP requests.
+//
+// The standard http.Client implements this interface.
+type HttpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client which conforms to the OpenAPI3 specification for this service.
+type Client struct {
+	// The endpoint of the server conforming to this interface, with scheme,
+	// https://api.deepmap.com for example. This can contain a path relative
+	// to the server, such as https://api.deepmap.com/dev-test, and all the
+	// paths in the swagger spec will be appended to the server.
+	Server string
+
+	// Doer for performing requests, typically a *http.Client with any
+	// customized settings, such as certificate chains.
+	Client HttpRequestDoer
+
+	// A list of callbacks for modifying requests which are generated before sending over
+	// the network.
+	RequestEditors []RequestEditorFn
+}
+
+// ClientOption allows setting custom parameters during construction
+type ClientOption func(*Client) error
+
+// Creates a new Client, with reasonable defaults
+func NewClient(server string, opts ...ClientOption) (*Client, error) {
+	// create a client with sane default values
+	client := Client{
+		Server: server,
+	}
+	// mutate client and add all optional params
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+	// ensure the server URL always has a trailing slash
+	if !strings.HasSuffix(client.Server, "/") {
+		client.Server += "/"
+	}
+	// create httpClient, if not already present
+	if client.Client == nil {
+		client.Client = &http.Client{}
+	}
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer HttpRequestDoer) ClientOption {
+	return func(c *Client) error {
+		c.Client = doer
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+		return nil
+	}
+}
+
+// The interface specification for the client above.
+type ClientInterface interface {
+	// GetAlerts request
+	GetAlerts(ctx context.Context, params *GetAlertsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetAnomalies request
+	GetAnomalies(ctx context.Context, params *GetAnomaliesParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostApiV1Write request with any body
+	PostApiV1WriteWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostBackup request
+	PostBackup(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetCardinality request
+	GetCardinality(ctx context.Context, params *GetCardinalityParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetContainers request
+	GetContainers(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEvents request
+	GetEvents(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetFederationSum request
+	GetFederationSum(ctx context.Context, params *GetFederationSumParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetFederationTop request
+	GetFederationTop(ctx context.Context, params *GetFederationTopParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetFsImages request
+	GetFsImages(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetFsRoot request
+	GetFsRoot(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostImport request with any body
+	PostImportWithBody(ctx context.Context, params *PostImportParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostImport(ctx context.Context, params *PostImportParams, body PostImportJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetMachine request
+	GetMachine(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetMetrics request
+	GetMetrics(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetMetricsList request
+	GetMetricsList(ctx context.Context, params *GetMetricsListParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostPush request with any body
+	PostPushWithBody(ctx context.Context, params *PostPushParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostRestore request with any body
+	PostRestoreWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostRestore(ctx context.Context, body PostRestoreJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetStatsSummary request
+	GetStatsSummary(ctx context.Context, params *GetStatsSummaryParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetStream request
+	GetStream(ctx context.Context, params *GetStreamParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetV1MetricsList request
+	GetV1MetricsList(ctx context.Context, params *GetV1MetricsListParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetV2MetricsList request
+	GetV2MetricsList(ctx context.Context, params *GetV2MetricsListParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetVersion request
+	GetVersion(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) GetAlerts(ctx context.Context, params *GetAlertsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetAlertsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetAnomalies(ctx context.Context, params *GetAnomaliesParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetAnomaliesRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostApiV1WriteWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostApiV1WriteRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostBackup(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostBackupRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetCardinality(ctx context.Context, params *GetCardinalityParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetCardinalityRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetContainers(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetContainersRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetEvents(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEventsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetFederationSum(ctx context.Context, params *GetFederationSumParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetFederationSumRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetFederationTop(ctx context.Context, params *GetFederationTopParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetFederationTopRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetFsImages(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetFsImagesRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetFsRoot(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetFsRootRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostImportWithBody(ctx context.Context, params *PostImportParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostImportRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostImport(ctx context.Context, params *PostImportParams, body PostImportJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostImportRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetMachine(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetMachineRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetMetrics(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetMetricsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetMetricsList(ctx context.Context, params *GetMetricsListParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetMetricsListRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostPushWithBody(ctx context.Context, params *PostPushParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostPushRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostRestoreWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostRestoreRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostRestore(ctx context.Context, body PostRestoreJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostRestoreRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetStatsSummary(ctx context.Context, params *GetStatsSummaryParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetStatsSummaryRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetStream(ctx context.Context, params *GetStreamParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetStreamRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetV1MetricsList(ctx context.Context, params *GetV1MetricsListParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetV1MetricsListRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetV2MetricsList(ctx context.Context, params *GetV2MetricsListParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetV2MetricsListRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetVersion(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetVersionRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewGetAlertsRequest generates requests for GetAlerts
+func NewGetAlertsRequest(server string, params *GetAlertsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/alerts")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.State != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "state", runtime.ParamLocationQuery, *params.State); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetAnomaliesRequest generates requests for GetAnomalies
+func NewGetAnomaliesRequest(server string, params *GetAnomaliesParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/anomalies")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.StartTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startTime", runtime.ParamLocationQuery, *params.StartTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.EndTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endTime", runtime.ParamLocationQuery, *params.EndTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Series != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "series", runtime.ParamLocationQuery, *params.Series); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostApiV1WriteRequestWithBody generates requests for PostApiV1Write with any type of body
+func NewPostApiV1WriteRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/write")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewPostBackupRequest generates requests for PostBackup
+func NewPostBackupRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/backup")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetCardinalityRequest generates requests for GetCardinality
+func NewGetCardinalityRequest(server string, params *GetCardinalityParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/cardinality")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.Limit != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetContainersRequest generates requests for GetContainers
+func NewGetContainersRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/containers")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetEventsRequest generates requests for GetEvents
+func NewGetEventsRequest(server string, params *GetEventsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/events")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.StartTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startTime", runtime.ParamLocationQuery, *params.StartTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.EndTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endTime", runtime.ParamLocationQuery, *params.EndTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Type != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "type", runtime.ParamLocationQuery, *params.Type); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetFederationSumRequest generates requests for GetFederationSum
+func NewGetFederationSumRequest(server string, params *GetFederationSumParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/federation/sum")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if queryFrag, err := runtime.StyleParamWithLocation("form", true, "measurement", runtime.ParamLocationQuery, params.Measurement); err != nil {
+		return nil, err
+	} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+		return nil, err
+	} else {
+		for k, v := range parsed {
+			for _, v2 := range v {
+				queryValues.Add(k, v2)
+			}
+		}
+	}
+
+	if params.Field != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "field", runtime.ParamLocationQuery, *params.Field); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.StartTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startTime", runtime.ParamLocationQuery, *params.StartTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.EndTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endTime", runtime.ParamLocationQuery, *params.EndTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Container != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "container", runtime.ParamLocationQuery, *params.Container); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Namespace != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "namespace", runtime.ParamLocationQuery, *params.Namespace); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Pod != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "pod", runtime.ParamLocationQuery, *params.Pod); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.GroupBy != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "groupBy", runtime.ParamLocationQuery, *params.GroupBy); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Step != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "step", runtime.ParamLocationQuery, *params.Step); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetFederationTopRequest generates requests for GetFederationTop
+func NewGetFederationTopRequest(server string, params *GetFederationTopParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/federation/top")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if queryFrag, err := runtime.StyleParamWithLocation("form", true, "measurement", runtime.ParamLocationQuery, params.Measurement); err != nil {
+		return nil, err
+	} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+		return nil, err
+	} else {
+		for k, v := range parsed {
+			for _, v2 := range v {
+				queryValues.Add(k, v2)
+			}
+		}
+	}
+
+	if params.Field != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "field", runtime.ParamLocationQuery, *params.Field); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.StartTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startTime", runtime.ParamLocationQuery, *params.StartTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.EndTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endTime", runtime.ParamLocationQuery, *params.EndTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Container != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "container", runtime.ParamLocationQuery, *params.Container); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Namespace != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "namespace", runtime.ParamLocationQuery, *params.Namespace); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Pod != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "pod", runtime.ParamLocationQuery, *params.Pod); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Limit != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.By != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "by", runtime.ParamLocationQuery, *params.By); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetFsImagesRequest generates requests for GetFsImages
+func NewGetFsImagesRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/fs/images")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetFsRootRequest generates requests for GetFsRoot
+func NewGetFsRootRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/fs/root")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostImportRequest calls the generic PostImport builder with application/json body
+func NewPostImportRequest(server string, params *PostImportParams, body PostImportJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostImportRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewPostImportRequestWithBody generates requests for PostImport with any type of body
+func NewPostImportRequestWithBody(server string, params *PostImportParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/import")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.Format != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "format", runtime.ParamLocationQuery, *params.Format); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Precision != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "precision", runtime.ParamLocationQuery, *params.Precision); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Machine != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "machine", runtime.ParamLocationQuery, *params.Machine); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetMachineRequest generates requests for GetMachine
+func NewGetMachineRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/machine")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetMetricsRequest generates requests for GetMetrics
+func NewGetMetricsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/metrics")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetMetricsListRequest generates requests for GetMetricsList
+func NewGetMetricsListRequest(server string, params *GetMetricsListParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/metricsList")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.StartTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startTime", runtime.ParamLocationQuery, *params.StartTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.EndTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endTime", runtime.ParamLocationQuery, *params.EndTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Measurement != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "measurement", runtime.ParamLocationQuery, *params.Measurement); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Container != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "container", runtime.ParamLocationQuery, *params.Container); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Namespace != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "namespace", runtime.ParamLocationQuery, *params.Namespace); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Pod != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "pod", runtime.ParamLocationQuery, *params.Pod); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.QosClass != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "qosClass", runtime.ParamLocationQuery, *params.QosClass); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostPushRequestWithBody generates requests for PostPush with any type of body
+func NewPostPushRequestWithBody(server string, params *PostPushParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/push")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if queryFrag, err := runtime.StyleParamWithLocation("form", true, "batch", runtime.ParamLocationQuery, params.Batch); err != nil {
+		return nil, err
+	} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+		return nil, err
+	} else {
+		for k, v := range parsed {
+			for _, v2 := range v {
+				queryValues.Add(k, v2)
+			}
+		}
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewPostRestoreRequest calls the generic PostRestore builder with application/json body
+func NewPostRestoreRequest(server string, body PostRestoreJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostRestoreRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewPostRestoreRequestWithBody generates requests for PostRestore with any type of body
+func NewPostRestoreRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/restore")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetStatsSummaryRequest generates requests for GetStatsSummary
+func NewGetStatsSummaryRequest(server string, params *GetStatsSummaryParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/stats/summary")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.Time != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "time", runtime.ParamLocationQuery, *params.Time); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetStreamRequest generates requests for GetStream
+func NewGetStreamRequest(server string, params *GetStreamParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/stream")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.Container != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "container", runtime.ParamLocationQuery, *params.Container); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Measurement != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "measurement", runtime.ParamLocationQuery, *params.Measurement); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetV1MetricsListRequest generates requests for GetV1MetricsList
+func NewGetV1MetricsListRequest(server string, params *GetV1MetricsListParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/v1/metricsList")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.StartTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startTime", runtime.ParamLocationQuery, *params.StartTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.EndTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endTime", runtime.ParamLocationQuery, *params.EndTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Measurement != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "measurement", runtime.ParamLocationQuery, *params.Measurement); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Container != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "container", runtime.ParamLocationQuery, *params.Container); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Namespace != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "namespace", runtime.ParamLocationQuery, *params.Namespace); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Pod != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "pod", runtime.ParamLocationQuery, *params.Pod); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.QosClass != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "qosClass", runtime.ParamLocationQuery, *params.QosClass); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetV2MetricsListRequest generates requests for GetV2MetricsList
+func NewGetV2MetricsListRequest(server string, params *GetV2MetricsListParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/v2/metricsList")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if params.StartTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "startTime", runtime.ParamLocationQuery, *params.StartTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.EndTime != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "endTime", runtime.ParamLocationQuery, *params.EndTime); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Measurement != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "measurement", runtime.ParamLocationQuery, *params.Measurement); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Container != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "container", runtime.ParamLocationQuery, *params.Container); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Namespace != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "namespace", runtime.ParamLocationQuery, *params.Namespace); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Pod != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "pod", runtime.ParamLocationQuery, *params.Pod); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.QosClass != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "qosClass", runtime.ParamLocationQuery, *params.QosClass); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Format != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "format", runtime.ParamLocationQuery, *params.Format); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Rollup != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "rollup", runtime.ParamLocationQuery, *params.Rollup); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	if params.Step != nil {
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "step", runtime.ParamLocationQuery, *params.Step); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetVersionRequest generates requests for GetVersion
+func NewGetVersionRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/version")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// GetAlerts request
+	GetAlertsWithResponse(ctx context.Context, params *GetAlertsParams, reqEditors ...RequestEditorFn) (*GetAlertsResponse, error)
+
+	// GetAnomalies request
+	GetAnomaliesWithResponse(ctx context.Context, params *GetAnomaliesParams, reqEditors ...RequestEditorFn) (*GetAnomaliesResponse, error)
+
+	// PostApiV1Write request with any body
+	PostApiV1WriteWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostApiV1WriteResponse, error)
+
+	// PostBackup request
+	PostBackupWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*PostBackupResponse, error)
+
+	// GetCardinality request
+	GetCardinalityWithResponse(ctx context.Context, params *GetCardinalityParams, reqEditors ...RequestEditorFn) (*GetCardinalityResponse, error)
+
+	// GetContainers request
+	GetContainersWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetContainersResponse, error)
+
+	// GetEvents request
+	GetEventsWithResponse(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*GetEventsResponse, error)
+
+	// GetFederationSum request
+	GetFederationSumWithResponse(ctx context.Context, params *GetFederationSumParams, reqEditors ...RequestEditorFn) (*GetFederationSumResponse, error)
+
+	// GetFederationTop request
+	GetFederationTopWithResponse(ctx context.Context, params *GetFederationTopParams, reqEditors ...RequestEditorFn) (*GetFederationTopResponse, error)
+
+	// GetFsImages request
+	GetFsImagesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetFsImagesResponse, error)
+
+	// GetFsRoot request
+	GetFsRootWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetFsRootResponse, error)
+
+	// PostImport request with any body
+	PostImportWithBodyWithResponse(ctx context.Context, params *PostImportParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostImportResponse, error)
+
+	PostImportWithResponse(ctx context.Context, params *PostImportParams, body PostImportJSONRequestBody, reqEditors ...RequestEditorFn) (*PostImportResponse, error)
+
+	// GetMachine request
+	GetMachineWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetMachineResponse, error)
+
+	// GetMetrics request
+	GetMetricsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetMetricsResponse, error)
+
+	// GetMetricsList request
+	GetMetricsListWithResponse(ctx context.Context, params *GetMetricsListParams, reqEditors ...RequestEditorFn) (*GetMetricsListResponse, error)
+
+	// PostPush request with any body
+	PostPushWithBodyWithResponse(ctx context.Context, params *PostPushParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostPushResponse, error)
+
+	// PostRestore request with any body
+	PostRestoreWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostRestoreResponse, error)
+
+	PostRestoreWithResponse(ctx context.Context, body PostRestoreJSONRequestBody, reqEditors ...RequestEditorFn) (*PostRestoreResponse, error)
+
+	// GetStatsSummary request
+	GetStatsSummaryWithResponse(ctx context.Context, params *GetStatsSummaryParams, reqEditors ...RequestEditorFn) (*GetStatsSummaryResponse, error)
+
+	// GetStream request
+	GetStreamWithResponse(ctx context.Context, params *GetStreamParams, reqEditors ...RequestEditorFn) (*GetStreamResponse, error)
+
+	// GetV1MetricsList request
+	GetV1MetricsListWithResponse(ctx context.Context, params *GetV1MetricsListParams, reqEditors ...RequestEditorFn) (*GetV1MetricsListResponse, error)
+
+	// GetV2MetricsList request
+	GetV2MetricsListWithResponse(ctx context.Context, params *GetV2MetricsListParams, reqEditors ...RequestEditorFn) (*GetV2MetricsListResponse, error)
+
+	// GetVersion request
+	GetVersionWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetVersionResponse, error)
+}
+
+type GetAlertsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *AlertList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetAlertsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetAlertsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetAnomaliesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *AnomalyList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetAnomaliesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetAnomaliesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostApiV1WriteResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r PostApiV1WriteResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostApiV1WriteResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostBackupResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Backup
+}
+
+// Status returns HTTPResponse.Status
+func (r PostBackupResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostBackupResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetCardinalityResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *CardinalityReport
+}
+
+// Status returns HTTPResponse.Status
+func (r GetCardinalityResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetCardinalityResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetContainersResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ContainerList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetContainersResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetContainersResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEventsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *EventList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEventsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEventsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetFederationSumResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SeriesList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetFederationSumResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetFederationSumResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetFederationTopResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *TopList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetFederationTopResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetFederationTopResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetFsImagesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *FsInfoList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetFsImagesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetFsImagesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetFsRootResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *FsInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r GetFsRootResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetFsRootResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostImportResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ImportResult
+}
+
+// Status returns HTTPResponse.Status
+func (r PostImportResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostImportResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetMachineResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *MachineInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r GetMachineResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetMachineResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetMetricsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r GetMetricsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetMetricsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetMetricsListResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *MetricsList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetMetricsListResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetMetricsListResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostPushResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *PushResult
+}
+
+// Status returns HTTPResponse.Status
+func (r PostPushResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostPushResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostRestoreResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Backup
+}
+
+// Status returns HTTPResponse.Status
+func (r PostRestoreResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostRestoreResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetStatsSummaryResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Summary
+}
+
+// Status returns HTTPResponse.Status
+func (r GetStatsSummaryResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetStatsSummaryResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetStreamResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r GetStreamResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetStreamResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetV1MetricsListResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *MetricsList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetV1MetricsListResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetV1MetricsListResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetV2MetricsListResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SeriesList
+}
+
+// Status returns HTTPResponse.Status
+func (r GetV2MetricsListResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetV2MetricsListResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetVersionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *VersionInfo
+}
+
+// Status returns HTTPResponse.Status
+func (r GetVersionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetVersionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// GetAlertsWithResponse request returning *GetAlertsResponse
+func (c *ClientWithResponses) GetAlertsWithResponse(ctx context.Context, params *GetAlertsParams, reqEditors ...RequestEditorFn) (*GetAlertsResponse, error) {
+	rsp, err := c.GetAlerts(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetAlertsResponse(rsp)
+}
+
+// GetAnomaliesWithResponse request returning *GetAnomaliesResponse
+func (c *ClientWithResponses) GetAnomaliesWithResponse(ctx context.Context, params *GetAnomaliesParams, reqEditors ...RequestEditorFn) (*GetAnomaliesResponse, error) {
+	rsp, err := c.GetAnomalies(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetAnomaliesResponse(rsp)
+}
+
+// PostApiV1WriteWithBodyWithResponse request with arbitrary body returning *PostApiV1WriteResponse
+func (c *ClientWithResponses) PostApiV1WriteWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostApiV1WriteResponse, error) {
+	rsp, err := c.PostApiV1WriteWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostApiV1WriteResponse(rsp)
+}
+
+// PostBackupWithResponse request returning *PostBackupResponse
+func (c *ClientWithResponses) PostBackupWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*PostBackupResponse, error) {
+	rsp, err := c.PostBackup(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostBackupResponse(rsp)
+}
+
+// GetCardinalityWithResponse request returning *GetCardinalityResponse
+func (c *ClientWithResponses) GetCardinalityWithResponse(ctx context.Context, params *GetCardinalityParams, reqEditors ...RequestEditorFn) (*GetCardinalityResponse, error) {
+	rsp, err := c.GetCardinality(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetCardinalityResponse(rsp)
+}
+
+// GetContainersWithResponse request returning *GetContainersResponse
+func (c *ClientWithResponses) GetContainersWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetContainersResponse, error) {
+	rsp, err := c.GetContainers(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetContainersResponse(rsp)
+}
+
+// GetEventsWithResponse request returning *GetEventsResponse
+func (c *ClientWithResponses) GetEventsWithResponse(ctx context.Context, params *GetEventsParams, reqEditors ...RequestEditorFn) (*GetEventsResponse, error) {
+	rsp, err := c.GetEvents(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEventsResponse(rsp)
+}
+
+// GetFederationSumWithResponse request returning *GetFederationSumResponse
+func (c *ClientWithResponses) GetFederationSumWithResponse(ctx context.Context, params *GetFederationSumParams, reqEditors ...RequestEditorFn) (*GetFederationSumResponse, error) {
+	rsp, err := c.GetFederationSum(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetFederationSumResponse(rsp)
+}
+
+// GetFederationTopWithResponse request returning *GetFederationTopResponse
+func (c *ClientWithResponses) GetFederationTopWithResponse(ctx context.Context, params *GetFederationTopParams, reqEditors ...RequestEditorFn) (*GetFederationTopResponse, error) {
+	rsp, err := c.GetFederationTop(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetFederationTopResponse(rsp)
+}
+
+// GetFsImagesWithResponse request returning *GetFsImagesResponse
+func (c *ClientWithResponses) GetFsImagesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetFsImagesResponse, error) {
+	rsp, err := c.GetFsImages(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetFsImagesResponse(rsp)
+}
+
+// GetFsRootWithResponse request returning *GetFsRootResponse
+func (c *ClientWithResponses) GetFsRootWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetFsRootResponse, error) {
+	rsp, err := c.GetFsRoot(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetFsRootResponse(rsp)
+}
+
+// PostImportWithBodyWithResponse request with arbitrary body returning *PostImportResponse
+func (c *ClientWithResponses) PostImportWithBodyWithResponse(ctx context.Context, params *PostImportParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostImportResponse, error) {
+	rsp, err := c.PostImportWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostImportResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostImportWithResponse(ctx context.Context, params *PostImportParams, body PostImportJSONRequestBody, reqEditors ...RequestEditorFn) (*PostImportResponse, error) {
+	rsp, err := c.PostImport(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostImportResponse(rsp)
+}
+
+// GetMachineWithResponse request returning *GetMachineResponse
+func (c *ClientWithResponses) GetMachineWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetMachineResponse, error) {
+	rsp, err := c.GetMachine(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetMachineResponse(rsp)
+}
+
+// GetMetricsWithResponse request returning *GetMetricsResponse
+func (c *ClientWithResponses) GetMetricsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetMetricsResponse, error) {
+	rsp, err := c.GetMetrics(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetMetricsResponse(rsp)
+}
+
+// GetMetricsListWithResponse request returning *GetMetricsListResponse
+func (c *ClientWithResponses) GetMetricsListWithResponse(ctx context.Context, params *GetMetricsListParams, reqEditors ...RequestEditorFn) (*GetMetricsListResponse, error) {
+	rsp, err := c.GetMetricsList(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetMetricsListResponse(rsp)
+}
+
+// PostPushWithBodyWithResponse request with arbitrary body returning *PostPushResponse
+func (c *ClientWithResponses) PostPushWithBodyWithResponse(ctx context.Context, params *PostPushParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostPushResponse, error) {
+	rsp, err := c.PostPushWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostPushResponse(rsp)
+}
+
+// PostRestoreWithBodyWithResponse request with arbitrary body returning *PostRestoreResponse
+func (c *ClientWithResponses) PostRestoreWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostRestoreResponse, error) {
+	rsp, err := c.PostRestoreWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostRestoreResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostRestoreWithResponse(ctx context.Context, body PostRestoreJSONRequestBody, reqEditors ...RequestEditorFn) (*PostRestoreResponse, error) {
+	rsp, err := c.PostRestore(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostRestoreResponse(rsp)
+}
+
+// GetStatsSummaryWithResponse request returning *GetStatsSummaryResponse
+func (c *ClientWithResponses) GetStatsSummaryWithResponse(ctx context.Context, params *GetStatsSummaryParams, reqEditors ...RequestEditorFn) (*GetStatsSummaryResponse, error) {
+	rsp, err := c.GetStatsSummary(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetStatsSummaryResponse(rsp)
+}
+
+// GetStreamWithResponse request returning *GetStreamResponse
+func (c *ClientWithResponses) GetStreamWithResponse(ctx context.Context, params *GetStreamParams, reqEditors ...RequestEditorFn) (*GetStreamResponse, error) {
+	rsp, err := c.GetStream(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetStreamResponse(rsp)
+}
+
+// GetV1MetricsListWithResponse request returning *GetV1MetricsListResponse
+func (c *ClientWithResponses) GetV1MetricsListWithResponse(ctx context.Context, params *GetV1MetricsListParams, reqEditors ...RequestEditorFn) (*GetV1MetricsListResponse, error) {
+	rsp, err := c.GetV1MetricsList(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetV1MetricsListResponse(rsp)
+}
+
+// GetV2MetricsListWithResponse request returning *GetV2MetricsListResponse
+func (c *ClientWithResponses) GetV2MetricsListWithResponse(ctx context.Context, params *GetV2MetricsListParams, reqEditors ...RequestEditorFn) (*GetV2MetricsListResponse, error) {
+	rsp, err := c.GetV2MetricsList(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetV2MetricsListResponse(rsp)
+}
+
+// GetVersionWithResponse request returning *GetVersionResponse
+func (c *ClientWithResponses) GetVersionWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetVersionResponse, error) {
+	rsp, err := c.GetVersion(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetVersionResponse(rsp)
+}
+
+// ParseGetAlertsResponse parses an HTTP response from a GetAlertsWithResponse call
+func ParseGetAlertsResponse(rsp *http.Response) (*GetAlertsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetAlertsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest AlertList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetAnomaliesResponse parses an HTTP response from a GetAnomaliesWithResponse call
+func ParseGetAnomaliesResponse(rsp *http.Response) (*GetAnomaliesResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetAnomaliesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest AnomalyList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostApiV1WriteResponse parses an HTTP response from a PostApiV1WriteWithResponse call
+func ParsePostApiV1WriteResponse(rsp *http.Response) (*PostApiV1WriteResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostApiV1WriteResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
+
+// ParsePostBackupResponse parses an HTTP response from a PostBackupWithResponse call
+func ParsePostBackupResponse(rsp *http.Response) (*PostBackupResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostBackupResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Backup
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetCardinalityResponse parses an HTTP response from a GetCardinalityWithResponse call
+func ParseGetCardinalityResponse(rsp *http.Response) (*GetCardinalityResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetCardinalityResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest CardinalityReport
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetContainersResponse parses an HTTP response from a GetContainersWithResponse call
+func ParseGetContainersResponse(rsp *http.Response) (*GetContainersResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetContainersResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ContainerList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetEventsResponse parses an HTTP response from a GetEventsWithResponse call
+func ParseGetEventsResponse(rsp *http.Response) (*GetEventsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetEventsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest EventList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetFederationSumResponse parses an HTTP response from a GetFederationSumWithResponse call
+func ParseGetFederationSumResponse(rsp *http.Response) (*GetFederationSumResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetFederationSumResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SeriesList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetFederationTopResponse parses an HTTP response from a GetFederationTopWithResponse call
+func ParseGetFederationTopResponse(rsp *http.Response) (*GetFederationTopResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetFederationTopResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest TopList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetFsImagesResponse parses an HTTP response from a GetFsImagesWithResponse call
+func ParseGetFsImagesResponse(rsp *http.Response) (*GetFsImagesResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetFsImagesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest FsInfoList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetFsRootResponse parses an HTTP response from a GetFsRootWithResponse call
+func ParseGetFsRootResponse(rsp *http.Response) (*GetFsRootResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetFsRootResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest FsInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostImportResponse parses an HTTP response from a PostImportWithResponse call
+func ParsePostImportResponse(rsp *http.Response) (*PostImportResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostImportResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ImportResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetMachineResponse parses an HTTP response from a GetMachineWithResponse call
+func ParseGetMachineResponse(rsp *http.Response) (*GetMachineResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetMachineResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest MachineInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetMetricsResponse parses an HTTP response from a GetMetricsWithResponse call
+func ParseGetMetricsResponse(rsp *http.Response) (*GetMetricsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetMetricsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
+
+// ParseGetMetricsListResponse parses an HTTP response from a GetMetricsListWithResponse call
+func ParseGetMetricsListResponse(rsp *http.Response) (*GetMetricsListResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetMetricsListResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest MetricsList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostPushResponse parses an HTTP response from a PostPushWithResponse call
+func ParsePostPushResponse(rsp *http.Response) (*PostPushResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostPushResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest PushResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostRestoreResponse parses an HTTP response from a PostRestoreWithResponse call
+func ParsePostRestoreResponse(rsp *http.Response) (*PostRestoreResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostRestoreResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Backup
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetStatsSummaryResponse parses an HTTP response from a GetStatsSummaryWithResponse call
+func ParseGetStatsSummaryResponse(rsp *http.Response) (*GetStatsSummaryResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetStatsSummaryResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Summary
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetStreamResponse parses an HTTP response from a GetStreamWithResponse call
+func ParseGetStreamResponse(rsp *http.Response) (*GetStreamResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetStreamResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
+
+// ParseGetV1MetricsListResponse parses an HTTP response from a GetV1MetricsListWithResponse call
+func ParseGetV1MetricsListResponse(rsp *http.Response) (*GetV1MetricsListResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetV1MetricsListResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest MetricsList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetV2MetricsListResponse parses an HTTP response from a GetV2MetricsListWithResponse call
+func ParseGetV2MetricsListResponse(rsp *http.Response) (*GetV2MetricsListResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetV2MetricsListResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SeriesList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case rsp.StatusCode == 200:
+		// Content-type (text/plain) unsupported
+
+	}
+
+	return response, nil
+}
+
+// ParseGetVersionResponse parses an HTTP response from a GetVersionWithResponse call
+func ParseGetVersionResponse(rsp *http.Response) (*GetVersionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetVersionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest VersionInfo
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
@@ -0,0 +1,108 @@
+package client
+
+//go:generate sh ../../scripts/generate.sh
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultSocket is the unix socket the daemon serves the api on by default.
+const DefaultSocket = "stalker.sock"
+
+// unixServer is the server url of requests sent over a unix socket, whose
+// host is ignored.
+const unixServer = "http://stalker"
+
+// Dial returns a client of the daemon at address, either the path of its
+// unix socket, a unix:// url, a host:port or an http(s) url of its
+// --listen-address.
+func Dial(address string, opts ...ClientOption) (*ClientWithResponses, error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		return NewUnixClient(strings.TrimPrefix(address, "unix://"), opts...)
+	case strings.HasPrefix(address, "http://"), strings.HasPrefix(address, "https://"):
+		return NewTCPClient(address, nil, opts...)
+	}
+
+	_, port, err := net.SplitHostPort(address)
+	if err == nil && port != "" && !strings.Contains(address, "/") {
+		return NewTCPClient(address, nil, opts...)
+	}
+	return NewUnixClient(address, opts...)
+}
+
+// NewUnixClient returns a client of the daemon listening on the unix socket.
+func NewUnixClient(socket string, opts ...ClientOption) (*ClientWithResponses, error) {
+	dialer := &net.Dialer{}
+	doer := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	return NewClientWithResponses(unixServer, append([]ClientOption{WithHTTPClient(doer)}, opts...)...)
+}
+
+// NewTCPClient returns a client of the daemon serving the api on server, a
+// host:port or an http(s) url. tlsConfig configures https connections and may
+// be nil.
+func NewTCPClient(server string, tlsConfig *tls.Config, opts ...ClientOption) (*ClientWithResponses, error) {
+	if !strings.HasPrefix(server, "http://") && !strings.HasPrefix(server, "https://") {
+		server = "http://" + server
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	doer := &http.Client{Transport: transport}
+
+	return NewClientWithResponses(server, append([]ClientOption{WithHTTPClient(doer)}, opts...)...)
+}
+
+// WithBearerToken authenticates requests with the --api-token of the daemon.
+func WithBearerToken(token string) ClientOption {
+	return WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// WithBasicAuth authenticates requests with a username and password, e.g.
+// for daemons behind a proxy.
+func WithBasicAuth(username, password string) ClientOption {
+	return WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// ResponseError is returned for responses whose status has no typed body.
+type ResponseError struct {
+	Status  string
+	Message string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("stalker returned %s", e.Status)
+	}
+	return fmt.Sprintf("stalker returned %s: %s", e.Status, e.Message)
+}
+
+// CheckResponse returns a ResponseError carrying the message returned by
+// the daemon unless the body was decoded.
+func CheckResponse(status string, body []byte, decoded bool) error {
+	if decoded {
+		return nil
+	}
+	return &ResponseError{
+		Status:  status,
+		Message: strings.TrimSpace(string(body)),
+	}
+}
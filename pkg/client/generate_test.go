@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// generated are the files scripts/generate.sh writes from stalker.yaml.
+var generated = []string{
+	"internal/models/models.go",
+	"internal/api/api.go",
+	"pkg/client/client.go",
+}
+
+// TestGeneratedCodeIsCurrent regenerates the api, models and client into a
+// copy of the module and fails when they drifted from stalker.yaml.
+func TestGeneratedCodeIsCurrent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs oapi-codegen")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is required to run scripts/generate.sh")
+	}
+
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, file := range append([]string{"go.mod", "go.sum", "stalker.yaml", "scripts/generate.sh"}, generated...) {
+		copyFile(t, filepath.Join(root, file), filepath.Join(dir, file))
+	}
+
+	cmd := exec.Command("sh", "scripts/generate.sh")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("scripts/generate.sh failed: %v\n%s", err, output)
+	}
+
+	for _, file := range generated {
+		want, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s drifted from stalker.yaml, run make generate", file)
+		}
+	}
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(dst, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Columns of the flux records listed by /metricsList.
+const (
+	columnTime        = "_time"
+	columnMeasurement = "_measurement"
+	columnField       = "_field"
+	columnValue       = "_value"
+	columnFluxResult  = "result"
+	columnFluxTable   = "table"
+)
+
+// Record is a stored sample listed by /metricsList.
+type Record struct {
+	Time        time.Time         `json:"time"`
+	Measurement string            `json:"measurement"`
+	Field       string            `json:"field"`
+	Value       interface{}       `json:"value"`
+	Tags        map[string]string `json:"tags"`
+}
+
+// Records decodes the JSON encoded flux records of the list, whose columns
+// not starting with an underscore are tags.
+func (m MetricsList) Records() ([]Record, error) {
+	records := []Record{}
+	if m.Metrics == nil {
+		return records, nil
+	}
+
+	for _, metric := range *m.Metrics {
+		values := map[string]interface{}{}
+		err := json.Unmarshal([]byte(metric), &values)
+		if err != nil {
+			return nil, err
+		}
+
+		r := Record{
+			Tags: map[string]string{},
+		}
+		for k, v := range values {
+			switch k {
+			case columnTime:
+				s, _ := v.(string)
+				r.Time, _ = time.Parse(time.RFC3339Nano, s)
+			case columnMeasurement:
+				r.Measurement, _ = v.(string)
+			case columnField:
+				r.Field, _ = v.(string)
+			case columnValue:
+				r.Value = v
+			case columnFluxResult, columnFluxTable:
+			default:
+				tag, ok := v.(string)
+				if ok && !strings.HasPrefix(k, "_") {
+					r.Tags[k] = tag
+				}
+			}
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,179 @@
+package export
+
+import (
+	"io"
+	"sort"
+
+	"github.com/apache/arrow/go/v8/arrow"
+	"github.com/apache/arrow/go/v8/arrow/array"
+	"github.com/apache/arrow/go/v8/arrow/ipc"
+	"github.com/apache/arrow/go/v8/arrow/memory"
+	"github.com/apache/arrow/go/v8/parquet"
+	"github.com/apache/arrow/go/v8/parquet/compress"
+	"github.com/apache/arrow/go/v8/parquet/pqarrow"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+const (
+	// arrowBatchSize is the number of rows of an arrow record batch.
+	arrowBatchSize = 8192
+	// parquetRowGroupSize is the number of rows of a parquet row group, which
+	// is held in memory until it is complete.
+	parquetRowGroupSize = 65536
+)
+
+// schema of the columnar formats, which hold numeric values only.
+var schema = arrow.NewSchema([]arrow.Field{
+	{Name: "time", Type: arrow.FixedWidthTypes.Timestamp_ns},
+	{Name: "measurement", Type: arrow.BinaryTypes.String},
+	{Name: "field", Type: arrow.BinaryTypes.String},
+	{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "tags", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.BinaryTypes.String)},
+}, nil)
+
+// batchBuilder accumulates records into arrow record batches of a fixed
+// number of rows, each passed to flush.
+type batchBuilder struct {
+	builder *array.RecordBuilder
+	size    int
+	rows    int
+	flush   func(arrow.Record) error
+}
+
+func newBatchBuilder(size int, flush func(arrow.Record) error) *batchBuilder {
+	return &batchBuilder{
+		builder: array.NewRecordBuilder(memory.NewGoAllocator(), schema),
+		size:    size,
+		flush:   flush,
+	}
+}
+
+func (b *batchBuilder) add(record influx.Record) error {
+	value, ok := influx.ToFloat(record.Value)
+	if !ok {
+		return nil
+	}
+
+	b.builder.Field(0).(*array.TimestampBuilder).Append(arrow.Timestamp(record.Time.UnixNano()))
+	b.builder.Field(1).(*array.StringBuilder).Append(record.Measurement)
+	b.builder.Field(2).(*array.StringBuilder).Append(record.Field)
+	b.builder.Field(3).(*array.Float64Builder).Append(value)
+
+	tags := b.builder.Field(4).(*array.MapBuilder)
+	tags.Append(true)
+	keys := make([]string, 0, len(record.Tags))
+	for k := range record.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		tags.KeyBuilder().(*array.StringBuilder).Append(k)
+		tags.ItemBuilder().(*array.StringBuilder).Append(record.Tags[k])
+	}
+
+	b.rows++
+	if b.rows >= b.size {
+		return b.flushBatch()
+	}
+	return nil
+}
+
+func (b *batchBuilder) flushBatch() error {
+	if b.rows == 0 {
+		return nil
+	}
+	b.rows = 0
+
+	batch := b.builder.NewRecord()
+	defer batch.Release()
+	return b.flush(batch)
+}
+
+func (b *batchBuilder) release() {
+	b.builder.Release()
+}
+
+// arrowWriter writes an arrow IPC stream.
+type arrowWriter struct {
+	w     *ipc.Writer
+	batch *batchBuilder
+}
+
+func newArrowWriter(w io.Writer) *arrowWriter {
+	a := &arrowWriter{
+		w: ipc.NewWriter(w, ipc.WithSchema(schema)),
+	}
+	a.batch = newBatchBuilder(arrowBatchSize, a.w.Write)
+	return a
+}
+
+func (a *arrowWriter) Write(record influx.Record) error {
+	return a.batch.add(record)
+}
+
+func (a *arrowWriter) Close() error {
+	defer a.batch.release()
+
+	err := a.batch.flushBatch()
+	if err != nil {
+		return err
+	}
+	return a.w.Close()
+}
+
+// parquetWriter writes a snappy compressed parquet file, started with the
+// first row group or on Close.
+type parquetWriter struct {
+	out   io.Writer
+	w     *pqarrow.FileWriter
+	batch *batchBuilder
+}
+
+func newParquetWriter(w io.Writer) *parquetWriter {
+	p := &parquetWriter{out: w}
+	p.batch = newBatchBuilder(parquetRowGroupSize, p.writeRowGroup)
+	return p
+}
+
+func (p *parquetWriter) start() error {
+	if p.w != nil {
+		return nil
+	}
+
+	props := parquet.NewWriterProperties(
+		parquet.WithCompression(compress.Codecs.Snappy),
+		parquet.WithMaxRowGroupLength(parquetRowGroupSize),
+	)
+	w, err := pqarrow.NewFileWriter(schema, p.out, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return err
+	}
+	p.w = w
+	return nil
+}
+
+func (p *parquetWriter) writeRowGroup(batch arrow.Record) error {
+	err := p.start()
+	if err != nil {
+		return err
+	}
+	return p.w.Write(batch)
+}
+
+func (p *parquetWriter) Write(record influx.Record) error {
+	return p.batch.add(record)
+}
+
+func (p *parquetWriter) Close() error {
+	defer p.batch.release()
+
+	err := p.batch.flushBatch()
+	if err != nil {
+		return err
+	}
+	err = p.start()
+	if err != nil {
+		return err
+	}
+	return p.w.Close()
+}
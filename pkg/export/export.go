@@ -0,0 +1,128 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+// Formats records are exported in.
+const (
+	// FormatJson is left to the callers, which answer with structured series.
+	FormatJson         = "json"
+	FormatCsv          = "csv"
+	FormatLineProtocol = "lp"
+	FormatArrow        = "arrow"
+	FormatParquet      = "parquet"
+)
+
+// Media types of the formats.
+const (
+	mediaTypeJson         = "application/json"
+	mediaTypeCsv          = "text/csv"
+	mediaTypeLineProtocol = "text/plain"
+	mediaTypeArrow        = "application/vnd.apache.arrow.stream"
+	mediaTypeParquet      = "application/vnd.apache.parquet"
+)
+
+var mediaTypes = map[string]string{
+	mediaTypeJson:           FormatJson,
+	mediaTypeCsv:            FormatCsv,
+	mediaTypeLineProtocol:   FormatLineProtocol,
+	mediaTypeArrow:          FormatArrow,
+	mediaTypeParquet:        FormatParquet,
+	"application/x-parquet": FormatParquet,
+}
+
+var contentTypes = map[string]string{
+	FormatJson:         mediaTypeJson,
+	FormatCsv:          mediaTypeCsv + "; charset=utf-8",
+	FormatLineProtocol: mediaTypeLineProtocol + "; charset=utf-8",
+	FormatArrow:        mediaTypeArrow,
+	FormatParquet:      mediaTypeParquet,
+}
+
+// Negotiate returns the format named by format, or else the format of the
+// most preferred media type of the Accept header. It defaults to FormatJson.
+func Negotiate(format, accept string) (string, error) {
+	if format != "" {
+		if _, ok := contentTypes[format]; !ok {
+			return "", fmt.Errorf("unknown format %q, expected json, csv, lp, arrow or parquet", format)
+		}
+		return format, nil
+	}
+	if strings.TrimSpace(accept) == "" {
+		return FormatJson, nil
+	}
+
+	type candidate struct {
+		format string
+		q      float64
+	}
+	candidates := []candidate{}
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil || q <= 0 {
+				continue
+			}
+		}
+
+		switch {
+		case mediaType == "*/*" || mediaType == "application/*":
+			candidates = append(candidates, candidate{FormatJson, q})
+		case mediaType == "text/*":
+			candidates = append(candidates, candidate{FormatCsv, q})
+		default:
+			if f, ok := mediaTypes[mediaType]; ok {
+				candidates = append(candidates, candidate{f, q})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("none of the accepted media types %q is supported", accept)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].format, nil
+}
+
+// ContentType returns the Content-Type of a format.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Writer encodes records as they are written. Close completes the output,
+// nothing is written before the first record or Close.
+type Writer interface {
+	Write(influx.Record) error
+	Close() error
+}
+
+// NewWriter returns a writer encoding records in a format other than
+// FormatJson to w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCsv:
+		return newCsvWriter(w), nil
+	case FormatLineProtocol:
+		return newLineProtocolWriter(w), nil
+	case FormatArrow:
+		return newArrowWriter(w), nil
+	case FormatParquet:
+		return newParquetWriter(w), nil
+	}
+	return nil, fmt.Errorf("format %q cannot be streamed", format)
+}
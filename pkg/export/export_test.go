@@ -0,0 +1,293 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v8/arrow"
+	"github.com/apache/arrow/go/v8/arrow/array"
+	"github.com/apache/arrow/go/v8/arrow/ipc"
+	"github.com/apache/arrow/go/v8/arrow/memory"
+	"github.com/apache/arrow/go/v8/parquet/file"
+	"github.com/apache/arrow/go/v8/parquet/pqarrow"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+var testStart = time.Unix(1600000000, 500)
+
+var testRecords = []influx.Record{
+	{Time: testStart, Measurement: "memory_usage", Field: "value", Value: 1024.5, Tags: map[string]string{"container_name": "/web", "machine": "node-1"}},
+	{Time: testStart.Add(time.Second), Measurement: "cpu_usage_total", Field: "value", Value: uint64(42)},
+	{Time: testStart.Add(2 * time.Second), Measurement: "top_processes", Field: "command", Value: "nginx", Tags: map[string]string{"rank": "1"}},
+	{Time: testStart.Add(3 * time.Second), Measurement: "memory_failure", Field: "value", Value: int64(-3), Tags: map[string]string{"type": "pgfault"}},
+	{Time: testStart.Add(4 * time.Second), Measurement: "cpu_load_average", Field: "value", Value: true, Tags: map[string]string{"container_name": "/db web"}},
+}
+
+// row is a record as read back from a columnar format.
+type row struct {
+	time        time.Time
+	measurement string
+	field       string
+	value       float64
+	tags        string
+}
+
+// columnarRows are the numeric test records as the columnar formats hold
+// them.
+var columnarRows = []row{
+	{time: testStart, measurement: "memory_usage", field: "value", value: 1024.5, tags: "container_name=/web,machine=node-1"},
+	{time: testStart.Add(time.Second), measurement: "cpu_usage_total", field: "value", value: 42},
+	{time: testStart.Add(3 * time.Second), measurement: "memory_failure", field: "value", value: -3, tags: "type=pgfault"},
+	{time: testStart.Add(4 * time.Second), measurement: "cpu_load_average", field: "value", value: 1, tags: "container_name=/db web"},
+}
+
+func writeRecords(t *testing.T, format string, records []influx.Record) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(format, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		err = w.Write(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readRows appends the rows of a record batch of the export schema.
+func readRows(t *testing.T, rows []row, batch arrow.Record) []row {
+	t.Helper()
+	// Parquet adds field ids to the metadata of the columns.
+	for i, field := range batch.Schema().Fields() {
+		if want := schema.Field(i); field.Name != want.Name || !arrow.TypeEqual(field.Type, want.Type) {
+			t.Fatalf("got column %d %s of %v, want %s of %v", i, field.Name, field.Type, want.Name, want.Type)
+		}
+	}
+	times := batch.Column(0).(*array.Timestamp)
+	measurements := batch.Column(1).(*array.String)
+	fields := batch.Column(2).(*array.String)
+	values := batch.Column(3).(*array.Float64)
+	tags := batch.Column(4).(*array.Map)
+	keys := tags.Keys().(*array.String)
+	items := tags.Items().(*array.String)
+	offsets := tags.Offsets()
+
+	for i := 0; i < int(batch.NumRows()); i++ {
+		pairs := []string{}
+		for j := offsets[i]; j < offsets[i+1]; j++ {
+			pairs = append(pairs, keys.Value(int(j))+"="+items.Value(int(j)))
+		}
+		rows = append(rows, row{
+			time:        time.Unix(0, int64(times.Value(i))),
+			measurement: measurements.Value(i),
+			field:       fields.Value(i),
+			value:       values.Value(i),
+			tags:        strings.Join(pairs, ","),
+		})
+	}
+	return rows
+}
+
+func compareRows(t *testing.T, got, want []row) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].time.Equal(want[i].time) || got[i].measurement != want[i].measurement || got[i].field != want[i].field || got[i].value != want[i].value || got[i].tags != want[i].tags {
+			t.Errorf("row %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func readArrow(t *testing.T, data []byte) ([]row, int) {
+	t.Helper()
+	r, err := ipc.NewReader(bytes.NewReader(data), ipc.WithSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	rows := []row{}
+	batches := 0
+	for r.Next() {
+		rows = readRows(t, rows, r.Record())
+		batches++
+	}
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+	return rows, batches
+}
+
+func readParquet(t *testing.T, data []byte) ([]row, int) {
+	t.Helper()
+	pf, err := file.NewParquetReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pf.Close()
+
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.NewGoAllocator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer table.Release()
+
+	rows := []row{}
+	tr := array.NewTableReader(table, table.NumRows()+1)
+	defer tr.Release()
+	for tr.Next() {
+		rows = readRows(t, rows, tr.Record())
+	}
+	return rows, pf.NumRowGroups()
+}
+
+func TestCsvWriter(t *testing.T) {
+	got := string(writeRecords(t, FormatCsv, testRecords))
+	want := "time,measurement,field,value,tags\n" +
+		"2020-09-13T12:26:40.0000005Z,memory_usage,value,1024.5,\"container_name=/web,machine=node-1\"\n" +
+		"2020-09-13T12:26:41.0000005Z,cpu_usage_total,value,42,\n" +
+		"2020-09-13T12:26:42.0000005Z,top_processes,command,nginx,rank=1\n" +
+		"2020-09-13T12:26:43.0000005Z,memory_failure,value,-3,type=pgfault\n" +
+		"2020-09-13T12:26:44.0000005Z,cpu_load_average,value,true,container_name=/db web\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// An empty export still has its header.
+	if got := string(writeRecords(t, FormatCsv, nil)); got != "time,measurement,field,value,tags\n" {
+		t.Errorf("got %q for an empty export", got)
+	}
+}
+
+func TestLineProtocolWriter(t *testing.T) {
+	got := string(writeRecords(t, FormatLineProtocol, testRecords))
+	want := "memory_usage,container_name=/web,machine=node-1 value=1024.5 1600000000000000500\n" +
+		"cpu_usage_total value=42u 1600000001000000500\n" +
+		"top_processes,rank=1 command=\"nginx\" 1600000002000000500\n" +
+		"memory_failure,type=pgfault value=-3i 1600000003000000500\n" +
+		"cpu_load_average,container_name=/db\\ web value=true 1600000004000000500\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Line protocol has no NaN.
+	nan := influx.Record{Time: testStart, Measurement: "memory_usage", Field: "value", Value: math.NaN()}
+	if got := writeRecords(t, FormatLineProtocol, []influx.Record{nan, testRecords[1]}); string(got) != "cpu_usage_total value=42u 1600000001000000500\n" {
+		t.Errorf("got %q after a NaN value", got)
+	}
+
+	if got := writeRecords(t, FormatLineProtocol, nil); len(got) != 0 {
+		t.Errorf("got %q for an empty export", got)
+	}
+}
+
+func TestArrowWriter(t *testing.T) {
+	rows, batches := readArrow(t, writeRecords(t, FormatArrow, testRecords))
+	// The string value has no place in the float column.
+	compareRows(t, rows, columnarRows)
+	if batches != 1 {
+		t.Errorf("got %d batches, want 1", batches)
+	}
+
+	rows, batches = readArrow(t, writeRecords(t, FormatArrow, nil))
+	if len(rows) != 0 || batches != 0 {
+		t.Errorf("got %d rows in %d batches for an empty export", len(rows), batches)
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	rows, groups := readParquet(t, writeRecords(t, FormatParquet, testRecords))
+	compareRows(t, rows, columnarRows)
+	if groups != 1 {
+		t.Errorf("got %d row groups, want 1", groups)
+	}
+
+	// An empty export is still a parquet file.
+	rows, _ = readParquet(t, writeRecords(t, FormatParquet, nil))
+	if len(rows) != 0 {
+		t.Errorf("got %d rows for an empty export", len(rows))
+	}
+}
+
+func TestColumnarBatches(t *testing.T) {
+	records := []influx.Record{}
+	want := []row{}
+	for i := 0; i < parquetRowGroupSize+1; i++ {
+		tags := map[string]string{"container_name": fmt.Sprintf("/c%d", i%3)}
+		records = append(records, influx.Record{Time: testStart.Add(time.Duration(i) * time.Second), Measurement: "memory_usage", Field: "value", Value: float64(i), Tags: tags})
+		want = append(want, row{time: testStart.Add(time.Duration(i) * time.Second), measurement: "memory_usage", field: "value", value: float64(i), tags: "container_name=" + tags["container_name"]})
+	}
+
+	rows, batches := readArrow(t, writeRecords(t, FormatArrow, records))
+	compareRows(t, rows, want)
+	if wantBatches := parquetRowGroupSize/arrowBatchSize + 1; batches != wantBatches {
+		t.Errorf("got %d arrow batches, want %d", batches, wantBatches)
+	}
+
+	rows, groups := readParquet(t, writeRecords(t, FormatParquet, records))
+	compareRows(t, rows, want)
+	if groups != 2 {
+		t.Errorf("got %d row groups, want 2", groups)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		format string
+		accept string
+		want   string
+		err    bool
+	}{
+		{want: FormatJson},
+		{format: FormatParquet, accept: "text/csv", want: FormatParquet},
+		{format: "xml", err: true},
+		{accept: "text/csv", want: FormatCsv},
+		{accept: "text/plain; charset=utf-8", want: FormatLineProtocol},
+		{accept: "application/vnd.apache.arrow.stream", want: FormatArrow},
+		{accept: "application/x-parquet", want: FormatParquet},
+		{accept: "*/*", want: FormatJson},
+		{accept: "text/*", want: FormatCsv},
+		// The most preferred media type wins, ties go to the first listed.
+		{accept: "text/csv;q=0.5, application/vnd.apache.parquet", want: FormatParquet},
+		{accept: "text/csv;q=0.9, text/plain;q=0.8, */*;q=0.1", want: FormatCsv},
+		{accept: "application/json;q=0.2, text/plain;q=0.7, text/csv;q=0.7", want: FormatLineProtocol},
+		{accept: "text/csv, text/plain", want: FormatCsv},
+		// Unsupported, refused and malformed media types are skipped.
+		{accept: "text/html, text/csv;q=0.1", want: FormatCsv},
+		{accept: "text/csv;q=0, text/plain;q=0.1", want: FormatLineProtocol},
+		{accept: "text/csv;q=x, application/json;q=0.1", want: FormatJson},
+		{accept: "text/html", err: true},
+		{accept: "text/csv;q=0", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Negotiate(tt.format, tt.accept)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q %q: got %s, want an error", tt.format, tt.accept, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q %q: got %s %v, want %s", tt.format, tt.accept, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	lineprotocol "github.com/influxdata/line-protocol"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+var csvHeader = []string{"time", "measurement", "field", "value", "tags"}
+
+// csvWriter writes a row per record, with the tags as comma separated
+// key=value pairs.
+type csvWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func newCsvWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.w.Write(csvHeader)
+}
+
+func (c *csvWriter) Write(record influx.Record) error {
+	err := c.start()
+	if err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{
+		record.Time.UTC().Format(time.RFC3339Nano),
+		record.Measurement,
+		record.Field,
+		fmt.Sprint(record.Value),
+		formatTags(record.Tags),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	err := c.start()
+	if err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// lineProtocolWriter writes a line per record with nanosecond timestamps.
+// Records of NaN or infinite values, which line protocol cannot hold, are
+// left out.
+type lineProtocolWriter struct {
+	encoder *lineprotocol.Encoder
+}
+
+func newLineProtocolWriter(w io.Writer) *lineProtocolWriter {
+	encoder := lineprotocol.NewEncoder(w)
+	encoder.SetFieldTypeSupport(lineprotocol.UintSupport)
+	encoder.SetPrecision(time.Nanosecond)
+	return &lineProtocolWriter{encoder: encoder}
+}
+
+func (l *lineProtocolWriter) Write(record influx.Record) error {
+	point := write.NewPoint(record.Measurement, record.Tags, map[string]interface{}{record.Field: record.Value}, record.Time)
+	_, err := l.encoder.Encode(point)
+	if err == lineprotocol.ErrNoFields {
+		return nil
+	}
+	return err
+}
+
+func (l *lineProtocolWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,341 @@
+package filter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+
+	info "github.com/google/cadvisor/info/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Config lists the rules deciding which containers are collected. A container
+// is collected when it matches at least one include rule (or there are none)
+// and no exclude rule.
+type Config struct {
+	Include []RuleConfig `yaml:"include"`
+	Exclude []RuleConfig `yaml:"exclude"`
+}
+
+// RuleConfig matches containers on every criterion that is set.
+type RuleConfig struct {
+	// Glob on the cgroup path. * and ? stop at /, ** matches across it.
+	Cgroup string `yaml:"cgroup"`
+	// Regular expression on the container aliases, or on the cgroup path for
+	// containers without aliases.
+	Name string `yaml:"name"`
+	// Glob on the container image, with the same syntax as Cgroup. The glob
+	// may leave out leading path elements of the image, so nginx:* matches
+	// docker.io/library/nginx:1.21 as well as library/nginx:* does.
+	Image string `yaml:"image"`
+	// Label selector, e.g. "app=web,tier!=db,canary,!debug".
+	Labels string `yaml:"labels"`
+}
+
+// Filter is a compiled set of include and exclude rules. A nil Filter
+// collects every container.
+type Filter struct {
+	include []rule
+	exclude []rule
+}
+
+type rule struct {
+	cgroup *regexp.Regexp
+	name   *regexp.Regexp
+	image  *regexp.Regexp
+	labels []requirement
+}
+
+type requirement struct {
+	key   string
+	value string
+	op    string
+}
+
+const (
+	opEquals    = "="
+	opNotEquals = "!="
+	opExists    = "exists"
+	opNotExists = "!exists"
+)
+
+// New compiles a filter configuration.
+func New(config Config) (*Filter, error) {
+	include, err := compileRules(config.Include)
+	if err != nil {
+		return nil, fmt.Errorf("include: %w", err)
+	}
+
+	exclude, err := compileRules(config.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("exclude: %w", err)
+	}
+
+	return &Filter{
+		include: include,
+		exclude: exclude,
+	}, nil
+}
+
+// Load reads and compiles a YAML filter configuration.
+func Load(path string) (*Filter, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := Config{}
+	err = yaml.UnmarshalStrict(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	f, err := New(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Matches reports whether the container with the given cgroup path is collected.
+func (f *Filter) Matches(cgroup string, cInfo *info.ContainerInfo) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, r := range f.exclude {
+		if r.matches(cgroup, cInfo) {
+			return false
+		}
+	}
+
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, r := range f.include {
+		if r.matches(cgroup, cInfo) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileRules(configs []RuleConfig) ([]rule, error) {
+	rules := []rule{}
+	for i, config := range configs {
+		r, err := compileRule(config)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func compileRule(config RuleConfig) (rule, error) {
+	r := rule{}
+	if config.Cgroup == "" && config.Name == "" && config.Image == "" && config.Labels == "" {
+		return r, fmt.Errorf("rule has no criteria")
+	}
+
+	var err error
+	if config.Cgroup != "" {
+		r.cgroup, err = compileGlob(config.Cgroup)
+		if err != nil {
+			return r, fmt.Errorf("cgroup: %w", err)
+		}
+	}
+	if config.Name != "" {
+		r.name, err = regexp.Compile(config.Name)
+		if err != nil {
+			return r, fmt.Errorf("name: %w", err)
+		}
+	}
+	if config.Image != "" {
+		r.image, err = compileImageGlob(config.Image)
+		if err != nil {
+			return r, fmt.Errorf("image: %w", err)
+		}
+	}
+	if config.Labels != "" {
+		r.labels, err = parseSelector(config.Labels)
+		if err != nil {
+			return r, fmt.Errorf("labels: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+func (r rule) matches(cgroup string, cInfo *info.ContainerInfo) bool {
+	if r.cgroup != nil && !r.cgroup.MatchString(cgroup) {
+		return false
+	}
+	if r.name != nil && !matchesName(r.name, cgroup, cInfo.Spec.Aliases) {
+		return false
+	}
+	if r.image != nil && !r.image.MatchString(cInfo.Spec.Image) {
+		return false
+	}
+	for _, req := range r.labels {
+		if !req.matches(cInfo.Spec.Labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesName(name *regexp.Regexp, cgroup string, aliases []string) bool {
+	if len(aliases) == 0 {
+		return name.MatchString(cgroup)
+	}
+	for _, alias := range aliases {
+		if name.MatchString(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+func (req requirement) matches(labels map[string]string) bool {
+	value, ok := labels[req.key]
+	switch req.op {
+	case opEquals:
+		return ok && value == req.value
+	case opNotEquals:
+		return !ok || value != req.value
+	case opExists:
+		return ok
+	case opNotExists:
+		return !ok
+	}
+	return false
+}
+
+// parseSelector parses a comma separated list of key=value, key==value,
+// key!=value, key and !key requirements.
+func parseSelector(selector string) ([]requirement, error) {
+	reqs := []requirement{}
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		var req requirement
+		switch {
+		case term == "":
+			return nil, fmt.Errorf("empty requirement in %q", selector)
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			req = requirement{key: parts[0], value: parts[1], op: opNotEquals}
+		case strings.Contains(term, "=="):
+			parts := strings.SplitN(term, "==", 2)
+			req = requirement{key: parts[0], value: parts[1], op: opEquals}
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(term, "=", 2)
+			req = requirement{key: parts[0], value: parts[1], op: opEquals}
+		case strings.HasPrefix(term, "!"):
+			req = requirement{key: term[1:], op: opNotExists}
+		default:
+			req = requirement{key: term, op: opExists}
+		}
+
+		req.key = strings.TrimSpace(req.key)
+		req.value = strings.TrimSpace(req.value)
+		if req.key == "" {
+			return nil, fmt.Errorf("missing label key in %q", term)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// compileGlob turns a glob into an anchored regular expression. * and ? do
+// not match /, ** matches any sequence including /.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	return regexp.Compile("^" + globExpr(glob) + "$")
+}
+
+// compileImageGlob compiles a glob matching an image reference or any of its
+// suffixes starting after a /, so the registry and repository path of the
+// image may be left out.
+func compileImageGlob(glob string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:.*/)?" + globExpr(glob) + "$")
+}
+
+func globExpr(glob string) string {
+	var b strings.Builder
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// File is a filter loaded from a configuration file that can be reloaded
+// while collection is running.
+type File struct {
+	path string
+
+	mu     sync.RWMutex
+	filter *Filter
+}
+
+// NewFile loads the filter configuration at path. An empty path yields a file
+// that collects every container.
+func NewFile(path string) (*File, error) {
+	file := &File{path: path}
+	if path == "" {
+		return file, nil
+	}
+
+	err := file.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Reload re-reads the configuration file. The previous rules stay in effect
+// when the new configuration is invalid.
+func (f *File) Reload() error {
+	if f.path == "" {
+		return nil
+	}
+
+	filter, err := Load(f.path)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.filter = filter
+	f.mu.Unlock()
+
+	return nil
+}
+
+// Filter returns the rules currently in effect.
+func (f *File) Filter() *Filter {
+	if f == nil {
+		return nil
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.filter
+}
@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"testing"
+
+	info "github.com/google/cadvisor/info/v2"
+)
+
+func container(image string, labels map[string]string, aliases ...string) *info.ContainerInfo {
+	return &info.ContainerInfo{Spec: info.ContainerSpec{Image: image, Labels: labels, Aliases: aliases}}
+}
+
+func TestRuleMatches(t *testing.T) {
+	nginx := container("docker.io/library/nginx:1.21", map[string]string{"app": "web", "tier": "frontend"}, "web-1")
+	redis := container("registry:5000/cache/redis@sha256:abc", map[string]string{"app": "cache"})
+
+	tests := []struct {
+		name   string
+		rule   RuleConfig
+		cgroup string
+		c      *info.ContainerInfo
+		want   bool
+	}{
+		{name: "image short name", rule: RuleConfig{Image: "nginx*"}, c: nginx, want: true},
+		{name: "image short name and tag", rule: RuleConfig{Image: "nginx:1.*"}, c: nginx, want: true},
+		{name: "image repository", rule: RuleConfig{Image: "library/nginx:*"}, c: nginx, want: true},
+		{name: "image full reference", rule: RuleConfig{Image: "docker.io/library/nginx:1.21"}, c: nginx, want: true},
+		{name: "image any registry", rule: RuleConfig{Image: "**/nginx:1.21"}, c: nginx, want: true},
+		{name: "image glob stops at /", rule: RuleConfig{Image: "docker.io/*:1.21"}, c: nginx, want: false},
+		{name: "image partial element", rule: RuleConfig{Image: "inx*"}, c: nginx, want: false},
+		{name: "image other tag", rule: RuleConfig{Image: "nginx:1.20"}, c: nginx, want: false},
+		{name: "image digest", rule: RuleConfig{Image: "redis@*"}, c: redis, want: true},
+		{name: "cgroup glob", rule: RuleConfig{Cgroup: "/kubepods/*/pod*"}, cgroup: "/kubepods/burstable/pod1", c: nginx, want: true},
+		{name: "cgroup glob stops at /", rule: RuleConfig{Cgroup: "/kubepods/*"}, cgroup: "/kubepods/burstable/pod1", c: nginx, want: false},
+		{name: "cgroup glob is anchored", rule: RuleConfig{Cgroup: "burstable/**"}, cgroup: "/kubepods/burstable/pod1", c: nginx, want: false},
+		{name: "cgroup double star", rule: RuleConfig{Cgroup: "/kubepods/**"}, cgroup: "/kubepods/burstable/pod1", c: nginx, want: true},
+		{name: "name alias", rule: RuleConfig{Name: "^web-"}, cgroup: "/docker/abc", c: nginx, want: true},
+		{name: "name cgroup without aliases", rule: RuleConfig{Name: "^/docker/"}, cgroup: "/docker/abc", c: redis, want: true},
+		{name: "labels", rule: RuleConfig{Labels: "app=web,tier!=db,!debug,tier"}, c: nginx, want: true},
+		{name: "labels missing", rule: RuleConfig{Labels: "app==web"}, c: redis, want: false},
+		{name: "every criterion", rule: RuleConfig{Image: "nginx:*", Labels: "app=cache"}, c: nginx, want: false},
+	}
+
+	for _, tt := range tests {
+		r, err := compileRule(tt.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := r.matches(tt.cgroup, tt.c); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	f, err := New(Config{
+		Include: []RuleConfig{{Image: "nginx*"}, {Labels: "app=cache"}},
+		Exclude: []RuleConfig{{Labels: "debug"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		c    *info.ContainerInfo
+		want bool
+	}{
+		{c: container("docker.io/library/nginx:1.21", nil), want: true},
+		{c: container("redis", map[string]string{"app": "cache"}), want: true},
+		{c: container("docker.io/library/nginx:1.21", map[string]string{"debug": "true"}), want: false},
+		{c: container("postgres", nil), want: false},
+	}
+	for _, tt := range tests {
+		if got := f.Matches("/docker/abc", tt.c); got != tt.want {
+			t.Errorf("%s %v: got %v, want %v", tt.c.Spec.Image, tt.c.Spec.Labels, got, tt.want)
+		}
+	}
+
+	var none *Filter
+	if !none.Matches("/docker/abc", container("postgres", nil)) {
+		t.Error("a nil filter must collect every container")
+	}
+}
+
+func TestNewRejects(t *testing.T) {
+	for _, config := range []Config{
+		{Include: []RuleConfig{{}}},
+		{Exclude: []RuleConfig{{Name: "("}}},
+		{Include: []RuleConfig{{Labels: "app=web,,tier"}}},
+		{Include: []RuleConfig{{Labels: "=web"}}},
+	} {
+		if _, err := New(config); err == nil {
+			t.Errorf("New(%+v) succeeded", config)
+		}
+	}
+}
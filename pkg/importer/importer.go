@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	cadvisorapiv2 "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	lineprotocol "github.com/influxdata/line-protocol"
+)
+
+// BatchSize is the number of line protocol points passed to the callback of
+// ReadLineProtocol at once.
+const BatchSize = 5000
+
+// MalformedError is returned for bodies that cannot be decoded, as opposed
+// to the errors of the callbacks.
+type MalformedError struct {
+	Err error
+}
+
+func (e *MalformedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MalformedError) Unwrap() error {
+	return e.Err
+}
+
+var precisions = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// Precision returns the unit of line protocol timestamps named by ns, us, ms
+// or s. An empty name defaults to nanoseconds.
+func Precision(name string) (time.Duration, error) {
+	if name == "" {
+		return time.Nanosecond, nil
+	}
+	precision, ok := precisions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown precision %q, expected ns, us, ms or s", name)
+	}
+	return precision, nil
+}
+
+// ReadLineProtocol parses line protocol from r and calls fn with batches of
+// at most BatchSize points. Lines without a timestamp are stamped with the
+// time they are read.
+func ReadLineProtocol(r io.Reader, precision time.Duration, fn func([]*write.Point) error) error {
+	parser := lineprotocol.NewStreamParser(r)
+	parser.SetTimePrecision(precision)
+
+	batch := make([]*write.Point, 0, BatchSize)
+	for {
+		metric, err := parser.Next()
+		if err == lineprotocol.EOF {
+			break
+		}
+		if err != nil {
+			return &MalformedError{Err: err}
+		}
+
+		tags := map[string]string{}
+		for _, tag := range metric.TagList() {
+			tags[tag.Key] = tag.Value
+		}
+		fields := map[string]interface{}{}
+		for _, field := range metric.FieldList() {
+			fields[field.Key] = field.Value
+		}
+		batch = append(batch, write.NewPoint(metric.Name(), tags, fields, metric.Time()))
+
+		if len(batch) == BatchSize {
+			err := fn(batch)
+			if err != nil {
+				return err
+			}
+			batch = make([]*write.Point, 0, BatchSize)
+		}
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return fn(batch)
+}
+
+// ReadContainerInfos decodes the container infos returned by cadvisor
+// ContainerInfoV2, keyed by container name, from r and calls fn with each of
+// them. Several concatenated dumps, e.g. captured periodically, are read in
+// turn.
+func ReadContainerInfos(r io.Reader, fn func(map[string]cadvisorapiv2.ContainerInfo) error) error {
+	decoder := json.NewDecoder(r)
+	for {
+		infos := map[string]cadvisorapiv2.ContainerInfo{}
+		err := decoder.Decode(&infos)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return &MalformedError{Err: fmt.Errorf("invalid container info dump: %w", err)}
+		}
+
+		err = fn(infos)
+		if err != nil {
+			return err
+		}
+	}
+}
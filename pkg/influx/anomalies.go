@@ -0,0 +1,120 @@
+package influx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// Annotations of points deviating from the baseline of their series
+const serAnomalies string = "anomalies"
+
+// Anomaly tag and field names
+const (
+	tagAnomalySeries string = "anomaly_series"
+	tagAnomalyMethod string = "anomaly_method"
+	fieldExpected    string = "expected"
+	fieldDeviation   string = "deviation"
+)
+
+// Anomaly is a sample that deviated from the baseline of its series.
+type Anomaly struct {
+	Timestamp time.Time
+	// Series is the name of the series, whose per second rate was analyzed
+	// when it is cumulative.
+	Series string
+	Method string
+	// Tags are the tags of the series.
+	Tags map[string]string
+	// Value is the observed value, Expected the baseline and Deviation the
+	// distance between both in standard deviations.
+	Value     float64
+	Expected  float64
+	Deviation float64
+}
+
+// AnomalyToPoint converts an anomaly into a point of the anomalies
+// measurement, tagged like the series it annotates.
+func AnomalyToPoint(anomaly Anomaly) *write.Point {
+	tags := map[string]string{}
+	for k, v := range anomaly.Tags {
+		tags[k] = v
+	}
+	tags[tagAnomalySeries] = anomaly.Series
+	tags[tagAnomalyMethod] = anomaly.Method
+
+	fields := map[string]interface{}{
+		fieldValue:     anomaly.Value,
+		fieldExpected:  anomaly.Expected,
+		fieldDeviation: anomaly.Deviation,
+	}
+
+	return write.NewPoint(serAnomalies, tags, fields, anomaly.Timestamp)
+}
+
+// GetAnomalies returns the anomalies stored between start and stop, oldest
+// first. An empty series matches every series.
+func (s *CAdvisorClient) GetAnomalies(ctx context.Context, start, stop time.Time, series []string) ([]Anomaly, error) {
+	ctx, cancel := context.WithTimeout(ctx, eventsQueryTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`from(bucket:%q)
+  |> range(start: %s, stop: %s)
+  |> filter(fn: (r) => r._measurement == %q)`,
+		s.bucket, start.UTC().Format(time.RFC3339Nano), stop.UTC().Format(time.RFC3339Nano), serAnomalies)
+	if len(series) > 0 {
+		conditions := []string{}
+		for _, name := range series {
+			conditions = append(conditions, fmt.Sprintf("r.%s == %q", tagAnomalySeries, name))
+		}
+		query += fmt.Sprintf("\n  |> filter(fn: (r) => %s)", strings.Join(conditions, " or "))
+	}
+	query += `
+  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+  |> group()
+  |> sort(columns: ["_time"])`
+
+	result, err := s.client.QueryAPI(s.org).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := []Anomaly{}
+	for result.Next() {
+		values := result.Record().Values()
+		anomaly := Anomaly{
+			Tags: map[string]string{},
+		}
+		for k, v := range values {
+			switch k {
+			case columnTime:
+				anomaly.Timestamp, _ = v.(time.Time)
+			case tagAnomalySeries:
+				anomaly.Series, _ = v.(string)
+			case tagAnomalyMethod:
+				anomaly.Method, _ = v.(string)
+			case fieldValue:
+				anomaly.Value, _ = ToFloat(v)
+			case fieldExpected:
+				anomaly.Expected, _ = ToFloat(v)
+			case fieldDeviation:
+				anomaly.Deviation, _ = ToFloat(v)
+			case columnFluxResult, columnFluxTable:
+			default:
+				tag, ok := v.(string)
+				if ok && !strings.HasPrefix(k, "_") {
+					anomaly.Tags[k] = tag
+				}
+			}
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return anomalies, nil
+}
@@ -5,9 +5,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
+	cadvisormetrics "github.com/google/cadvisor/container"
 	info "github.com/google/cadvisor/info/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api/write"
 
@@ -17,7 +19,7 @@ import (
 var argDbRetentionPolicy = flag.String("storage_driver_influxdb_retention_policy", "", "retention policy")
 
 type CAdvisorClient struct {
-	machineName    string
+	*PointConverter
 	client         influxdb_client_go.Client
 	lastWrite      time.Time
 	org            string
@@ -51,6 +53,8 @@ const (
 	serMemoryMappedFile string = "memory_mapped_file"
 	// Working set size
 	serMemoryWorkingSet string = "memory_working_set"
+	// Memory limit of the container, absent when unlimited
+	serMemoryLimit string = "memory_limit"
 	// Number of memory usage hits limits
 	serMemoryFailcnt string = "memory_failcnt"
 	// Cumulative count of memory allocation failures
@@ -63,10 +67,18 @@ const (
 	serTxBytes string = "tx_bytes"
 	// Cumulative count of transmit errors encountered.
 	serTxErrors string = "tx_errors"
-	// Filesystem limit.
-	serFsLimit string = "fs_limit"
 	// Filesystem usage.
 	serFsUsage string = "fs_usage"
+	// Filesystem usage of the container root filesystem.
+	serFsBaseUsage string = "fs_base_usage"
+	// Inodes used by the container root filesystem.
+	serFsInodesUsage string = "fs_inodes_usage"
+	// Cumulative count of bytes read from and written to block devices.
+	serDiskIoBytes string = "disk_io_bytes"
+	// Number of TCP connections by state.
+	serTcpConnections string = "tcp_connections"
+	// Cumulative count of OOM events.
+	serOomEvents string = "oom_events"
 	// Hugetlb stat - current res_counter usage for hugetlb
 	setHugetlbUsage = "hugetlb_usage"
 	// Hugetlb stat - maximum usage ever recorded
@@ -85,6 +97,94 @@ const (
 	serResctrlLLCOccupancy = "resctrl_llc_occupancy"
 )
 
+// Series whose values only ever grow over the lifetime of a container.
+var cumulativeSeries = map[string]bool{
+	serCPUUsageTotal:               true,
+	serCPUUsageSystem:              true,
+	serCPUUsageUser:                true,
+	serCPUUsagePerCPU:              true,
+	serMemoryFailcnt:               true,
+	serMemoryFailure:               true,
+	serRxBytes:                     true,
+	serRxErrors:                    true,
+	serTxBytes:                     true,
+	serTxErrors:                    true,
+	serDiskIoBytes:                 true,
+	serOomEvents:                   true,
+	serCPUCfsPeriods:               true,
+	serCPUCfsThrottledPeriods:      true,
+	serCPUCfsThrottledTime:         true,
+	serPressureTotal:               true,
+	setHugetlbFailcnt:              true,
+	serPerfStat:                    true,
+	serResctrlMemoryBandwidthTotal: true,
+	serResctrlMemoryBandwidthLocal: true,
+}
+
+// IsCumulative reports whether the named series is a monotonically increasing
+// counter rather than a point-in-time gauge.
+func IsCumulative(series string) bool {
+	return cumulativeSeries[series]
+}
+
+// Series of limits rather than usage, which do not add up over containers.
+var limitSeries = map[string]bool{
+	serMemoryLimit:       true,
+	serProcessThreadsMax: true,
+	serProcessUlimit:     true,
+}
+
+// IsLimit reports whether the named series is a limit of a container.
+func IsLimit(series string) bool {
+	return limitSeries[series]
+}
+
+// Series of the network namespace, which every container of a pod shares.
+var podSharedSeries = map[string]bool{
+	serRxBytes:        true,
+	serRxErrors:       true,
+	serTxBytes:        true,
+	serTxErrors:       true,
+	serTcpConnections: true,
+}
+
+// IsPodShared reports whether the containers of a pod report the same values
+// of the named series.
+func IsPodShared(series string) bool {
+	return podSharedSeries[series]
+}
+
+// Tags telling apart the series of a measurement collected from the same
+// container, as opposed to the tags of the container and its labels.
+var seriesTags = map[string]bool{
+	"instance":      true,
+	"failure_type":  true,
+	"scope":         true,
+	"ip_version":    true,
+	"state":         true,
+	"operation":     true,
+	"page_size":     true,
+	"cpu":           true,
+	"name":          true,
+	"scaling_ratio": true,
+	"node_id":       true,
+	fieldType:       true,
+	fieldDevice:     true,
+	tagMountpoint:   true,
+	tagUlimit:       true,
+	tagRank:         true,
+	tagSortBy:       true,
+	tagResource:     true,
+	tagKind:         true,
+	tagWindow:       true,
+}
+
+// IsSeriesTag reports whether the named tag tells apart series of the same
+// container.
+func IsSeriesTag(tag string) bool {
+	return seriesTags[tag]
+}
+
 type CAdvisorClientParams struct {
 	Token string
 	Uri   string
@@ -101,17 +201,115 @@ const (
 	fieldDevice string = "device"
 )
 
+// UnlimitedMemory is the smallest memory limit treated as no limit at all.
+const UnlimitedMemory = 1 << 62
+
+// ValueField is the name of the single field every series point carries.
+const ValueField = fieldValue
+
 // Tag names
 const (
-	tagMachineName   string = "machine"
-	tagContainerName string = "container_name"
+	tagMachineName    string = "machine"
+	tagContainerName  string = "container_name"
+	tagContainerImage string = "container_image"
 )
 
-func (s *CAdvisorClient) ContainerFilesystemStatsToPoints(
+// Tags every series point carries to identify where it was collected.
+const (
+	TagMachineName    = tagMachineName
+	TagContainerName  = tagContainerName
+	TagContainerImage = tagContainerImage
+)
+
+// PointConverter turns cadvisor container stats into influx points tagged
+// with the machine and container they were collected from.
+type PointConverter struct {
+	machineName     string
+	includedMetrics cadvisormetrics.MetricSet
+
+	labelPolicyLock sync.RWMutex
+	labelPolicy     *LabelPolicy
+}
+
+// NewPointConverter creates a converter that tags points with the local hostname.
+func NewPointConverter() (*PointConverter, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PointConverter{
+		machineName: hostname,
+	}, nil
+}
+
+// WithMachineName returns a converter of the same metric sets and label
+// policy tagging points with another machine, e.g. for stats collected on
+// other hosts.
+func (s *PointConverter) WithMachineName(machineName string) *PointConverter {
+	s.labelPolicyLock.RLock()
+	defer s.labelPolicyLock.RUnlock()
+
+	return &PointConverter{
+		machineName:     machineName,
+		includedMetrics: s.includedMetrics,
+		labelPolicy:     s.labelPolicy,
+	}
+}
+
+// SetIncludedMetrics restricts conversion to the metric sets cadvisor
+// collects, so disabled sets do not produce zero valued points. It must be
+// called before conversion starts, a nil set converts everything.
+func (s *PointConverter) SetIncludedMetrics(includedMetrics cadvisormetrics.MetricSet) {
+	s.includedMetrics = includedMetrics
+}
+
+// Includes reports whether the metric set is converted.
+func (s *PointConverter) Includes(kind cadvisormetrics.MetricKind) bool {
+	return s.includedMetrics == nil || s.includedMetrics.Has(kind)
+}
+
+// SetLabelPolicy replaces the policy applied to container labels. A nil
+// policy tags points with every label.
+func (s *PointConverter) SetLabelPolicy(policy *LabelPolicy) {
+	s.labelPolicyLock.Lock()
+	defer s.labelPolicyLock.Unlock()
+
+	s.labelPolicy = policy
+}
+
+func (s *PointConverter) labelTags(labels map[string]string) map[string]string {
+	s.labelPolicyLock.RLock()
+	defer s.labelPolicyLock.RUnlock()
+
+	return s.labelPolicy.Apply(labels)
+}
+
+// StatsToPoints converts every supported stats family of a container sample into points.
+func (s *PointConverter) StatsToPoints(cInfo *info.ContainerInfo, stats *info.ContainerStats) (points []*write.Point) {
+	if stats == nil {
+		return nil
+	}
+
+	points = append(points, s.ContainerStatsToPoints(cInfo, stats)...)
+	points = append(points, s.CpuCfsStatsToPoints(cInfo, stats)...)
+	points = append(points, s.MemoryStatsToPoints(cInfo, stats)...)
+	points = append(points, s.NetworkTcpStatsToPoints(cInfo, stats)...)
+	points = append(points, s.DiskIoStatsToPoints(cInfo, stats)...)
+	points = append(points, s.ProcessStatsToPoints(cInfo, stats)...)
+	points = append(points, s.HugetlbStatsToPoints(cInfo, stats)...)
+	points = append(points, s.PerfStatsToPoints(cInfo, stats)...)
+	points = append(points, s.ResctrlStatsToPoints(cInfo, stats)...)
+	points = append(points, s.ContainerFilesystemStatsToPoints(cInfo, stats)...)
+
+	return points
+}
+
+func (s *PointConverter) ContainerFilesystemStatsToPoints(
 	cInfo *info.ContainerInfo,
 	stats *info.ContainerStats) (points []*write.Point) {
 
-	if !cInfo.Spec.HasFilesystem {
+	if !cInfo.Spec.HasFilesystem || !s.Includes(cadvisormetrics.DiskUsageMetrics) {
 		return nil
 	}
 
@@ -119,34 +317,40 @@ func (s *CAdvisorClient) ContainerFilesystemStatsToPoints(
 	if fsStat == nil {
 		return nil
 	}
-	tagsFsUsage := map[string]string{
-		fieldType: "usage",
-	}
 
-	fieldsFsUsage := map[string]interface{}{
-		fieldValue: fsStat.TotalUsageBytes,
+	defaultTags := s.DefaultTags(cInfo, stats)
+	if fsStat.TotalUsageBytes != nil {
+		point := makePoint(serFsUsage, defaultTags, *fsStat.TotalUsageBytes, stats.Timestamp)
+		addTagsToPoint(point, map[string]string{fieldType: "usage"})
+		points = append(points, point)
 	}
-
-	pointFsUsage := write.NewPoint(serFsUsage, tagsFsUsage, fieldsFsUsage, stats.Timestamp)
-
-	tagsFsLimit := map[string]string{
-		fieldDevice: "", //fsStat.Device,
-		fieldType:   "limit",
+	if fsStat.BaseUsageBytes != nil {
+		points = append(points, makePoint(serFsBaseUsage, defaultTags, *fsStat.BaseUsageBytes, stats.Timestamp))
 	}
-	fieldsFsLimit := map[string]interface{}{
-		fieldValue: "", //int64(fsStat.Limit),
+	if fsStat.InodeUsage != nil {
+		points = append(points, makePoint(serFsInodesUsage, defaultTags, *fsStat.InodeUsage, stats.Timestamp))
 	}
-	pointFsLimit := write.NewPoint(serFsLimit, tagsFsLimit, fieldsFsLimit, stats.Timestamp)
-	points = append(points, pointFsUsage, pointFsLimit)
 
 	s.TagPoints(cInfo, stats, points)
 
 	return points
 }
 
+// RootContainerName is the container_name of the root cgroup, which holds
+// the usage of the whole machine and has neither aliases nor an image.
+const RootContainerName = "/"
+
+// TagRootPoints names the points of the root cgroup, whose series would
+// otherwise be shared with every other cgroup without a container name.
+func TagRootPoints(points []*write.Point) {
+	for _, point := range points {
+		point.AddTag(tagContainerName, RootContainerName)
+	}
+}
+
 // Set tags and timestamp for all points of the batch.
 // Points should inherit the tags that are set for BatchPoints, but that does not seem to work.
-func (s *CAdvisorClient) TagPoints(cInfo *info.ContainerInfo, stats *info.ContainerStats, points []*write.Point) {
+func (s *PointConverter) TagPoints(cInfo *info.ContainerInfo, stats *info.ContainerStats, points []*write.Point) {
 	// Use container alias if possible
 	var containerName string
 	if len(cInfo.Spec.Aliases) > 0 {
@@ -156,19 +360,21 @@ func (s *CAdvisorClient) TagPoints(cInfo *info.ContainerInfo, stats *info.Contai
 	}
 
 	commonTags := map[string]string{
-		tagMachineName:   s.machineName,
-		tagContainerName: containerName,
+		tagMachineName:    s.machineName,
+		tagContainerName:  containerName,
+		tagContainerImage: cInfo.Spec.Image,
 	}
+	labelTags := s.labelTags(cInfo.Spec.Labels)
 	for i := 0; i < len(points); i++ {
-		// merge with existing tags if any
+		// merge with existing tags if any, labels must not shadow the common tags
+		addTagsToPoint(points[i], labelTags)
 		addTagsToPoint(points[i], commonTags)
-		addTagsToPoint(points[i], cInfo.Spec.Labels)
 	}
 }
 
 // Set tags and timestamp for all points of the batch.
 // Points should inherit the tags that are set for BatchPoints, but that does not seem to work.
-func (s *CAdvisorClient) DefaultTags(cInfo *info.ContainerInfo, stats *info.ContainerStats) map[string]string {
+func (s *PointConverter) DefaultTags(cInfo *info.ContainerInfo, stats *info.ContainerStats) map[string]string {
 	// Use container alias if possible
 	var containerName string
 	if len(cInfo.Spec.Aliases) > 0 {
@@ -178,59 +384,73 @@ func (s *CAdvisorClient) DefaultTags(cInfo *info.ContainerInfo, stats *info.Cont
 	}
 
 	commonTags := map[string]string{
-		tagMachineName:   s.machineName,
-		tagContainerName: containerName,
+		tagMachineName:    s.machineName,
+		tagContainerName:  containerName,
+		tagContainerImage: cInfo.Spec.Image,
 	}
 
 	return commonTags
 }
 
-func (s *CAdvisorClient) ContainerStatsToPoints(
+func (s *PointConverter) ContainerStatsToPoints(
 	cInfo *info.ContainerInfo,
 	stats *info.ContainerStats,
 ) (points []*write.Point) {
 
 	defaultTags := s.DefaultTags(cInfo, stats)
-	// CPU usage: Total usage in nanoseconds
-	points = append(points, makePoint(serCPUUsageTotal, defaultTags, stats.Cpu.Usage.Total, stats.Timestamp))
 
-	// CPU usage: Time spend in system space (in nanoseconds)
-	points = append(points, makePoint(serCPUUsageSystem, defaultTags, stats.Cpu.Usage.System, stats.Timestamp))
+	if stats.Cpu != nil && s.Includes(cadvisormetrics.CpuUsageMetrics) {
+		// CPU usage: Total usage in nanoseconds
+		points = append(points, makePoint(serCPUUsageTotal, defaultTags, stats.Cpu.Usage.Total, stats.Timestamp))
 
-	// CPU usage: Time spent in user space (in nanoseconds)
-	points = append(points, makePoint(serCPUUsageUser, defaultTags, stats.Cpu.Usage.User, stats.Timestamp))
+		// CPU usage: Time spend in system space (in nanoseconds)
+		points = append(points, makePoint(serCPUUsageSystem, defaultTags, stats.Cpu.Usage.System, stats.Timestamp))
 
-	// CPU usage per CPU
-	for i := 0; i < len(stats.Cpu.Usage.PerCpu); i++ {
+		// CPU usage: Time spent in user space (in nanoseconds)
+		points = append(points, makePoint(serCPUUsageUser, defaultTags, stats.Cpu.Usage.User, stats.Timestamp))
 
-		point := makePoint(serCPUUsagePerCPU, defaultTags, stats.Cpu.Usage.PerCpu[i], stats.Timestamp)
-		tags := map[string]string{"instance": fmt.Sprintf("%v", i)}
-		addTagsToPoint(point, tags)
-		points = append(points, point)
-	}
+		// CPU usage per CPU
+		for i := 0; i < len(stats.Cpu.Usage.PerCpu); i++ {
+
+			point := makePoint(serCPUUsagePerCPU, defaultTags, stats.Cpu.Usage.PerCpu[i], stats.Timestamp)
+			tags := map[string]string{"instance": fmt.Sprintf("%v", i)}
+			addTagsToPoint(point, tags)
+			points = append(points, point)
+		}
 
-	// Load Average
-	points = append(points, makePoint(serLoadAverage, defaultTags, stats.Cpu.LoadAverage, stats.Timestamp))
+		// Load Average
+		if s.Includes(cadvisormetrics.CpuLoadMetrics) {
+			points = append(points, makePoint(serLoadAverage, defaultTags, stats.Cpu.LoadAverage, stats.Timestamp))
+		}
+	}
 
 	// Network Stats
-	points = append(points, makePoint(serRxBytes, defaultTags, stats.Network.Interfaces[0].RxBytes, stats.Timestamp))
-	points = append(points, makePoint(serRxErrors, defaultTags, stats.Network.Interfaces[0].RxErrors, stats.Timestamp))
-	points = append(points, makePoint(serTxBytes, defaultTags, stats.Network.Interfaces[0].TxBytes, stats.Timestamp))
-	points = append(points, makePoint(serTxErrors, defaultTags, stats.Network.Interfaces[0].TxErrors, stats.Timestamp))
+	if stats.Network != nil && len(stats.Network.Interfaces) > 0 && s.Includes(cadvisormetrics.NetworkUsageMetrics) {
+		points = append(points, makePoint(serRxBytes, defaultTags, stats.Network.Interfaces[0].RxBytes, stats.Timestamp))
+		points = append(points, makePoint(serRxErrors, defaultTags, stats.Network.Interfaces[0].RxErrors, stats.Timestamp))
+		points = append(points, makePoint(serTxBytes, defaultTags, stats.Network.Interfaces[0].TxBytes, stats.Timestamp))
+		points = append(points, makePoint(serTxErrors, defaultTags, stats.Network.Interfaces[0].TxErrors, stats.Timestamp))
+	}
 
 	// Referenced Memory
-	points = append(points, makePoint(serReferencedMemory, defaultTags, stats.ReferencedMemory, stats.Timestamp))
+	if s.Includes(cadvisormetrics.ReferencedMemoryMetrics) {
+		points = append(points, makePoint(serReferencedMemory, defaultTags, stats.ReferencedMemory, stats.Timestamp))
+	}
 
 	s.TagPoints(cInfo, stats, points)
 
 	return points
 }
 
-func (s *CAdvisorClient) MemoryStatsToPoints(
+func (s *PointConverter) MemoryStatsToPoints(
 	cInfo *info.ContainerInfo,
 	stats *info.ContainerStats,
 ) (points []*write.Point) {
 
+	if stats.Memory == nil || !s.Includes(cadvisormetrics.MemoryUsageMetrics) {
+		return nil
+	}
+
 	defaultTags := s.DefaultTags(cInfo, stats)
 
 	// Memory Usage
@@ -247,6 +467,11 @@ func (s *CAdvisorClient) MemoryStatsToPoints(
 	points = append(points, makePoint(serMemoryMappedFile, defaultTags, stats.Memory.MappedFile, stats.Timestamp))
 	// Working Set Size
 	points = append(points, makePoint(serMemoryWorkingSet, defaultTags, stats.Memory.WorkingSet, stats.Timestamp))
+	// Memory limit, unlimited containers report a limit close to the maximum
+	// value of the counter
+	if cInfo.Spec.HasMemory && cInfo.Spec.Memory.Limit > 0 && cInfo.Spec.Memory.Limit < UnlimitedMemory {
+		points = append(points, makePoint(serMemoryLimit, defaultTags, cInfo.Spec.Memory.Limit, stats.Timestamp))
+	}
 	// Number of memory usage hits limits
 	points = append(points, makePoint(serMemoryFailcnt, defaultTags, stats.Memory.Failcnt, stats.Timestamp))
 
@@ -275,15 +500,106 @@ func (s *CAdvisorClient) MemoryStatsToPoints(
 	addTagsToPoint(memoryFailurePoint, memoryFailuresTags)
 	points = append(points, memoryFailurePoint)
 
+	s.TagPoints(cInfo, stats, points)
+
 	return points
 }
 
-func (s *CAdvisorClient) HugetlbStatsToPoints(
+func (s *PointConverter) NetworkTcpStatsToPoints(
 	cInfo *info.ContainerInfo,
 	stats *info.ContainerStats,
 ) (points []*write.Point) {
 
-	if !cInfo.Spec.HasHugetlb {
+	if stats.Network == nil || !s.Includes(cadvisormetrics.NetworkTcpUsageMetrics) {
+		return nil
+	}
+
+	defaultTags := s.DefaultTags(cInfo, stats)
+
+	for ipVersion, tcp := range map[string]info.TcpStat{"4": stats.Network.Tcp, "6": stats.Network.Tcp6} {
+		states := map[string]uint64{
+			"established": tcp.Established,
+			"syn_sent":    tcp.SynSent,
+			"syn_recv":    tcp.SynRecv,
+			"fin_wait1":   tcp.FinWait1,
+			"fin_wait2":   tcp.FinWait2,
+			"time_wait":   tcp.TimeWait,
+			"close":       tcp.Close,
+			"close_wait":  tcp.CloseWait,
+			"last_ack":    tcp.LastAck,
+			"listen":      tcp.Listen,
+			"closing":     tcp.Closing,
+		}
+		for state, count := range states {
+			point := makePoint(serTcpConnections, defaultTags, count, stats.Timestamp)
+			addTagsToPoint(point, map[string]string{
+				"ip_version": ipVersion,
+				"state":      state,
+			})
+			points = append(points, point)
+		}
+	}
+
+	s.TagPoints(cInfo, stats, points)
+
+	return points
+}
+
+func (s *PointConverter) DiskIoStatsToPoints(
+	cInfo *info.ContainerInfo,
+	stats *info.ContainerStats,
+) (points []*write.Point) {
+
+	if stats.DiskIo == nil || !s.Includes(cadvisormetrics.DiskIOMetrics) {
+		return nil
+	}
+
+	defaultTags := s.DefaultTags(cInfo, stats)
+
+	for _, disk := range stats.DiskIo.IoServiceBytes {
+		device := disk.Device
+		if device == "" {
+			device = fmt.Sprintf("%d:%d", disk.Major, disk.Minor)
+		}
+		for _, operation := range []string{"Read", "Write"} {
+			value, ok := disk.Stats[operation]
+			if !ok {
+				continue
+			}
+			point := makePoint(serDiskIoBytes, defaultTags, value, stats.Timestamp)
+			addTagsToPoint(point, map[string]string{
+				fieldDevice: device,
+				"operation": strings.ToLower(operation),
+			})
+			points = append(points, point)
+		}
+	}
+
+	s.TagPoints(cInfo, stats, points)
+
+	return points
+}
+
+// OomEventsToPoint converts the number of OOM events seen for a container
+// into a cumulative point.
+func (s *PointConverter) OomEventsToPoint(
+	cInfo *info.ContainerInfo,
+	stats *info.ContainerStats,
+	count uint64,
+) *write.Point {
+
+	point := makePoint(serOomEvents, s.DefaultTags(cInfo, stats), count, stats.Timestamp)
+	s.TagPoints(cInfo, stats, []*write.Point{point})
+
+	return point
+}
+
+func (s *PointConverter) HugetlbStatsToPoints(
+	cInfo *info.ContainerInfo,
+	stats *info.ContainerStats,
+) (points []*write.Point) {
+
+	if !cInfo.Spec.HasHugetlb || stats.Hugetlb == nil || !s.Includes(cadvisormetrics.HugetlbUsageMetrics) {
 		return nil
 	}
 
@@ -316,11 +632,15 @@ func (s *CAdvisorClient) HugetlbStatsToPoints(
 	return points
 }
 
-func (s *CAdvisorClient) PerfStatsToPoints(
+func (s *PointConverter) PerfStatsToPoints(
 	cInfo *info.ContainerInfo,
 	stats *info.ContainerStats,
 ) (points []*write.Point) {
 
+	if !s.Includes(cadvisormetrics.PerfMetrics) {
+		return nil
+	}
+
 	defaultTags := s.DefaultTags(cInfo, stats)
 
 	for _, perfStat := range stats.PerfStats {
@@ -339,11 +659,15 @@ func (s *CAdvisorClient) PerfStatsToPoints(
 	return points
 }
 
-func (s *CAdvisorClient) ResctrlStatsToPoints(
+func (s *PointConverter) ResctrlStatsToPoints(
 	cInfo *info.ContainerInfo,
 	stats *info.ContainerStats,
 ) (points []*write.Point) {
 
+	if !s.Includes(cadvisormetrics.ResctrlMetrics) {
+		return nil
+	}
+
 	defaultTags := s.DefaultTags(cInfo, stats)
 
 	// Memory bandwidth
@@ -387,18 +711,23 @@ func (s *CAdvisorClient) AddStats(cInfo *info.ContainerInfo, stats *info.Contain
 	if stats == nil {
 		return nil
 	}
+
+	return s.AddPoints(s.StatsToPoints(cInfo, stats)...)
+}
+
+// AddPoints buffers already converted points and flushes them to the database
+// once the buffer duration has elapsed.
+func (s *CAdvisorClient) AddPoints(points ...*write.Point) error {
+	if len(points) == 0 {
+		return nil
+	}
 	var pointsToFlush []*write.Point
 	func() {
-		// AddStats will be invoked simultaneously from multiple threads and only one of them will perform a write.
+		// AddPoints will be invoked simultaneously from multiple threads and only one of them will perform a write.
 		s.lock.Lock()
 		defer s.lock.Unlock()
 
-		s.points = append(s.points, s.ContainerStatsToPoints(cInfo, stats)...)
-		s.points = append(s.points, s.MemoryStatsToPoints(cInfo, stats)...)
-		s.points = append(s.points, s.HugetlbStatsToPoints(cInfo, stats)...)
-		s.points = append(s.points, s.PerfStatsToPoints(cInfo, stats)...)
-		s.points = append(s.points, s.ResctrlStatsToPoints(cInfo, stats)...)
-		s.points = append(s.points, s.ContainerFilesystemStatsToPoints(cInfo, stats)...)
+		s.points = append(s.points, points...)
 		if s.readyToFlush() {
 			pointsToFlush = s.points
 			s.points = make([]*write.Point, 0)
@@ -407,7 +736,7 @@ func (s *CAdvisorClient) AddStats(cInfo *info.ContainerInfo, stats *info.Contain
 	}()
 	if len(pointsToFlush) > 0 {
 		writeAPI := s.client.WriteAPIBlocking(s.org, s.bucket)
-		writeAPI.WritePoint(context.TODO(), pointsToFlush...)
+		return writeAPI.WritePoint(context.TODO(), pointsToFlush...)
 	}
 
 	return nil
@@ -437,6 +766,112 @@ func (s *CAdvisorClient) GetStats() ([]map[string]interface{}, error) {
 	return returnList, nil
 }
 
+// GetStatsInPeriod returns the records stored between start and stop that
+// match the filter, oldest first.
+func (s *CAdvisorClient) GetStatsInPeriod(ctx context.Context, start, stop time.Time, filter StatsFilter) ([]map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, eventsQueryTimeout)
+	defer cancel()
+
+	result, err := s.client.QueryAPI(s.org).Query(ctx, s.statsQuery(start, stop, filter))
+	if err != nil {
+		return nil, err
+	}
+
+	records := []map[string]interface{}{}
+	for result.Next() {
+		records = append(records, result.Record().Values())
+	}
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return records, nil
+}
+
+// Record is a stored sample.
+type Record struct {
+	Time        time.Time
+	Measurement string
+	Field       string
+	// Value is a float64, int64, uint64, bool or string.
+	Value interface{}
+	Tags  map[string]string
+}
+
+// StreamStatsInPeriod calls fn with every record stored between start and
+// stop that matches the filter as they are read, series after series, oldest
+// first within a series. It stops at the first error returned by fn.
+func (s *CAdvisorClient) StreamStatsInPeriod(ctx context.Context, start, stop time.Time, filter StatsFilter, fn func(Record) error) error {
+	result, err := s.client.QueryAPI(s.org).Query(ctx, s.statsQuery(start, stop, filter))
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	for result.Next() {
+		fluxRecord := result.Record()
+		record := Record{
+			Time:        fluxRecord.Time(),
+			Measurement: fluxRecord.Measurement(),
+			Field:       fluxRecord.Field(),
+			Value:       fluxRecord.Value(),
+			Tags:        map[string]string{},
+		}
+		for k, v := range fluxRecord.Values() {
+			tag, ok := v.(string)
+			if ok && !strings.HasPrefix(k, "_") && k != columnFluxResult && k != columnFluxTable {
+				record.Tags[k] = tag
+			}
+		}
+
+		err := fn(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	return result.Err()
+}
+
+// StatsFilter selects stored records by measurement, container and pod.
+// Empty lists match every value.
+type StatsFilter struct {
+	Measurements []string
+	Containers   []string
+	Namespaces   []string
+	Pods         []string
+	QOSClasses   []string
+}
+
+func (s *CAdvisorClient) statsQuery(start, stop time.Time, filter StatsFilter) string {
+	query := fmt.Sprintf(`from(bucket:%q)
+  |> range(start: %s, stop: %s)`,
+		s.bucket, start.UTC().Format(time.RFC3339Nano), stop.UTC().Format(time.RFC3339Nano))
+	query += fluxFilter("_measurement", filter.Measurements)
+	query += fluxFilter(tagContainerName, filter.Containers)
+	query += fluxFilter(TagNamespace, filter.Namespaces)
+	query += fluxFilter(TagPod, filter.Pods)
+	query += fluxFilter(TagQOSClass, filter.QOSClasses)
+	query += `
+  |> sort(columns: ["_time"])`
+
+	return query
+}
+
+// fluxFilter keeps the records whose column has one of values, it keeps
+// every record when values is empty.
+func fluxFilter(column string, values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	conditions := []string{}
+	for _, value := range values {
+		conditions = append(conditions, fmt.Sprintf("r[%q] == %q", column, value))
+	}
+	return fmt.Sprintf("\n  |> filter(fn: (r) => %s)", strings.Join(conditions, " or "))
+}
+
 func (s *CAdvisorClient) Close() error {
 	s.client = nil
 	return nil
@@ -449,18 +884,18 @@ func newStorage(params CAdvisorClientParams) (*CAdvisorClient, error) {
 
 	client := influxdb_client_go.NewClient(params.Uri, params.Token) // "http://localhost:8086", "token")
 
-	hostname, err := os.Hostname()
+	converter, err := NewPointConverter()
 	if err != nil {
 		return nil, err
 	}
 
 	ret := &CAdvisorClient{
-		client:      client,
-		org:         DefaultOrgName,
-		bucket:      DefaultBucketName,
-		lastWrite:   time.Now(),
-		points:      make([]*write.Point, 0),
-		machineName: hostname,
+		PointConverter: converter,
+		client:         client,
+		org:            DefaultOrgName,
+		bucket:         DefaultBucketName,
+		lastWrite:      time.Now(),
+		points:         make([]*write.Point, 0),
 	}
 	ret.readyToFlush = ret.defaultReadyToFlush
 	return ret, nil
@@ -499,3 +934,21 @@ func toSignedIfUnsigned(value interface{}) interface{} {
 	}
 	return value
 }
+
+// ToFloat converts a numeric point field value into a float64.
+func ToFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,134 @@
+package influx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	cadvisorapi "github.com/google/cadvisor/info/v1"
+	info "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// Container lifecycle and OOM events
+const serEvents string = "events"
+
+// Event field and tag names
+const (
+	tagEventType       string = "event_type"
+	fieldPid           string = "pid"
+	fieldProcessName   string = "process_name"
+	columnTime         string = "_time"
+	columnFluxResult   string = "result"
+	columnFluxTable    string = "table"
+	eventsQueryTimeout        = 30 * time.Second
+)
+
+// EventToPoint converts a cadvisor event into a point of the events
+// measurement. cInfo may be nil when the container is already gone, in which
+// case the point is tagged with the raw container name only.
+func (s *PointConverter) EventToPoint(event *cadvisorapi.Event, cInfo *info.ContainerInfo) *write.Point {
+	fields := map[string]interface{}{
+		fieldValue: int64(1),
+	}
+	if event.EventData.OomKill != nil {
+		fields[fieldPid] = int64(event.EventData.OomKill.Pid)
+		fields[fieldProcessName] = event.EventData.OomKill.ProcessName
+	}
+
+	tags := map[string]string{
+		tagMachineName: s.machineName,
+		tagEventType:   string(event.EventType),
+	}
+	point := write.NewPoint(serEvents, tags, fields, event.Timestamp)
+	if cInfo != nil {
+		s.TagPoints(cInfo, nil, []*write.Point{point})
+	}
+	if !hasTag(point, tagContainerName) {
+		point.AddTag(tagContainerName, event.ContainerName)
+	}
+
+	return point
+}
+
+// Event is a stored container event.
+type Event struct {
+	Timestamp     time.Time
+	Type          string
+	ContainerName string
+	Pid           int64
+	ProcessName   string
+	Tags          map[string]string
+}
+
+// GetEvents returns the events stored between start and stop, oldest first.
+// An empty eventTypes matches every type.
+func (s *CAdvisorClient) GetEvents(ctx context.Context, start, stop time.Time, eventTypes []string) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, eventsQueryTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`from(bucket:%q)
+  |> range(start: %s, stop: %s)
+  |> filter(fn: (r) => r._measurement == %q)`,
+		s.bucket, start.UTC().Format(time.RFC3339Nano), stop.UTC().Format(time.RFC3339Nano), serEvents)
+	if len(eventTypes) > 0 {
+		conditions := []string{}
+		for _, eventType := range eventTypes {
+			conditions = append(conditions, fmt.Sprintf("r.%s == %q", tagEventType, eventType))
+		}
+		query += fmt.Sprintf("\n  |> filter(fn: (r) => %s)", strings.Join(conditions, " or "))
+	}
+	query += `
+  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+  |> group()
+  |> sort(columns: ["_time"])`
+
+	result, err := s.client.QueryAPI(s.org).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []Event{}
+	for result.Next() {
+		values := result.Record().Values()
+		event := Event{
+			Tags: map[string]string{},
+		}
+		for k, v := range values {
+			switch k {
+			case columnTime:
+				event.Timestamp, _ = v.(time.Time)
+			case tagEventType:
+				event.Type, _ = v.(string)
+			case tagContainerName:
+				event.ContainerName, _ = v.(string)
+			case fieldPid:
+				event.Pid, _ = v.(int64)
+			case fieldProcessName:
+				event.ProcessName, _ = v.(string)
+			case fieldValue, columnFluxResult, columnFluxTable:
+			default:
+				tag, ok := v.(string)
+				if ok && !strings.HasPrefix(k, "_") {
+					event.Tags[k] = tag
+				}
+			}
+		}
+		events = append(events, event)
+	}
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return events, nil
+}
+
+func hasTag(point *write.Point, key string) bool {
+	for _, tag := range point.TagList() {
+		if tag.Key == key && tag.Value != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package influx
+
+import (
+	"time"
+
+	info "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// Image filesystem series
+const (
+	// Capacity of the filesystem holding container images
+	serImageFsCapacity string = "image_fs_capacity"
+	// Bytes used on the image filesystem
+	serImageFsUsage string = "image_fs_usage"
+	// Bytes available to non-root users on the image filesystem
+	serImageFsAvailable string = "image_fs_available"
+	// Total inodes of the image filesystem
+	serImageFsInodes string = "image_fs_inodes"
+	// Free inodes of the image filesystem
+	serImageFsInodesFree string = "image_fs_inodes_free"
+)
+
+const tagMountpoint string = "mountpoint"
+
+// ImageFsInfoToPoints converts the usage of an image filesystem into points
+// tagged with its device and mountpoint.
+func (s *PointConverter) ImageFsInfoToPoints(fsInfo info.FsInfo, ts time.Time) (points []*write.Point) {
+	tags := map[string]string{
+		tagMachineName: s.machineName,
+		fieldDevice:    fsInfo.Device,
+		tagMountpoint:  fsInfo.Mountpoint,
+	}
+
+	points = append(points, makePoint(serImageFsCapacity, tags, fsInfo.Capacity, ts))
+	points = append(points, makePoint(serImageFsUsage, tags, fsInfo.Usage, ts))
+	points = append(points, makePoint(serImageFsAvailable, tags, fsInfo.Available, ts))
+	if fsInfo.Inodes != nil {
+		points = append(points, makePoint(serImageFsInodes, tags, *fsInfo.Inodes, ts))
+	}
+	if fsInfo.InodesFree != nil {
+		points = append(points, makePoint(serImageFsInodesFree, tags, *fsInfo.InodesFree, ts))
+	}
+
+	return points
+}
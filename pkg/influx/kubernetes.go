@@ -0,0 +1,157 @@
+package influx
+
+import (
+	"path"
+	"strings"
+
+	info "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// Labels the kubelet and the CRI runtimes set on the containers of pods.
+const (
+	labelPodName       = "io.kubernetes.pod.name"
+	labelPodNamespace  = "io.kubernetes.pod.namespace"
+	labelPodUID        = "io.kubernetes.pod.uid"
+	labelContainerName = "io.kubernetes.container.name"
+)
+
+// Tags of the points of pod containers.
+const (
+	TagNamespace = "namespace"
+	TagPod       = "pod"
+	TagContainer = "container"
+	TagQOSClass  = "qos_class"
+)
+
+// QoS classes of pods.
+const (
+	QOSGuaranteed = "Guaranteed"
+	QOSBurstable  = "Burstable"
+	QOSBestEffort = "BestEffort"
+)
+
+// kubepodsCgroup is the cgroup the kubelet nests pods under, kubepods.slice
+// with the systemd cgroup driver.
+const kubepodsCgroup = "kubepods"
+
+// PodMetadata describes the pod a cgroup belongs to.
+type PodMetadata struct {
+	Namespace string
+	Pod       string
+	// Container is empty for the cgroup of the pod itself.
+	Container string
+	UID       string
+	QOSClass  string
+}
+
+// ParsePodMetadata reads the pod of a container from its kubelet labels and
+// the QoS class and pod UID from its cgroup path, of either the cgroupfs or
+// the systemd driver:
+//
+//	/kubepods/burstable/pod<uid>/<container>
+//	/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid>.slice/<container>.scope
+//
+// Guaranteed pods are nested directly under kubepods. It reports false for
+// cgroups of neither a pod nor a pod container.
+func ParsePodMetadata(name string, labels map[string]string) (PodMetadata, bool) {
+	meta := PodMetadata{
+		Namespace: labels[labelPodNamespace],
+		Pod:       labels[labelPodName],
+		Container: labels[labelContainerName],
+		UID:       labels[labelPodUID],
+	}
+
+	segments := strings.Split(strings.Trim(path.Clean(name), "/"), "/")
+	for i, segment := range segments {
+		if segment != kubepodsCgroup && segment != kubepodsCgroup+".slice" {
+			continue
+		}
+
+		// The QoS cgroups of burstable and best effort pods, guaranteed pods
+		// have none.
+		meta.QOSClass = QOSGuaranteed
+		rest := segments[i+1:]
+		if len(rest) > 0 {
+			switch strings.TrimSuffix(strings.TrimPrefix(rest[0], kubepodsCgroup+"-"), ".slice") {
+			case "burstable":
+				meta.QOSClass = QOSBurstable
+				rest = rest[1:]
+			case "besteffort":
+				meta.QOSClass = QOSBestEffort
+				rest = rest[1:]
+			}
+		}
+
+		// kubepods and the QoS cgroups hold many pods.
+		if len(rest) == 0 {
+			return PodMetadata{}, false
+		}
+		uid, ok := podUID(rest[0])
+		if !ok {
+			return PodMetadata{}, false
+		}
+		if len(rest) == 1 {
+			return PodMetadata{UID: uid, QOSClass: meta.QOSClass}, true
+		}
+		if meta.UID == "" {
+			meta.UID = uid
+		}
+		return meta, true
+	}
+
+	if meta.Pod == "" {
+		return PodMetadata{}, false
+	}
+	return meta, true
+}
+
+// podUID returns the uid of a pod cgroup, pod<uid> or, with the systemd
+// driver, kubepods[-<qos>]-pod<uid>.slice where the dashes of the uid are
+// underscores.
+func podUID(segment string) (string, bool) {
+	if strings.HasSuffix(segment, ".slice") {
+		segment = strings.TrimSuffix(segment, ".slice")
+		i := strings.LastIndex(segment, "-pod")
+		if i < 0 {
+			return "", false
+		}
+		return strings.ReplaceAll(segment[i+len("-pod"):], "_", "-"), true
+	}
+	if !strings.HasPrefix(segment, "pod") {
+		return "", false
+	}
+	return strings.TrimPrefix(segment, "pod"), true
+}
+
+// Tags returns the tags of the points of the cgroup. Pod cgroups are tagged
+// with their QoS class only, so summing the containers of a pod does not
+// count the pod twice.
+func (m PodMetadata) Tags() map[string]string {
+	tags := map[string]string{}
+	for tag, value := range map[string]string{
+		TagNamespace: m.Namespace,
+		TagPod:       m.Pod,
+		TagContainer: m.Container,
+		TagQOSClass:  m.QOSClass,
+	} {
+		if value != "" {
+			tags[tag] = value
+		}
+	}
+	return tags
+}
+
+// TagPodPoints tags the points of a cgroup with its pod, if it belongs to
+// one. The pod tags shadow container labels of the same name.
+func TagPodPoints(name string, cInfo *info.ContainerInfo, points []*write.Point) {
+	meta, ok := ParsePodMetadata(name, cInfo.Spec.Labels)
+	if !ok {
+		return
+	}
+
+	tags := meta.Tags()
+	for _, point := range points {
+		addTagsToPoint(point, tags)
+	}
+}
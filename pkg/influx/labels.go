@@ -0,0 +1,165 @@
+package influx
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LabelPolicy decides which container labels become tags. Allow and Deny hold
+// label name globs in path.Match syntax; an empty Allow list allows every
+// label and Deny always wins. Rename maps a label name to its tag name and MaxValueLength truncates
+// longer values, 0 leaves them untouched.
+type LabelPolicy struct {
+	Allow          []string          `yaml:"allow"`
+	Deny           []string          `yaml:"deny"`
+	Rename         map[string]string `yaml:"rename"`
+	MaxValueLength int               `yaml:"maxValueLength"`
+}
+
+// LoadLabelPolicy reads a YAML label policy.
+func LoadLabelPolicy(filename string) (*LabelPolicy, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &LabelPolicy{}
+	err = yaml.UnmarshalStrict(data, policy)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return policy, nil
+}
+
+// Validate checks the globs and limits of the policy.
+func (p *LabelPolicy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Allow...), p.Deny...) {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+		}
+	}
+	if p.MaxValueLength < 0 {
+		return fmt.Errorf("maxValueLength must not be negative")
+	}
+	for from, to := range p.Rename {
+		if to == "" {
+			return fmt.Errorf("label %q is renamed to an empty name", from)
+		}
+	}
+	return nil
+}
+
+// Apply returns the tags for a set of container labels. A nil policy keeps
+// every label as is.
+func (p *LabelPolicy) Apply(labels map[string]string) map[string]string {
+	if p == nil {
+		return labels
+	}
+
+	tags := make(map[string]string, len(labels))
+	for name, value := range labels {
+		if !p.allowed(name) {
+			continue
+		}
+		if renamed, ok := p.Rename[name]; ok {
+			name = renamed
+		}
+		if p.MaxValueLength > 0 && len(value) > p.MaxValueLength {
+			value = truncate(value, p.MaxValueLength)
+		}
+		tags[name] = value
+	}
+	return tags
+}
+
+func (p *LabelPolicy) allowed(name string) bool {
+	if matchesAny(p.Deny, name) {
+		return false
+	}
+	return len(p.Allow) == 0 || matchesAny(p.Allow, name)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate shortens s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// LabelPolicyFile is a label policy loaded from a file that can be reloaded
+// while collection is running.
+type LabelPolicyFile struct {
+	filename string
+
+	mu     sync.RWMutex
+	policy *LabelPolicy
+}
+
+// NewLabelPolicyFile loads the label policy at filename. An empty filename
+// yields a file that keeps every label.
+func NewLabelPolicyFile(filename string) (*LabelPolicyFile, error) {
+	file := &LabelPolicyFile{filename: filename}
+	if filename == "" {
+		return file, nil
+	}
+
+	err := file.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Reload re-reads the policy file. The previous policy stays in effect when
+// the new one is invalid.
+func (f *LabelPolicyFile) Reload() error {
+	if f.filename == "" {
+		return nil
+	}
+
+	policy, err := LoadLabelPolicy(f.filename)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.policy = policy
+	f.mu.Unlock()
+
+	return nil
+}
+
+// Policy returns the policy currently in effect.
+func (f *LabelPolicyFile) Policy() *LabelPolicy {
+	if f == nil {
+		return nil
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.policy
+}
@@ -0,0 +1,120 @@
+package influx
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	cadvisormetrics "github.com/google/cadvisor/container"
+	cadvisorapi "github.com/google/cadvisor/info/v1"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// Snapshot of the machine hardware description
+const serMachineInfo string = "machine_info"
+
+// CPU topology series
+const (
+	// Number of hardware threads of a CPU core
+	serCPUThreadSiblings string = "cpu_thread_siblings"
+	// Cache size in bytes assigned to a NUMA node or CPU core
+	serCPUCacheCapacity string = "cpu_cache_capacity"
+)
+
+// Machine info field and tag names
+const (
+	tagBootID              string = "boot_id"
+	tagMachineID           string = "machine_id"
+	tagSystemUUID          string = "system_uuid"
+	tagNodeID              string = "node_id"
+	tagCoreID              string = "core_id"
+	tagSocketID            string = "socket_id"
+	tagCacheLevel          string = "level"
+	fieldNumCores          string = "num_cores"
+	fieldNumPhysicalCores  string = "num_physical_cores"
+	fieldNumSockets        string = "num_sockets"
+	fieldCpuFrequency      string = "cpu_frequency_khz"
+	fieldMemoryCapacity    string = "memory_capacity"
+	fieldNumaNodes         string = "numa_nodes"
+	fieldNumFilesystems    string = "filesystems"
+	fieldNumNetworkDevices string = "network_devices"
+	fieldMachineInfo       string = "info"
+)
+
+// MachineInfoToPoint converts a machine description into a single snapshot
+// point. The full description is kept as JSON in the info field.
+func (s *PointConverter) MachineInfoToPoint(machineInfo *cadvisorapi.MachineInfo, ts time.Time) (*write.Point, error) {
+	infoJson, err := json.Marshal(machineInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{
+		tagMachineName: s.machineName,
+		tagBootID:      machineInfo.BootID,
+		tagMachineID:   machineInfo.MachineID,
+		tagSystemUUID:  machineInfo.SystemUUID,
+	}
+	fields := map[string]interface{}{
+		fieldNumCores:          machineInfo.NumCores,
+		fieldNumPhysicalCores:  machineInfo.NumPhysicalCores,
+		fieldNumSockets:        machineInfo.NumSockets,
+		fieldCpuFrequency:      toSignedIfUnsigned(machineInfo.CpuFrequency),
+		fieldMemoryCapacity:    toSignedIfUnsigned(machineInfo.MemoryCapacity),
+		fieldNumaNodes:         len(machineInfo.Topology),
+		fieldNumFilesystems:    len(machineInfo.Filesystems),
+		fieldNumNetworkDevices: len(machineInfo.NetworkDevices),
+		fieldMachineInfo:       string(infoJson),
+	}
+
+	return write.NewPoint(serMachineInfo, tags, fields, ts), nil
+}
+
+// MachineTopologyToPoints converts the CPU topology of the machine into thread
+// sibling and cache capacity points. It returns nothing unless the
+// cpu_topology metric set is collected.
+func (s *PointConverter) MachineTopologyToPoints(machineInfo *cadvisorapi.MachineInfo, ts time.Time) (points []*write.Point) {
+	if !s.Includes(cadvisormetrics.CPUTopologyMetrics) {
+		return nil
+	}
+
+	defaultTags := map[string]string{
+		tagMachineName: s.machineName,
+	}
+	for _, node := range machineInfo.Topology {
+		nodeID := strconv.Itoa(node.Id)
+		for _, cache := range node.Caches {
+			point := makePoint(serCPUCacheCapacity, defaultTags, cache.Size, ts)
+			addTagsToPoint(point, map[string]string{
+				tagNodeID:     nodeID,
+				fieldType:     cache.Type,
+				tagCacheLevel: strconv.Itoa(cache.Level),
+			})
+			points = append(points, point)
+		}
+
+		for _, core := range node.Cores {
+			coreTags := map[string]string{
+				tagNodeID:   nodeID,
+				tagCoreID:   strconv.Itoa(core.Id),
+				tagSocketID: strconv.Itoa(core.SocketID),
+			}
+
+			point := makePoint(serCPUThreadSiblings, defaultTags, len(core.Threads), ts)
+			addTagsToPoint(point, coreTags)
+			points = append(points, point)
+
+			for _, cache := range core.Caches {
+				point := makePoint(serCPUCacheCapacity, defaultTags, cache.Size, ts)
+				addTagsToPoint(point, coreTags)
+				addTagsToPoint(point, map[string]string{
+					fieldType:     cache.Type,
+					tagCacheLevel: strconv.Itoa(cache.Level),
+				})
+				points = append(points, point)
+			}
+		}
+	}
+
+	return points
+}
@@ -0,0 +1,125 @@
+package influx
+
+import (
+	"sort"
+
+	cadvisormetrics "github.com/google/cadvisor/container"
+	info "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/psi"
+)
+
+// CPU throttling and pressure series
+const (
+	// Cumulative number of elapsed CFS enforcement periods
+	serCPUCfsPeriods string = "cpu_cfs_periods"
+	// Cumulative number of CFS periods the container was throttled in
+	serCPUCfsThrottledPeriods string = "cpu_cfs_throttled_periods"
+	// Cumulative time the container was throttled, in nanoseconds
+	serCPUCfsThrottledTime string = "cpu_cfs_throttled_time"
+	// Share of CFS periods the container was throttled in between the two
+	// latest samples
+	serCPUCfsThrottledRatio string = "cpu_cfs_throttled_ratio"
+	// Cumulative time tasks stalled on a resource, in microseconds
+	serPressureTotal string = "pressure_total"
+	// Share of wall time tasks stalled on a resource, in percent
+	serPressureAvg string = "pressure_avg"
+)
+
+// Pressure tag names
+const (
+	tagResource string = "resource"
+	tagKind     string = "kind"
+	tagWindow   string = "window"
+)
+
+// CpuCfsStatsToPoints converts the CFS bandwidth statistics of containers
+// with a CPU quota. The throttled ratio is derived from the sample before
+// stats in cInfo, when there is one.
+func (s *PointConverter) CpuCfsStatsToPoints(
+	cInfo *info.ContainerInfo,
+	stats *info.ContainerStats,
+) (points []*write.Point) {
+
+	if stats.Cpu == nil || !s.Includes(cadvisormetrics.CpuUsageMetrics) {
+		return nil
+	}
+
+	// Containers without a CPU quota are never throttled and have no periods.
+	cfs := stats.Cpu.CFS
+	if cfs.Periods == 0 {
+		return nil
+	}
+
+	defaultTags := s.DefaultTags(cInfo, stats)
+	points = append(points, makePoint(serCPUCfsPeriods, defaultTags, cfs.Periods, stats.Timestamp))
+	points = append(points, makePoint(serCPUCfsThrottledPeriods, defaultTags, cfs.ThrottledPeriods, stats.Timestamp))
+	points = append(points, makePoint(serCPUCfsThrottledTime, defaultTags, cfs.ThrottledTime, stats.Timestamp))
+
+	previous := previousStats(cInfo, stats)
+	if previous != nil && previous.Cpu != nil {
+		prev := previous.Cpu.CFS
+		if cfs.Periods > prev.Periods && cfs.ThrottledPeriods >= prev.ThrottledPeriods {
+			ratio := float64(cfs.ThrottledPeriods-prev.ThrottledPeriods) / float64(cfs.Periods-prev.Periods)
+			points = append(points, makePoint(serCPUCfsThrottledRatio, defaultTags, ratio, stats.Timestamp))
+		}
+	}
+
+	s.TagPoints(cInfo, stats, points)
+
+	return points
+}
+
+// PressureToPoints converts the pressure stall information of a container.
+func (s *PointConverter) PressureToPoints(
+	cInfo *info.ContainerInfo,
+	stats *info.ContainerStats,
+	pressure map[string]psi.Stats,
+) (points []*write.Point) {
+
+	defaultTags := s.DefaultTags(cInfo, stats)
+
+	resources := []string{}
+	for resource := range pressure {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		for kind, p := range pressure[resource] {
+			tags := map[string]string{
+				tagResource: resource,
+				tagKind:     kind,
+			}
+
+			point := makePoint(serPressureTotal, defaultTags, p.Total, stats.Timestamp)
+			addTagsToPoint(point, tags)
+			points = append(points, point)
+
+			for window, avg := range map[string]float64{"10s": p.Avg10, "60s": p.Avg60, "300s": p.Avg300} {
+				point := makePoint(serPressureAvg, defaultTags, avg, stats.Timestamp)
+				addTagsToPoint(point, tags)
+				addTagsToPoint(point, map[string]string{tagWindow: window})
+				points = append(points, point)
+			}
+		}
+	}
+
+	s.TagPoints(cInfo, stats, points)
+
+	return points
+}
+
+// previousStats returns the sample taken right before stats, if any.
+func previousStats(cInfo *info.ContainerInfo, stats *info.ContainerStats) *info.ContainerStats {
+	var previous *info.ContainerStats
+	for _, sample := range cInfo.Stats {
+		if sample == nil || !sample.Timestamp.Before(stats.Timestamp) {
+			continue
+		}
+		if previous == nil || sample.Timestamp.After(previous.Timestamp) {
+			previous = sample
+		}
+	}
+	return previous
+}
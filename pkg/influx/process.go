@@ -0,0 +1,111 @@
+package influx
+
+import (
+	"strconv"
+
+	cadvisormetrics "github.com/google/cadvisor/container"
+	info "github.com/google/cadvisor/info/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/procs"
+)
+
+// Process series
+const (
+	// Number of processes in the container
+	serProcessCount string = "process_count"
+	// Number of threads in the container
+	serProcessThreads string = "process_threads"
+	// Maximum number of threads allowed in the container
+	serProcessThreadsMax string = "process_threads_max"
+	// Number of open file descriptors
+	serProcessFdCount string = "process_fd_count"
+	// Number of open sockets
+	serProcessSocketCount string = "process_socket_count"
+	// Soft limit of a resource of the top-level container process
+	serProcessUlimit string = "process_ulimit"
+	// Processes using the most CPU or memory
+	serTopProcesses string = "top_processes"
+)
+
+// Process field and tag names
+const (
+	tagUlimit       string = "ulimit"
+	tagRank         string = "rank"
+	tagSortBy       string = "sort_by"
+	fieldCommand    string = "command"
+	fieldCpuPercent string = "cpu_percent"
+	fieldRssBytes   string = "rss_bytes"
+	fieldHardLimit  string = "hard_limit"
+)
+
+// ThreadsMax of containers without a pids limit
+const unlimitedThreads uint64 = 0
+
+func (s *PointConverter) ProcessStatsToPoints(
+	cInfo *info.ContainerInfo,
+	stats *info.ContainerStats,
+) (points []*write.Point) {
+
+	if stats.Processes == nil || !s.Includes(cadvisormetrics.ProcessMetrics) {
+		return nil
+	}
+
+	processes := stats.Processes
+	defaultTags := s.DefaultTags(cInfo, stats)
+
+	points = append(points, makePoint(serProcessCount, defaultTags, processes.ProcessCount, stats.Timestamp))
+	points = append(points, makePoint(serProcessThreads, defaultTags, processes.ThreadsCurrent, stats.Timestamp))
+	// cadvisor reports 0 when the pids controller sets no limit
+	if processes.ThreadsMax != unlimitedThreads {
+		points = append(points, makePoint(serProcessThreadsMax, defaultTags, processes.ThreadsMax, stats.Timestamp))
+	}
+	points = append(points, makePoint(serProcessFdCount, defaultTags, processes.FdCount, stats.Timestamp))
+	points = append(points, makePoint(serProcessSocketCount, defaultTags, processes.SocketCount, stats.Timestamp))
+
+	for _, ulimit := range processes.Ulimits {
+		point := makePoint(serProcessUlimit, defaultTags, ulimit.SoftLimit, stats.Timestamp)
+		point.AddField(fieldHardLimit, ulimit.HardLimit)
+		addTagsToPoint(point, map[string]string{tagUlimit: ulimit.Name})
+		points = append(points, point)
+	}
+
+	s.TagPoints(cInfo, stats, points)
+
+	return points
+}
+
+// TopProcessesToPoints converts the processes of a container ranked by cpu
+// or rss into points tagged with their rank. The value field holds the
+// ranking metric, always as a float so both rankings share its field type.
+// The pid and command are fields to bound the series count.
+func (s *PointConverter) TopProcessesToPoints(
+	cInfo *info.ContainerInfo,
+	stats *info.ContainerStats,
+	by string,
+	processes []procs.Process,
+) (points []*write.Point) {
+
+	defaultTags := s.DefaultTags(cInfo, stats)
+
+	for i, process := range processes {
+		value := process.CpuPercent
+		if by == procs.ByRSS {
+			value = float64(process.RssBytes)
+		}
+
+		point := makePoint(serTopProcesses, defaultTags, value, stats.Timestamp)
+		point.AddField(fieldPid, int64(process.Pid))
+		point.AddField(fieldCommand, process.Command)
+		point.AddField(fieldCpuPercent, process.CpuPercent)
+		point.AddField(fieldRssBytes, toSignedIfUnsigned(process.RssBytes))
+		addTagsToPoint(point, map[string]string{
+			tagRank:   strconv.Itoa(i + 1),
+			tagSortBy: by,
+		})
+		points = append(points, point)
+	}
+
+	s.TagPoints(cInfo, stats, points)
+
+	return points
+}
@@ -0,0 +1,49 @@
+package influx
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	info "github.com/google/cadvisor/info/v2"
+	"github.com/zawachte/stalker/pkg/procs"
+)
+
+func TestTopProcessesFieldTypes(t *testing.T) {
+	s := &PointConverter{machineName: "node-1"}
+	cInfo := &info.ContainerInfo{Spec: info.ContainerSpec{Image: "nginx"}}
+	stats := &info.ContainerStats{Timestamp: time.Unix(1600000000, 0)}
+	processes := []procs.Process{
+		{Pid: 1, Command: "nginx", CpuPercent: 12.5, RssBytes: 4096},
+		{Pid: 7, Command: "worker", CpuPercent: 0, RssBytes: 1 << 40},
+	}
+
+	// The points of both rankings land in one measurement, so every field
+	// must keep a single type across them.
+	types := map[string]string{}
+	for _, by := range []string{procs.ByCPU, procs.ByRSS} {
+		points := s.TopProcessesToPoints(cInfo, stats, by, processes)
+		if len(points) != len(processes) {
+			t.Fatalf("%s: got %d points, want %d", by, len(points), len(processes))
+		}
+		for i, point := range points {
+			if point.Name() != serTopProcesses {
+				t.Errorf("%s: got measurement %s", by, point.Name())
+			}
+			for _, field := range point.FieldList() {
+				typ := fmt.Sprintf("%T", field.Value)
+				if want, ok := types[field.Key]; ok && want != typ {
+					t.Errorf("%s: field %s of point %d is a %s, other points have a %s", by, field.Key, i, typ, want)
+				}
+				types[field.Key] = typ
+			}
+		}
+
+		want := map[string]float64{procs.ByCPU: 0, procs.ByRSS: 1 << 40}[by]
+		for _, field := range points[1].FieldList() {
+			if field.Key == fieldValue && field.Value != want {
+				t.Errorf("%s: got value %v, want %v", by, field.Value, want)
+			}
+		}
+	}
+}
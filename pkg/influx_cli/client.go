@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"net/url"
 	"runtime"
+	"time"
 
 	influxapi "github.com/influxdata/influx-cli/v2/api"
 	"github.com/influxdata/influx-cli/v2/clients"
 	"github.com/influxdata/influx-cli/v2/clients/backup"
+	"github.com/influxdata/influx-cli/v2/clients/restore"
 	"github.com/influxdata/influx-cli/v2/clients/setup"
 	"github.com/influxdata/influx-cli/v2/config"
 
@@ -71,6 +73,7 @@ func newApiClient(configSvc config.Service, injectToken bool) (*influxapi.APICli
 type Client interface {
 	SetupInflux(SetupInfluxParams) error
 	BackupInflux(BackupInfluxParams) error
+	RestoreInflux(RestoreInfluxParams) error
 }
 
 type client struct {
@@ -158,3 +161,93 @@ func (c *client) BackupInflux(inputParams BackupInfluxParams) error {
 
 	return nil
 }
+
+type RestoreInfluxParams struct {
+	Org    string
+	Bucket string
+	Path   string
+}
+
+// RestoreInflux replaces the bucket with the latest backup of it found in
+// the backup directory. The backup is restored into a temporary bucket, the
+// bucket is only replaced once that succeeded, so a failed restore leaves the
+// stored metrics untouched.
+func (c *client) RestoreInflux(inputParams RestoreInfluxParams) error {
+	ctx := context.Background()
+
+	err := validateBackup(inputParams.Path, inputParams.Org, inputParams.Bucket)
+	if err != nil {
+		return err
+	}
+
+	client := restore.Client{
+		CLI:              c.cli,
+		HealthApi:        c.apiClient.HealthApi,
+		RestoreApi:       c.apiClient.RestoreApi,
+		BucketsApi:       c.apiClient.BucketsApi,
+		OrganizationsApi: c.apiClient.OrganizationsApi,
+		ApiConfig:        c.apiClient,
+	}
+
+	restored := fmt.Sprintf("%s_restore_%d", inputParams.Bucket, time.Now().Unix())
+	params := restore.Params{
+		Path:          inputParams.Path,
+		NewBucketName: restored,
+	}
+	params.OrgName = inputParams.Org
+	params.BucketName = inputParams.Bucket
+
+	err = client.Restore(ctx, &params)
+	if err != nil {
+		c.deleteBuckets(ctx, inputParams.Org, restored)
+		return fmt.Errorf("failed to restore bucket %s: %w", inputParams.Bucket, err)
+	}
+
+	restoredBuckets, err := c.findBuckets(ctx, inputParams.Org, restored)
+	if err == nil && len(restoredBuckets) != 1 {
+		err = fmt.Errorf("found %d buckets", len(restoredBuckets))
+	}
+	if err != nil {
+		c.deleteBuckets(ctx, inputParams.Org, restored)
+		return fmt.Errorf("failed to find the restored bucket %s: %w", restored, err)
+	}
+
+	// Writes to the bucket fail between the deletion and the rename.
+	err = c.deleteBuckets(ctx, inputParams.Org, inputParams.Bucket)
+	if err != nil {
+		c.deleteBuckets(ctx, inputParams.Org, restored)
+		return fmt.Errorf("failed to delete bucket %s before replacing it: %w", inputParams.Bucket, err)
+	}
+
+	name := inputParams.Bucket
+	_, err = c.apiClient.BucketsApi.PatchBucketsID(ctx, restoredBuckets[0].GetId()).
+		PatchBucketRequest(influxapi.PatchBucketRequest{Name: &name}).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to rename the restored bucket %s to %s: %w", restored, name, err)
+	}
+
+	return nil
+}
+
+func (c *client) findBuckets(ctx context.Context, org, name string) ([]influxapi.Bucket, error) {
+	buckets, err := c.apiClient.BucketsApi.GetBuckets(ctx).Org(org).Name(name).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return buckets.GetBuckets(), nil
+}
+
+func (c *client) deleteBuckets(ctx context.Context, org, name string) error {
+	buckets, err := c.findBuckets(ctx, org, name)
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		err := c.apiClient.BucketsApi.DeleteBucketsID(ctx, bucket.GetId()).Execute()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
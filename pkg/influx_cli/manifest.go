@@ -0,0 +1,82 @@
+package influx_cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// manifestVersion is the version of the manifests written by influx backup.
+const manifestVersion = 2
+
+// backupManifest is the part of a backup manifest checked before a restore.
+type backupManifest struct {
+	Version int `json:"manifestVersion"`
+	Buckets []struct {
+		OrganizationName  string `json:"organizationName"`
+		BucketName        string `json:"bucketName"`
+		RetentionPolicies []struct {
+			ShardGroups []struct {
+				Shards []struct {
+					FileName string `json:"fileName"`
+				} `json:"shards"`
+			} `json:"shardGroups"`
+		} `json:"retentionPolicies"`
+	} `json:"buckets"`
+}
+
+// validateBackup checks that the latest manifest in path holding the bucket
+// of org is readable and that every shard file it lists is present.
+func validateBackup(path, org, bucket string) error {
+	manifests, err := filepath.Glob(filepath.Join(path, "*.manifest"))
+	if err != nil {
+		return fmt.Errorf("failed to find backup manifests at %q: %w", path, err)
+	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("no backup manifests found at %q", path)
+	}
+	// Manifests are named after the time of their backup.
+	sort.Sort(sort.Reverse(sort.StringSlice(manifests)))
+
+	for _, file := range manifests {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read backup manifest %q: %w", file, err)
+		}
+
+		manifest := backupManifest{}
+		err = json.Unmarshal(data, &manifest)
+		if err != nil {
+			return fmt.Errorf("backup manifest %q is invalid: %w", file, err)
+		}
+		if manifest.Version != manifestVersion {
+			return fmt.Errorf("backup manifest %q has unsupported version %d", file, manifest.Version)
+		}
+
+		for _, b := range manifest.Buckets {
+			if b.OrganizationName != org || b.BucketName != bucket {
+				continue
+			}
+			for _, rp := range b.RetentionPolicies {
+				for _, sg := range rp.ShardGroups {
+					for _, shard := range sg.Shards {
+						// Shards are files of the backup directory.
+						if shard.FileName == "" || filepath.Base(shard.FileName) != shard.FileName {
+							return fmt.Errorf("backup manifest %q lists the invalid shard file %q", file, shard.FileName)
+						}
+						_, err := os.Stat(filepath.Join(path, shard.FileName))
+						if err != nil {
+							return fmt.Errorf("backup manifest %q lists a missing shard: %w", file, err)
+						}
+					}
+				}
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no backup of bucket %s of org %s found at %q", bucket, org, path)
+}
@@ -0,0 +1,74 @@
+package influx_cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testManifest = `{
+  "manifestVersion": 2,
+  "kv": {"fileName": "20220101T000000Z.bolt.gz"},
+  "buckets": [{
+    "organizationName": "stalker",
+    "bucketName": "stalker",
+    "retentionPolicies": [{"shardGroups": [{"shards": [{"id": 1, "fileName": "%s"}]}]}]
+  }]
+}`
+
+func writeBackup(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func manifestOf(shard string) string {
+	return strings.Replace(testManifest, "%s", shard, 1)
+}
+
+func TestValidateBackup(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		bucket string
+		err    string
+	}{
+		{
+			name:   "valid",
+			files:  map[string]string{"20220101T000000Z.manifest": manifestOf("1.tar.gz"), "1.tar.gz": "tsm"},
+			bucket: "stalker",
+		},
+		{
+			name: "latest manifest of the bucket",
+			files: map[string]string{
+				"20220101T000000Z.manifest": manifestOf("1.tar.gz"),
+				"20220102T000000Z.manifest": manifestOf("2.tar.gz"),
+				"2.tar.gz":                  "tsm",
+			},
+			bucket: "stalker",
+		},
+		{name: "no manifest", files: map[string]string{"1.tar.gz": "tsm"}, bucket: "stalker", err: "no backup manifests found"},
+		{name: "invalid manifest", files: map[string]string{"20220101T000000Z.manifest": "{"}, bucket: "stalker", err: "is invalid"},
+		{name: "legacy manifest", files: map[string]string{"20220101T000000Z.manifest": `{"buckets": []}`}, bucket: "stalker", err: "unsupported version 0"},
+		{name: "other bucket", files: map[string]string{"20220101T000000Z.manifest": manifestOf("1.tar.gz"), "1.tar.gz": "tsm"}, bucket: "other", err: "no backup of bucket other"},
+		{name: "missing shard", files: map[string]string{"20220101T000000Z.manifest": manifestOf("1.tar.gz")}, bucket: "stalker", err: "lists a missing shard"},
+		{name: "shard outside the directory", files: map[string]string{"20220101T000000Z.manifest": manifestOf("../1.tar.gz")}, bucket: "stalker", err: "invalid shard file"},
+	}
+
+	for _, tt := range tests {
+		err := validateBackup(writeBackup(t, tt.files), "stalker", tt.bucket)
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: got error %v, want %s", tt.name, err, tt.err)
+		}
+	}
+}
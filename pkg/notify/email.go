@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// EmailConfig sends notifications by mail through an SMTP server.
+type EmailConfig struct {
+	// Smarthost is the host:port of the SMTP server.
+	Smarthost string   `yaml:"smarthost"`
+	From      string   `yaml:"from"`
+	To        []string `yaml:"to"`
+	// Username and Password authenticate with PLAIN, which is only attempted
+	// over TLS or to localhost.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// RequireTLS fails deliveries to servers not offering STARTTLS.
+	RequireTLS bool `yaml:"requireTLS"`
+}
+
+type email struct {
+	config EmailConfig
+	host   string
+}
+
+func newEmail(config EmailConfig) (*email, error) {
+	host, _, err := net.SplitHostPort(config.Smarthost)
+	if err != nil {
+		return nil, fmt.Errorf("smarthost: %w", err)
+	}
+	if config.From == "" {
+		return nil, fmt.Errorf("missing from address")
+	}
+	if len(config.To) == 0 {
+		return nil, fmt.Errorf("missing to addresses")
+	}
+
+	return &email{config: config, host: host}, nil
+}
+
+func (e *email) kind() string {
+	return "email"
+}
+
+func (e *email) send(ctx context.Context, message Message) (bool, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", e.config.Smarthost)
+	if err != nil {
+		return true, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return smtpRecoverable(err), err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: e.host})
+		if err != nil {
+			return smtpRecoverable(err), err
+		}
+	} else if e.config.RequireTLS {
+		return false, fmt.Errorf("%s does not support STARTTLS", e.config.Smarthost)
+	}
+
+	if e.config.Username != "" {
+		err = c.Auth(smtp.PlainAuth("", e.config.Username, e.config.Password, e.host))
+		if err != nil {
+			return smtpRecoverable(err), err
+		}
+	}
+
+	err = c.Mail(e.config.From)
+	if err != nil {
+		return smtpRecoverable(err), err
+	}
+	for _, to := range e.config.To {
+		err = c.Rcpt(to)
+		if err != nil {
+			return smtpRecoverable(err), err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return smtpRecoverable(err), err
+	}
+	_, err = w.Write(e.mail(message))
+	if err != nil {
+		return true, err
+	}
+	err = w.Close()
+	if err != nil {
+		return smtpRecoverable(err), err
+	}
+
+	return false, c.Quit()
+}
+
+// mail formats a plain text mail with CRLF line endings.
+func (e *email) mail(message Message) []byte {
+	var b bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+	}
+	header("From", e.config.From)
+	header("To", strings.Join(e.config.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Title))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+
+	text := strings.ReplaceAll(message.Text, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
+
+// smtpRecoverable reports whether an SMTP failure is transient, permanent
+// failures have a 5xx reply code.
+func smtpRecoverable(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code/100 != 5
+	}
+	return true
+}
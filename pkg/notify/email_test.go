@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zawachte/stalker/pkg/alerting"
+)
+
+// smtpServer is a minimal in-process SMTP server without STARTTLS. It answers
+// the RCPT commands with the codes of rcptCodes in turn, then with 250.
+type smtpServer struct {
+	listener net.Listener
+
+	mu        sync.Mutex
+	rcptCodes []int
+	sessions  int
+	mails     []string
+}
+
+func newSMTPServer(t *testing.T, rcptCodes ...int) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{listener: listener, rcptCodes: rcptCodes}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	s.mu.Lock()
+	s.sessions++
+	s.mu.Unlock()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		fmt.Fprintf(conn, "%s\r\n", line)
+	}
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.Fields(line + " ")[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL", "RSET", "NOOP":
+			reply("250 ok")
+		case "RCPT":
+			s.mu.Lock()
+			code := 250
+			if len(s.rcptCodes) > 0 {
+				code, s.rcptCodes = s.rcptCodes[0], s.rcptCodes[1:]
+			}
+			s.mu.Unlock()
+			reply(fmt.Sprintf("%d recipient", code))
+		case "DATA":
+			reply("354 go ahead")
+			var mail strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				mail.WriteString(line)
+			}
+			s.mu.Lock()
+			s.mails = append(s.mails, mail.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) stats() (int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions, append([]string{}, s.mails...)
+}
+
+func TestEmail(t *testing.T) {
+	srv := newSMTPServer(t)
+	c, err := newEmail(EmailConfig{
+		Smarthost: srv.listener.Addr().String(),
+		From:      "stalker@example.com",
+		To:        []string{"ops@example.com", "db@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = c.send(ctx, Message{
+		Notification: Notification{Status: alerting.StateFiring},
+		Title:        "[FIRING:1] rule=high_cpu",
+		Text:         "firing high_cpu\n  cpu is high",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, mails := srv.stats()
+	if len(mails) != 1 {
+		t.Fatalf("got %d mails, want 1", len(mails))
+	}
+	mail := mails[0]
+	for _, want := range []string{
+		"From: stalker@example.com\r\n",
+		"To: ops@example.com, db@example.com\r\n",
+		"Subject: [FIRING:1] rule=high_cpu\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\n\r\nfiring high_cpu\r\n  cpu is high\r\n",
+	} {
+		if !strings.Contains(mail, want) {
+			t.Errorf("mail %q does not contain %q", mail, want)
+		}
+	}
+}
+
+func TestEmailRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		rcptCodes []int
+		sessions  int
+		failed    bool
+	}{
+		{name: "transient failure", rcptCodes: []int{450}, sessions: 2},
+		{name: "permanent failure", rcptCodes: []int{550}, sessions: 1, failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newSMTPServer(t, tt.rcptCodes...)
+			c, err := newEmail(EmailConfig{
+				Smarthost: srv.listener.Addr().String(),
+				From:      "stalker@example.com",
+				To:        []string{"ops@example.com"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			d := &Dispatcher{ctx: context.Background()}
+			s := &settings{maxRetries: 2, minBackoff: time.Millisecond, maxBackoff: time.Millisecond}
+			err = d.sendWithRetries(s, delivery{receiver: "ops", channel: c, message: Message{Title: "test"}})
+			if (err != nil) != tt.failed {
+				t.Errorf("got error %v, want failure %v", err, tt.failed)
+			}
+
+			sessions, mails := srv.stats()
+			if sessions != tt.sessions {
+				t.Errorf("got %d sessions, want %d", sessions, tt.sessions)
+			}
+			if want := map[bool]int{true: 0, false: 1}[tt.failed]; len(mails) != want {
+				t.Errorf("got %d mails, want %d", len(mails), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,644 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/zawachte/stalker/pkg/alerting"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultGroupInterval  = time.Minute
+	defaultRepeatInterval = 4 * time.Hour
+	defaultQueueCapacity  = 100
+	defaultMaxRetries     = 5
+	defaultMinBackoff     = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultTimeout        = 30 * time.Second
+)
+
+// RuleLabel stands for the rule name of an alert in route matchers and
+// GroupBy.
+const RuleLabel = "rule"
+
+// ErrQueueFull is returned when a notification is dropped because the
+// delivery queue is full.
+var ErrQueueFull = errors.New("notification queue is full")
+
+// Config is the content of a notifier configuration file.
+type Config struct {
+	Receivers []ReceiverConfig `yaml:"receivers"`
+	// Routes send each alert to the receiver of the first route matching it,
+	// alerts matching no route are not notified.
+	Routes []RouteConfig `yaml:"routes"`
+	Retry  RetryConfig   `yaml:"retry"`
+	// DeadLetterFile is appended a JSON line for every notification that
+	// could not be delivered. Failures are only logged when it is empty.
+	DeadLetterFile string `yaml:"deadLetterFile"`
+}
+
+// ReceiverConfig delivers notifications on every channel that is set.
+type ReceiverConfig struct {
+	Name    string         `yaml:"name"`
+	Webhook *WebhookConfig `yaml:"webhook"`
+	Slack   *SlackConfig   `yaml:"slack"`
+	Email   *EmailConfig   `yaml:"email"`
+	// Title and Text are text/template templates executed with a
+	// Notification, defaulting to DefaultTitle and DefaultText.
+	Title string `yaml:"title"`
+	Text  string `yaml:"text"`
+}
+
+// RouteConfig selects alerts by their labels and groups them into
+// notifications.
+type RouteConfig struct {
+	// Match requires alert labels to have these values, "rule" stands for
+	// the rule name. An empty Match selects every alert.
+	Match    map[string]string `yaml:"match"`
+	Receiver string            `yaml:"receiver"`
+	// GroupBy names the labels alerts sharing a notification have in common,
+	// "rule" stands for the rule name. Without it every alert of the route
+	// shares one notification.
+	GroupBy []string `yaml:"groupBy"`
+	// GroupInterval is the least time between two notifications of a group
+	// whose alerts changed, RepeatInterval the time after which a group whose
+	// alerts keep firing is notified again.
+	GroupInterval  string `yaml:"groupInterval"`
+	RepeatInterval string `yaml:"repeatInterval"`
+}
+
+// RetryConfig configures the exponential backoff between delivery attempts.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after a failed delivery, 0 uses the
+	// default and a negative value disables retries.
+	MaxRetries int    `yaml:"maxRetries"`
+	MinBackoff string `yaml:"minBackoff"`
+	MaxBackoff string `yaml:"maxBackoff"`
+}
+
+// Notification is a group of alerts delivered together, it is the data
+// templates are executed with.
+type Notification struct {
+	Receiver string
+	// Status is firing when any of the alerts fires and resolved otherwise.
+	Status      string
+	GroupLabels map[string]string
+	Alerts      []alerting.Alert
+}
+
+// Firing returns the firing alerts of the notification.
+func (n Notification) Firing() []alerting.Alert {
+	return n.withState(alerting.StateFiring)
+}
+
+// Resolved returns the resolved alerts of the notification.
+func (n Notification) Resolved() []alerting.Alert {
+	return n.withState(alerting.StateResolved)
+}
+
+func (n Notification) withState(state string) []alerting.Alert {
+	alerts := []alerting.Alert{}
+	for _, alert := range n.Alerts {
+		if alert.State == state {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// Message is a rendered notification.
+type Message struct {
+	Notification
+	Title string
+	Text  string
+}
+
+// channel delivers messages to one destination.
+type channel interface {
+	kind() string
+	// send delivers a message and reports whether a failure is worth retrying.
+	send(ctx context.Context, message Message) (bool, error)
+}
+
+type receiver struct {
+	name     string
+	channels []channel
+	title    *template.Template
+	text     *template.Template
+}
+
+type route struct {
+	match          map[string]string
+	receiver       *receiver
+	groupBy        []string
+	groupInterval  time.Duration
+	repeatInterval time.Duration
+}
+
+type settings struct {
+	routes         []*route
+	maxRetries     int
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	deadLetterFile string
+}
+
+// load reads and validates a YAML notifier configuration.
+func load(path string) (*settings, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := Config{}
+	err = yaml.UnmarshalStrict(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s, err := compile(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+func compile(config Config) (*settings, error) {
+	receivers := map[string]*receiver{}
+	for i, rc := range config.Receivers {
+		if rc.Name == "" {
+			return nil, fmt.Errorf("receiver %d: missing name", i)
+		}
+		if _, ok := receivers[rc.Name]; ok {
+			return nil, fmt.Errorf("receiver %s: duplicate name", rc.Name)
+		}
+
+		r, err := newReceiver(rc)
+		if err != nil {
+			return nil, fmt.Errorf("receiver %s: %w", rc.Name, err)
+		}
+		receivers[rc.Name] = r
+	}
+
+	s := &settings{
+		maxRetries:     config.Retry.MaxRetries,
+		deadLetterFile: config.DeadLetterFile,
+	}
+	if s.maxRetries < 0 {
+		s.maxRetries = 0
+	} else if s.maxRetries == 0 {
+		s.maxRetries = defaultMaxRetries
+	}
+
+	var err error
+	s.minBackoff, err = parseDuration(config.Retry.MinBackoff, defaultMinBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("retry: minBackoff: %w", err)
+	}
+	s.maxBackoff, err = parseDuration(config.Retry.MaxBackoff, defaultMaxBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("retry: maxBackoff: %w", err)
+	}
+	if s.maxBackoff < s.minBackoff {
+		return nil, fmt.Errorf("retry: maxBackoff %s is shorter than minBackoff %s", s.maxBackoff, s.minBackoff)
+	}
+
+	for i, rc := range config.Routes {
+		r, ok := receivers[rc.Receiver]
+		if !ok {
+			return nil, fmt.Errorf("route %d: unknown receiver %q", i, rc.Receiver)
+		}
+
+		groupInterval, err := parseDuration(rc.GroupInterval, defaultGroupInterval)
+		if err != nil {
+			return nil, fmt.Errorf("route %d: groupInterval: %w", i, err)
+		}
+		repeatInterval, err := parseDuration(rc.RepeatInterval, defaultRepeatInterval)
+		if err != nil {
+			return nil, fmt.Errorf("route %d: repeatInterval: %w", i, err)
+		}
+
+		s.routes = append(s.routes, &route{
+			match:          rc.Match,
+			receiver:       r,
+			groupBy:        rc.GroupBy,
+			groupInterval:  groupInterval,
+			repeatInterval: repeatInterval,
+		})
+	}
+
+	return s, nil
+}
+
+func newReceiver(rc ReceiverConfig) (*receiver, error) {
+	r := &receiver{name: rc.Name}
+
+	if rc.Webhook != nil {
+		c, err := newWebhook(*rc.Webhook)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: %w", err)
+		}
+		r.channels = append(r.channels, c)
+	}
+	if rc.Slack != nil {
+		c, err := newSlack(*rc.Slack)
+		if err != nil {
+			return nil, fmt.Errorf("slack: %w", err)
+		}
+		r.channels = append(r.channels, c)
+	}
+	if rc.Email != nil {
+		c, err := newEmail(*rc.Email)
+		if err != nil {
+			return nil, fmt.Errorf("email: %w", err)
+		}
+		r.channels = append(r.channels, c)
+	}
+	if len(r.channels) == 0 {
+		return nil, fmt.Errorf("no webhook, slack or email configured")
+	}
+
+	var err error
+	r.title, err = parseTemplate("title", rc.Title, DefaultTitle)
+	if err != nil {
+		return nil, err
+	}
+	r.text, err = parseTemplate("text", rc.Text, DefaultText)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive", value)
+	}
+	return d, nil
+}
+
+// matches reports whether the alert has every label of the route.
+func (r *route) matches(alert alerting.Alert) bool {
+	for k, v := range r.match {
+		if labelValue(alert, k) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *route) groupLabels(alert alerting.Alert) map[string]string {
+	labels := map[string]string{}
+	for _, name := range r.groupBy {
+		labels[name] = labelValue(alert, name)
+	}
+	return labels
+}
+
+func labelValue(alert alerting.Alert, name string) string {
+	if name == RuleLabel {
+		return alert.Rule
+	}
+	return alert.Labels[name]
+}
+
+// group is the delivery state of the alerts sharing a notification.
+type group struct {
+	lastSent time.Time
+	// notified holds the keys of the alerts last notified as firing.
+	notified map[string]struct{}
+}
+
+type delivery struct {
+	receiver string
+	channel  channel
+	message  Message
+}
+
+// Dispatcher routes alerts to receivers, groups them and delivers the
+// notifications in the background, retrying failed deliveries with
+// exponential backoff.
+type Dispatcher struct {
+	path string
+
+	mu       sync.Mutex
+	settings *settings
+	groups   map[string]*group
+
+	queue  chan delivery
+	closed bool
+	wg     sync.WaitGroup
+	once   sync.Once
+	// ctx is cancelled on Close to stop backing off.
+	ctx  context.Context
+	stop context.CancelFunc
+}
+
+// NewDispatcher loads the notifier configuration at path and starts
+// delivering notifications. An empty path yields a dispatcher without routes.
+func NewDispatcher(path string) (*Dispatcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		path:     path,
+		settings: &settings{},
+		groups:   map[string]*group{},
+		queue:    make(chan delivery, defaultQueueCapacity),
+		ctx:      ctx,
+		stop:     cancel,
+	}
+
+	if path != "" {
+		err := d.Reload()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	d.wg.Add(1)
+	go d.run()
+
+	return d, nil
+}
+
+// Reload re-reads the configuration file. The previous configuration stays in
+// effect when the new one is invalid.
+func (d *Dispatcher) Reload() error {
+	if d.path == "" {
+		return nil
+	}
+
+	s, err := load(d.path)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.settings = s
+	d.mu.Unlock()
+
+	return nil
+}
+
+// Close stops accepting notifications and makes a last attempt at delivering
+// the queued ones, which are dead-lettered when it fails.
+func (d *Dispatcher) Close() error {
+	d.once.Do(func() {
+		d.mu.Lock()
+		d.closed = true
+		close(d.queue)
+		d.mu.Unlock()
+		d.stop()
+	})
+	d.wg.Wait()
+	return nil
+}
+
+// Notify groups the firing and resolved alerts by route and queues the
+// notifications of groups that changed since their last notification, once
+// the group interval passed, or that keep firing past the repeat interval.
+func (d *Dispatcher) Notify(now time.Time, alerts []alerting.Alert) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+
+	type pending struct {
+		route       *route
+		groupLabels map[string]string
+		alerts      []alerting.Alert
+	}
+	current := map[string]*pending{}
+	for _, alert := range alerts {
+		if alert.State == alerting.StatePending {
+			continue
+		}
+
+		for _, r := range d.settings.routes {
+			if !r.matches(alert) {
+				continue
+			}
+
+			labels := r.groupLabels(alert)
+			key := r.receiver.name + "\xff" + groupKey(labels)
+			p, ok := current[key]
+			if !ok {
+				p = &pending{route: r, groupLabels: labels}
+				current[key] = p
+			}
+			p.alerts = append(p.alerts, alert)
+			break
+		}
+	}
+
+	// Groups without alerts have nothing left to resolve.
+	for key := range d.groups {
+		if _, ok := current[key]; !ok {
+			delete(d.groups, key)
+		}
+	}
+
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		p := current[key]
+		g, ok := d.groups[key]
+		if !ok {
+			g = &group{notified: map[string]struct{}{}}
+			d.groups[key] = g
+		}
+
+		firing := map[string]struct{}{}
+		notification := Notification{
+			Receiver:    p.route.receiver.name,
+			Status:      alerting.StateResolved,
+			GroupLabels: p.groupLabels,
+		}
+		changed := false
+		for _, alert := range p.alerts {
+			_, wasNotified := g.notified[alert.Key()]
+			switch alert.State {
+			case alerting.StateFiring:
+				firing[alert.Key()] = struct{}{}
+				notification.Status = alerting.StateFiring
+				notification.Alerts = append(notification.Alerts, alert)
+				changed = changed || !wasNotified
+			case alerting.StateResolved:
+				// Alerts resolved before their firing was notified are dropped.
+				if wasNotified {
+					notification.Alerts = append(notification.Alerts, alert)
+					changed = true
+				}
+			}
+		}
+		if len(notification.Alerts) == 0 {
+			continue
+		}
+
+		since := now.Sub(g.lastSent)
+		due := g.lastSent.IsZero() ||
+			(changed && since >= p.route.groupInterval) ||
+			(len(firing) > 0 && since >= p.route.repeatInterval)
+		if !due {
+			continue
+		}
+
+		g.lastSent = now
+		g.notified = firing
+		d.enqueue(p.route.receiver, notification)
+	}
+}
+
+// enqueue renders a notification and queues its delivery on every channel
+// of the receiver.
+func (d *Dispatcher) enqueue(r *receiver, notification Notification) {
+	message, err := render(r, notification)
+	if err != nil {
+		deadLetter(d.settings.deadLetterFile, r.name, "", message, err)
+		return
+	}
+
+	for _, c := range r.channels {
+		select {
+		case d.queue <- delivery{receiver: r.name, channel: c, message: message}:
+		default:
+			deadLetter(d.settings.deadLetterFile, r.name, c.kind(), message, ErrQueueFull)
+		}
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	for delivery := range d.queue {
+		d.mu.Lock()
+		s := d.settings
+		d.mu.Unlock()
+
+		err := d.sendWithRetries(s, delivery)
+		if err != nil {
+			deadLetter(s.deadLetterFile, delivery.receiver, delivery.channel.kind(), delivery.message, err)
+		}
+	}
+}
+
+func (d *Dispatcher) sendWithRetries(s *settings, delivery delivery) error {
+	backoff := s.minBackoff
+
+	var err error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-d.ctx.Done():
+				return fmt.Errorf("closed before retrying: %w", err)
+			}
+			backoff *= 2
+			if backoff > s.maxBackoff {
+				backoff = s.maxBackoff
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+		var recoverable bool
+		recoverable, err = delivery.channel.send(ctx, delivery.message)
+		cancel()
+		if err == nil || !recoverable {
+			return err
+		}
+	}
+
+	return err
+}
+
+// deadLetterEntry is a line of the dead-letter file.
+type deadLetterEntry struct {
+	Time     time.Time      `json:"time"`
+	Receiver string         `json:"receiver"`
+	Channel  string         `json:"channel,omitempty"`
+	Error    string         `json:"error"`
+	Message  webhookPayload `json:"message"`
+}
+
+// deadLetterLock serializes the writes to dead-letter files.
+var deadLetterLock sync.Mutex
+
+// deadLetter logs a notification that could not be delivered and appends it
+// to the dead-letter file at path, if any.
+func deadLetter(path, receiver, channel string, message Message, cause error) {
+	log.Printf("failed to notify receiver %s %s: %v", receiver, channel, cause)
+	if path == "" {
+		return
+	}
+
+	deadLetterLock.Lock()
+	defer deadLetterLock.Unlock()
+
+	data, err := json.Marshal(deadLetterEntry{
+		Time:     time.Now(),
+		Receiver: receiver,
+		Channel:  channel,
+		Error:    cause.Error(),
+		Message:  newWebhookPayload(message),
+	})
+	if err != nil {
+		log.Printf("failed to encode dead letter: %v", err)
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Printf("failed to open dead-letter file: %v", err)
+		return
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	if err != nil {
+		log.Printf("failed to write dead-letter file: %v", err)
+	}
+}
+
+func groupKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte(0xff)
+		b.WriteString(labels[k])
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
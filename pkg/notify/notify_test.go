@@ -0,0 +1,260 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zawachte/stalker/pkg/alerting"
+)
+
+func newAlert(rule, state string, labels map[string]string) alerting.Alert {
+	return alerting.Alert{
+		Rule:     rule,
+		Expr:     "cpu_usage_rate > 0.9",
+		State:    state,
+		Labels:   labels,
+		Value:    0.95,
+		ActiveAt: time.Unix(1600000000, 0),
+	}
+}
+
+// newTestDispatcher starts a dispatcher of the configuration config.
+func newTestDispatcher(t *testing.T, config string) *Dispatcher {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notifier.yaml")
+	err := os.WriteFile(path, []byte(config), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewDispatcher(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// idleDispatcher returns a dispatcher of config whose deliveries are left in
+// its queue.
+func idleDispatcher(t *testing.T, config Config) *Dispatcher {
+	t.Helper()
+	s, err := compile(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Dispatcher{
+		settings: s,
+		groups:   map[string]*group{},
+		queue:    make(chan delivery, defaultQueueCapacity),
+		ctx:      context.Background(),
+	}
+}
+
+func queued(d *Dispatcher) []delivery {
+	deliveries := []delivery{}
+	for {
+		select {
+		case delivery := <-d.queue:
+			deliveries = append(deliveries, delivery)
+		default:
+			return deliveries
+		}
+	}
+}
+
+func TestNotifyGroupAndRepeatInterval(t *testing.T) {
+	d := idleDispatcher(t, Config{
+		Receivers: []ReceiverConfig{{Name: "ops", Webhook: &WebhookConfig{Url: "http://127.0.0.1:1/hook"}}},
+		Routes: []RouteConfig{{
+			Receiver:       "ops",
+			GroupBy:        []string{RuleLabel},
+			GroupInterval:  "1m",
+			RepeatInterval: "1h",
+		}},
+	})
+
+	a := map[string]string{"container_name": "/a"}
+	b := map[string]string{"container_name": "/b"}
+	start := time.Unix(1600000000, 0)
+	steps := []struct {
+		name   string
+		after  time.Duration
+		alerts []alerting.Alert
+		// alerts of the notification sent, -1 when none is sent.
+		sent   int
+		status string
+	}{
+		{name: "first alert", after: 0, alerts: []alerting.Alert{newAlert("high_cpu", alerting.StateFiring, a)}, sent: 1, status: alerting.StateFiring},
+		{name: "pending alerts are ignored", after: 5 * time.Second, alerts: []alerting.Alert{newAlert("high_cpu", alerting.StateFiring, a), newAlert("high_cpu", alerting.StatePending, b)}, sent: -1},
+		{name: "new alert within the group interval", after: 10 * time.Second, alerts: []alerting.Alert{newAlert("high_cpu", alerting.StateFiring, a), newAlert("high_cpu", alerting.StateFiring, b)}, sent: -1},
+		{name: "new alert after the group interval", after: time.Minute, alerts: []alerting.Alert{newAlert("high_cpu", alerting.StateFiring, a), newAlert("high_cpu", alerting.StateFiring, b)}, sent: 2, status: alerting.StateFiring},
+		{name: "unchanged within the repeat interval", after: 30 * time.Minute, alerts: []alerting.Alert{newAlert("high_cpu", alerting.StateFiring, a), newAlert("high_cpu", alerting.StateFiring, b)}, sent: -1},
+		{name: "unchanged after the repeat interval", after: time.Hour + time.Minute, alerts: []alerting.Alert{newAlert("high_cpu", alerting.StateFiring, a), newAlert("high_cpu", alerting.StateFiring, b)}, sent: 2, status: alerting.StateFiring},
+		{name: "resolved alert", after: time.Hour + 2*time.Minute, alerts: []alerting.Alert{newAlert("high_cpu", alerting.StateResolved, a), newAlert("high_cpu", alerting.StateFiring, b)}, sent: 2, status: alerting.StateFiring},
+		{name: "every alert resolved", after: time.Hour + 3*time.Minute, alerts: []alerting.Alert{newAlert("high_cpu", alerting.StateResolved, a), newAlert("high_cpu", alerting.StateResolved, b)}, sent: 1, status: alerting.StateResolved},
+	}
+
+	for _, step := range steps {
+		d.Notify(start.Add(step.after), step.alerts)
+		deliveries := queued(d)
+		if step.sent < 0 {
+			if len(deliveries) != 0 {
+				t.Errorf("%s: sent %d notifications, want none", step.name, len(deliveries))
+			}
+			continue
+		}
+		if len(deliveries) != 1 {
+			t.Errorf("%s: sent %d notifications, want 1", step.name, len(deliveries))
+			continue
+		}
+		message := deliveries[0].message
+		if len(message.Alerts) != step.sent || message.Status != step.status {
+			t.Errorf("%s: sent %s notification of %d alerts, want %s of %d", step.name, message.Status, len(message.Alerts), step.status, step.sent)
+		}
+		if message.GroupLabels[RuleLabel] != "high_cpu" {
+			t.Errorf("%s: group labels %v", step.name, message.GroupLabels)
+		}
+	}
+}
+
+func TestNotifyRoutes(t *testing.T) {
+	d := idleDispatcher(t, Config{
+		Receivers: []ReceiverConfig{
+			{Name: "db", Webhook: &WebhookConfig{Url: "http://127.0.0.1:1/db"}},
+			{Name: "ops", Webhook: &WebhookConfig{Url: "http://127.0.0.1:1/ops"}},
+		},
+		Routes: []RouteConfig{
+			{Match: map[string]string{"namespace": "db"}, Receiver: "db"},
+			{Match: map[string]string{RuleLabel: "high_cpu"}, Receiver: "ops", GroupBy: []string{"namespace"}},
+		},
+	})
+
+	d.Notify(time.Unix(1600000000, 0), []alerting.Alert{
+		newAlert("high_cpu", alerting.StateFiring, map[string]string{"namespace": "db"}),
+		newAlert("high_cpu", alerting.StateFiring, map[string]string{"namespace": "web"}),
+		newAlert("high_cpu", alerting.StateFiring, map[string]string{"namespace": "api"}),
+		newAlert("high_memory", alerting.StateFiring, map[string]string{"namespace": "web"}),
+	})
+
+	got := []string{}
+	for _, delivery := range queued(d) {
+		got = append(got, delivery.receiver+":"+delivery.message.GroupLabels["namespace"])
+	}
+	want := []string{"db:", "ops:api", "ops:web"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("notified %v, want %v", got, want)
+	}
+}
+
+// flakyServer answers the requests with the statuses of codes in turn, then
+// with 200.
+func flakyServer(t *testing.T, codes ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := http.StatusOK
+		if i := int(atomic.AddInt32(&requests, 1)) - 1; i < len(codes) {
+			code = codes[i]
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestSendWithRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []int
+		requests int
+		failed   bool
+	}{
+		{name: "server errors", codes: []int{500, 503}, requests: 3},
+		{name: "rate limited", codes: []int{429}, requests: 2},
+		{name: "client error", codes: []int{400}, requests: 1, failed: true},
+		{name: "retries exhausted", codes: []int{500, 500, 500, 500}, requests: 3, failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := flakyServer(t, tt.codes...)
+			c, err := newWebhook(WebhookConfig{Url: srv.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			d := &Dispatcher{ctx: context.Background()}
+			s := &settings{maxRetries: 2, minBackoff: time.Millisecond, maxBackoff: time.Millisecond}
+			err = d.sendWithRetries(s, delivery{receiver: "ops", channel: c, message: Message{}})
+			if (err != nil) != tt.failed {
+				t.Errorf("got error %v, want failure %v", err, tt.failed)
+			}
+			if atomic.LoadInt32(requests) != int32(tt.requests) {
+				t.Errorf("got %d requests, want %d", atomic.LoadInt32(requests), tt.requests)
+			}
+		})
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	srv, requests := flakyServer(t, 400)
+	deadLetters := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	d := newTestDispatcher(t, `
+receivers:
+  - name: ops
+    webhook:
+      url: `+srv.URL+`
+routes:
+  - receiver: ops
+    groupBy: [rule]
+retry:
+  minBackoff: 1ms
+  maxBackoff: 1ms
+deadLetterFile: `+deadLetters+`
+`)
+
+	d.Notify(time.Unix(1600000000, 0), []alerting.Alert{
+		newAlert("high_cpu", alerting.StateFiring, map[string]string{"container_name": "/a"}),
+	})
+	d.Close()
+
+	if atomic.LoadInt32(requests) != 1 {
+		t.Errorf("got %d requests, want 1", atomic.LoadInt32(requests))
+	}
+
+	file, err := os.Open(deadLetters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	entries := []deadLetterEntry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := deadLetterEntry{}
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Receiver != "ops" || entry.Channel != "webhook" || !strings.Contains(entry.Error, "400") {
+		t.Errorf("dead letter %+v, want a 400 of the ops webhook", entry)
+	}
+	if len(entry.Message.Alerts) != 1 || entry.Message.Alerts[0].Rule != "high_cpu" || entry.Message.Title != "[FIRING:1] rule=high_cpu" {
+		t.Errorf("dead letter message %+v", entry.Message)
+	}
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// DefaultTitle summarizes a notification on one line.
+const DefaultTitle = `[{{ .Status | upper }}{{ if eq .Status "firing" }}:{{ len .Firing }}{{ end }}]` +
+	`{{ range $name, $value := .GroupLabels }} {{ $name }}={{ $value }}{{ end }}`
+
+// DefaultText lists the alerts of a notification, one per line followed by
+// their summary annotation.
+const DefaultText = `{{ range .Alerts }}{{ .State }} {{ .Rule }}: {{ .Expr }} ({{ printf "%.4g" .Value }})` +
+	`{{ range $name, $value := .Labels }} {{ $name }}={{ $value }}{{ end }}
+{{ with index .Annotations "summary" }}  {{ . }}
+{{ end }}{{ end }}`
+
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+}
+
+func parseTemplate(name, text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// render executes the templates of a receiver on a notification.
+func render(r *receiver, notification Notification) (Message, error) {
+	message := Message{Notification: notification}
+
+	var b bytes.Buffer
+	err := r.title.Execute(&b, notification)
+	if err != nil {
+		return message, err
+	}
+	message.Title = strings.TrimSpace(b.String())
+
+	b.Reset()
+	err = r.text.Execute(&b, notification)
+	if err != nil {
+		return message, err
+	}
+	message.Text = strings.TrimSpace(b.String())
+
+	return message, nil
+}
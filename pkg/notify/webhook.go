@@ -0,0 +1,204 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/zawachte/stalker/pkg/alerting"
+)
+
+// WebhookConfig posts notifications as JSON to a URL.
+type WebhookConfig struct {
+	Url     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// SlackConfig posts notifications to a Slack-compatible incoming webhook.
+type SlackConfig struct {
+	Url string `yaml:"url"`
+	// Channel and Username override the defaults of the incoming webhook.
+	Channel  string `yaml:"channel"`
+	Username string `yaml:"username"`
+}
+
+// webhookPayload is the body posted by webhooks.
+type webhookPayload struct {
+	Receiver    string            `json:"receiver"`
+	Status      string            `json:"status"`
+	GroupLabels map[string]string `json:"groupLabels"`
+	Title       string            `json:"title"`
+	Text        string            `json:"text"`
+	Alerts      []webhookAlert    `json:"alerts"`
+}
+
+type webhookAlert struct {
+	Rule        string            `json:"rule"`
+	Expr        string            `json:"expr"`
+	State       string            `json:"state"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Value       float64           `json:"value"`
+	ActiveAt    time.Time         `json:"activeAt"`
+	FiredAt     *time.Time        `json:"firedAt,omitempty"`
+	ResolvedAt  *time.Time        `json:"resolvedAt,omitempty"`
+}
+
+func newWebhookPayload(message Message) webhookPayload {
+	payload := webhookPayload{
+		Receiver:    message.Receiver,
+		Status:      message.Status,
+		GroupLabels: message.GroupLabels,
+		Title:       message.Title,
+		Text:        message.Text,
+		Alerts:      []webhookAlert{},
+	}
+	for _, alert := range message.Alerts {
+		payload.Alerts = append(payload.Alerts, newWebhookAlert(alert))
+	}
+	return payload
+}
+
+func newWebhookAlert(alert alerting.Alert) webhookAlert {
+	a := webhookAlert{
+		Rule:        alert.Rule,
+		Expr:        alert.Expr,
+		State:       alert.State,
+		Labels:      alert.Labels,
+		Annotations: alert.Annotations,
+		Value:       alert.Value,
+		ActiveAt:    alert.ActiveAt,
+	}
+	if !alert.FiredAt.IsZero() {
+		firedAt := alert.FiredAt
+		a.FiredAt = &firedAt
+	}
+	if !alert.ResolvedAt.IsZero() {
+		resolvedAt := alert.ResolvedAt
+		a.ResolvedAt = &resolvedAt
+	}
+	return a
+}
+
+type webhook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newWebhook(config WebhookConfig) (*webhook, error) {
+	err := validateUrl(config.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook{
+		url:     config.Url,
+		headers: config.Headers,
+		client:  &http.Client{},
+	}, nil
+}
+
+func (w *webhook) kind() string {
+	return "webhook"
+}
+
+func (w *webhook) send(ctx context.Context, message Message) (bool, error) {
+	return postJson(ctx, w.client, w.url, w.headers, newWebhookPayload(message))
+}
+
+type slack struct {
+	url      string
+	channel  string
+	username string
+	client   *http.Client
+}
+
+func newSlack(config SlackConfig) (*slack, error) {
+	err := validateUrl(config.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &slack{
+		url:      config.Url,
+		channel:  config.Channel,
+		username: config.Username,
+		client:   &http.Client{},
+	}, nil
+}
+
+func (s *slack) kind() string {
+	return "slack"
+}
+
+// slackPayload is the subset of the incoming webhook payload understood by
+// Slack and the chat servers mimicking it.
+type slackPayload struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+func (s *slack) send(ctx context.Context, message Message) (bool, error) {
+	text := message.Title
+	if message.Text != "" {
+		text += "\n" + message.Text
+	}
+
+	return postJson(ctx, s.client, s.url, nil, slackPayload{
+		Text:     text,
+		Channel:  s.channel,
+		Username: s.username,
+	})
+}
+
+func validateUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url %q must be http or https", rawUrl)
+	}
+	return nil
+}
+
+// postJson posts v and reports whether a failure is worth retrying.
+func postJson(ctx context.Context, client *http.Client, url string, headers map[string]string, v interface{}) (bool, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "stalker")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("server returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	recoverable := resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+	return recoverable, err
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zawachte/stalker/pkg/alerting"
+)
+
+// captureServer records the headers and JSON bodies posted to it.
+func captureServer(t *testing.T) (*httptest.Server, chan *http.Request, chan map[string]interface{}) {
+	t.Helper()
+	requests := make(chan *http.Request, 10)
+	bodies := make(chan map[string]interface{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests <- r
+		bodies <- body
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests, bodies
+}
+
+func TestWebhook(t *testing.T) {
+	srv, requests, bodies := captureServer(t)
+	d := newTestDispatcher(t, `
+receivers:
+  - name: ops
+    webhook:
+      url: `+srv.URL+`
+      headers:
+        Authorization: Bearer secret
+routes:
+  - receiver: ops
+    groupBy: [rule]
+`)
+
+	alert := newAlert("high_cpu", alerting.StateFiring, map[string]string{"container_name": "/a"})
+	alert.Annotations = map[string]string{"summary": "cpu is high"}
+	alert.FiredAt = time.Unix(1600000060, 0).UTC()
+	d.Notify(time.Unix(1600000060, 0), []alerting.Alert{alert})
+	d.Close()
+
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	req := <-requests
+	if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	body := <-bodies
+	for key, want := range map[string]interface{}{
+		"receiver": "ops",
+		"status":   "firing",
+		"title":    "[FIRING:1] rule=high_cpu",
+		"text":     "firing high_cpu: cpu_usage_rate > 0.9 (0.95) container_name=/a\n  cpu is high",
+	} {
+		if body[key] != want {
+			t.Errorf("%s = %q, want %q", key, body[key], want)
+		}
+	}
+	alerts, _ := body["alerts"].([]interface{})
+	if len(alerts) != 1 {
+		t.Fatalf("alerts = %v, want one alert", body["alerts"])
+	}
+	a := alerts[0].(map[string]interface{})
+	if a["rule"] != "high_cpu" || a["state"] != "firing" || a["firedAt"] != "2020-09-13T12:27:40Z" || a["resolvedAt"] != nil {
+		t.Errorf("alert = %v", a)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	srv, requests, bodies := captureServer(t)
+	d := newTestDispatcher(t, `
+receivers:
+  - name: chat
+    slack:
+      url: `+srv.URL+`
+      channel: "#alerts"
+      username: stalker
+    title: "{{ .Status | upper }} {{ len .Alerts }}"
+    text: "{{ range .Alerts }}{{ .Rule }} {{ index .Labels \"container_name\" }}{{ end }}"
+routes:
+  - receiver: chat
+`)
+
+	d.Notify(time.Unix(1600000000, 0), []alerting.Alert{
+		newAlert("high_cpu", alerting.StateFiring, map[string]string{"container_name": "/a"}),
+	})
+	d.Close()
+
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	body := <-bodies
+	for key, want := range map[string]interface{}{
+		"text":     "FIRING 1\nhigh_cpu /a",
+		"channel":  "#alerts",
+		"username": "stalker",
+	} {
+		if body[key] != want {
+			t.Errorf("%s = %q, want %q", key, body[key], want)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Supported OTLP transports.
+const (
+	ProtocolHTTP = "http/protobuf"
+	ProtocolGRPC = "grpc"
+)
+
+const (
+	metricsPath       = "/v1/metrics"
+	exportGRPCMethod  = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	defaultBatchSize  = 1000
+	defaultFlushEvery = 10 * time.Second
+	defaultQueueSize  = 100
+	defaultRetries    = 5
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+	defaultTimeout    = 10 * time.Second
+
+	// seriesTTL is how long the start of a cumulative series is remembered
+	// after its last sample.
+	seriesTTL = 10 * time.Minute
+)
+
+// ErrQueueFull is returned when points are dropped because the export queue is full.
+var ErrQueueFull = errors.New("otlp export queue is full")
+
+type ExporterParams struct {
+	// Endpoint of the collector. For http/protobuf this is a base url such as
+	// http://collector:4318, for grpc a host:port such as collector:4317.
+	Endpoint string
+	Protocol string
+	// Insecure disables TLS for grpc connections.
+	Insecure bool
+	Headers  map[string]string
+	// Number of points sent in a single export request.
+	BatchSize int
+	// Maximum time points wait in a partial batch before being exported.
+	FlushInterval time.Duration
+	// Number of scrapes buffered before new points are dropped.
+	QueueCapacity int
+	// Number of retries for a batch that failed with a recoverable error.
+	// Zero uses the default, a negative value disables retries.
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Timeout    time.Duration
+}
+
+// Exporter batches points and sends them to an OpenTelemetry collector.
+type Exporter struct {
+	params    ExporterParams
+	transport transport
+	queue     chan []*write.Point
+	// starts is only used by the worker.
+	starts *StartTimes
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// transport sends one serialized ExportMetricsServiceRequest. It reports
+// whether a failure is worth retrying.
+type transport interface {
+	export(ctx context.Context, body []byte) (bool, error)
+	close() error
+}
+
+// NewExporter creates an exporter for the configured protocol and starts its
+// background worker.
+func NewExporter(params ExporterParams) (*Exporter, error) {
+	if params.Endpoint == "" {
+		return nil, errors.New("otlp endpoint must be set")
+	}
+	if params.BatchSize <= 0 {
+		params.BatchSize = defaultBatchSize
+	}
+	if params.FlushInterval <= 0 {
+		params.FlushInterval = defaultFlushEvery
+	}
+	if params.QueueCapacity <= 0 {
+		params.QueueCapacity = defaultQueueSize
+	}
+	if params.MaxRetries < 0 {
+		params.MaxRetries = 0
+	} else if params.MaxRetries == 0 {
+		params.MaxRetries = defaultRetries
+	}
+	if params.MinBackoff <= 0 {
+		params.MinBackoff = defaultMinBackoff
+	}
+	if params.MaxBackoff <= 0 {
+		params.MaxBackoff = defaultMaxBackoff
+	}
+	if params.Timeout <= 0 {
+		params.Timeout = defaultTimeout
+	}
+
+	var t transport
+	var err error
+	switch params.Protocol {
+	case "", ProtocolHTTP:
+		t, err = newHTTPTransport(params)
+	case ProtocolGRPC:
+		t, err = newGRPCTransport(params)
+	default:
+		err = fmt.Errorf("unsupported otlp protocol %q, must be %q or %q", params.Protocol, ProtocolHTTP, ProtocolGRPC)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exporter{
+		params:    params,
+		transport: t,
+		queue:     make(chan []*write.Point, params.QueueCapacity),
+		starts:    NewStartTimes(),
+	}
+
+	e.wg.Add(1)
+	go e.run()
+
+	return e, nil
+}
+
+// WritePoints queues points for export. It never blocks; when the queue is
+// full the points are dropped and ErrQueueFull is returned.
+func (e *Exporter) WritePoints(ctx context.Context, points []*write.Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	select {
+	case e.queue <- points:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting points, exports what is still queued and closes the
+// connection to the collector.
+func (e *Exporter) Close() error {
+	e.once.Do(func() {
+		close(e.queue)
+	})
+	e.wg.Wait()
+	return e.transport.close()
+}
+
+func (e *Exporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.params.FlushInterval)
+	defer ticker.Stop()
+
+	pending := []*write.Point{}
+	for {
+		select {
+		case points, ok := <-e.queue:
+			if !ok {
+				e.flush(pending)
+				return
+			}
+			pending = append(pending, points...)
+			for len(pending) >= e.params.BatchSize {
+				e.flush(pending[:e.params.BatchSize])
+				pending = pending[e.params.BatchSize:]
+			}
+		case <-ticker.C:
+			e.flush(pending)
+			pending = []*write.Point{}
+		}
+	}
+}
+
+func (e *Exporter) flush(points []*write.Point) {
+	if len(points) == 0 {
+		return
+	}
+
+	body := EncodeExportRequest(points, e.starts)
+	e.starts.Expire(time.Now().Add(-seriesTTL))
+	backoff := e.params.MinBackoff
+
+	var err error
+	for attempt := 0; attempt <= e.params.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > e.params.MaxBackoff {
+				backoff = e.params.MaxBackoff
+			}
+		}
+
+		var recoverable bool
+		recoverable, err = e.export(body)
+		if err == nil || !recoverable {
+			break
+		}
+	}
+
+	if err != nil {
+		log.Printf("otlp: dropping %d points: %v", len(points), err)
+	}
+}
+
+func (e *Exporter) export(body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.params.Timeout)
+	defer cancel()
+
+	return e.transport.export(ctx, body)
+}
+
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newHTTPTransport(params ExporterParams) (*httpTransport, error) {
+	url := strings.TrimSuffix(params.Endpoint, "/")
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("otlp http endpoint %q must be an http or https url", params.Endpoint)
+	}
+	if !strings.HasSuffix(url, metricsPath) {
+		url = url + metricsPath
+	}
+
+	return &httpTransport{
+		url:     url,
+		headers: params.Headers,
+		client:  &http.Client{},
+	}, nil
+}
+
+func (t *httpTransport) export(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "stalker")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("otlp collector returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, err
+	}
+	return false, err
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+type grpcTransport struct {
+	conn    *grpc.ClientConn
+	headers metadata.MD
+}
+
+func newGRPCTransport(params ExporterParams) (*grpcTransport, error) {
+	creds := grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	if params.Insecure {
+		creds = grpc.WithInsecure()
+	}
+
+	conn, err := grpc.Dial(params.Endpoint, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcTransport{
+		conn:    conn,
+		headers: metadata.New(params.Headers),
+	}, nil
+}
+
+func (t *grpcTransport) export(ctx context.Context, body []byte) (bool, error) {
+	ctx = metadata.NewOutgoingContext(ctx, t.headers)
+
+	resp := rawMessage{}
+	err := t.conn.Invoke(ctx, exportGRPCMethod, rawMessage(body), &resp, grpc.ForceCodec(rawCodec{}))
+	if err == nil {
+		return false, nil
+	}
+
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+		codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return true, err
+	}
+	return false, err
+}
+
+func (t *grpcTransport) close() error {
+	return t.conn.Close()
+}
+
+// rawMessage is an already serialized protobuf message.
+type rawMessage []byte
+
+// rawCodec passes serialized protobuf messages through unchanged.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return msg, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*msg = append((*msg)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
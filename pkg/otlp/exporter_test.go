@@ -0,0 +1,209 @@
+package otlp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// testExporter returns an exporter of t without its background worker,
+// batches are sent by calling flush.
+func testExporter(t transport) *Exporter {
+	return &Exporter{
+		params: ExporterParams{
+			MaxRetries: 2,
+			MinBackoff: time.Millisecond,
+			MaxBackoff: time.Millisecond,
+			Timeout:    5 * time.Second,
+		},
+		transport: t,
+		starts:    NewStartTimes(),
+	}
+}
+
+func testPoints() []*write.Point {
+	return []*write.Point{containerPoint("cpu_usage_total", nil, 10.0, 0)}
+}
+
+func TestHTTPExporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []int
+		requests int
+	}{
+		{name: "accepted", requests: 1},
+		{name: "unavailable", codes: []int{503, 502}, requests: 3},
+		{name: "rate limited", codes: []int{429}, requests: 2},
+		{name: "retries exhausted", codes: []int{503, 503, 503, 503}, requests: 3},
+		{name: "bad request", codes: []int{400}, requests: 1},
+		{name: "server error", codes: []int{500}, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := int(atomic.AddInt32(&requests, 1)) - 1
+				if r.URL.Path != metricsPath || r.Header.Get("Content-Type") != "application/x-protobuf" || r.Header.Get("X-Scope-OrgID") != "tenant" {
+					t.Errorf("got request %s %v", r.URL.Path, r.Header)
+				}
+				body, _ := io.ReadAll(r.Body)
+				req := &colmetricspb.ExportMetricsServiceRequest{}
+				if err := proto.Unmarshal(body, req); err != nil || len(req.ResourceMetrics) != 1 {
+					t.Errorf("got request %v: %v", req, err)
+				}
+				if i < len(tt.codes) {
+					w.WriteHeader(tt.codes[i])
+				}
+			}))
+			defer srv.Close()
+
+			transport, err := newHTTPTransport(ExporterParams{Endpoint: srv.URL + "/", Headers: map[string]string{"X-Scope-OrgID": "tenant"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := testExporter(transport)
+			e.flush(testPoints())
+			e.transport.close()
+
+			if got := atomic.LoadInt32(&requests); got != int32(tt.requests) {
+				t.Errorf("got %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+// metricsServer answers the exports with the codes in turn, then with OK.
+type metricsServer struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	codes    []codes.Code
+	requests int32
+}
+
+func (s *metricsServer) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	i := int(atomic.AddInt32(&s.requests, 1)) - 1
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(req.ResourceMetrics) != 1 || len(md.Get("x-scope-orgid")) != 1 || md.Get("x-scope-orgid")[0] != "tenant" {
+		return nil, status.Errorf(codes.InvalidArgument, "got request %v with metadata %v", req, md)
+	}
+	if i < len(s.codes) {
+		return nil, status.Error(s.codes[i], "failed")
+	}
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+// grpcExporter serves s in process and returns an exporter sending to it.
+func grpcExporter(t *testing.T, s *metricsServer) *Exporter {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	colmetricspb.RegisterMetricsServiceServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return testExporter(&grpcTransport{conn: conn, headers: metadata.New(map[string]string{"X-Scope-OrgID": "tenant"})})
+}
+
+func TestGRPCExporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []codes.Code
+		requests int
+	}{
+		{name: "accepted", requests: 1},
+		{name: "unavailable", codes: []codes.Code{codes.Unavailable, codes.ResourceExhausted}, requests: 3},
+		{name: "retries exhausted", codes: []codes.Code{codes.Unavailable, codes.Unavailable, codes.Unavailable}, requests: 3},
+		{name: "invalid argument", codes: []codes.Code{codes.InvalidArgument}, requests: 1},
+		{name: "unauthenticated", codes: []codes.Code{codes.Unauthenticated}, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &metricsServer{codes: tt.codes}
+			e := grpcExporter(t, s)
+
+			e.flush(testPoints())
+			if got := atomic.LoadInt32(&s.requests); got != int32(tt.requests) {
+				t.Errorf("got %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestGRPCTransportRetries(t *testing.T) {
+	tests := []struct {
+		code        codes.Code
+		recoverable bool
+	}{
+		{code: codes.Unavailable, recoverable: true},
+		{code: codes.DeadlineExceeded, recoverable: true},
+		{code: codes.InvalidArgument, recoverable: false},
+		{code: codes.PermissionDenied, recoverable: false},
+	}
+
+	for _, tt := range tests {
+		e := grpcExporter(t, &metricsServer{codes: []codes.Code{tt.code}})
+		recoverable, err := e.export(EncodeExportRequest(testPoints(), NewStartTimes()))
+		if status.Code(err) != tt.code || recoverable != tt.recoverable {
+			t.Errorf("got %v recoverable %v, want %v recoverable %v", err, recoverable, tt.code, tt.recoverable)
+		}
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+
+	e, err := NewExporter(ExporterParams{Endpoint: srv.URL, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.WritePoints(context.Background(), testPoints())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Close exports the partial batch.
+	err = e.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("got %d requests, want 1", atomic.LoadInt32(&requests))
+	}
+
+	for _, params := range []ExporterParams{
+		{},
+		{Endpoint: "collector:4318"},
+		{Endpoint: srv.URL, Protocol: "http/json"},
+	} {
+		if _, err := NewExporter(params); err == nil {
+			t.Errorf("NewExporter(%+v) succeeded", params)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package otlp
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/influx"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Resource attribute keys from the OpenTelemetry semantic conventions.
+const (
+	attrContainerName      = "container.name"
+	attrContainerImageName = "container.image.name"
+	attrContainerImageTag  = "container.image.tag"
+	attrHostName           = "host.name"
+	attrServiceName        = "service.name"
+)
+
+const (
+	scopeName = "github.com/zawachte/stalker"
+
+	// AGGREGATION_TEMPORALITY_CUMULATIVE
+	aggregationTemporalityCumulative = 2
+)
+
+// Field numbers of the opentelemetry.proto.metrics.v1 messages.
+const (
+	fieldExportRequestResourceMetrics = 1
+
+	fieldResourceMetricsResource     = 1
+	fieldResourceMetricsScopeMetrics = 2
+
+	fieldResourceAttributes = 1
+
+	fieldScopeMetricsScope   = 1
+	fieldScopeMetricsMetrics = 2
+
+	fieldScopeName = 1
+
+	fieldMetricName  = 1
+	fieldMetricGauge = 5
+	fieldMetricSum   = 7
+
+	fieldGaugeDataPoints = 1
+
+	fieldSumDataPoints             = 1
+	fieldSumAggregationTemporality = 2
+	fieldSumIsMonotonic            = 3
+
+	fieldDataPointStartTimeUnixNano = 2
+	fieldDataPointTimeUnixNano      = 3
+	fieldDataPointAsDouble          = 4
+	fieldDataPointAsInt             = 6
+	fieldDataPointAttributes        = 7
+
+	fieldKeyValueKey   = 1
+	fieldKeyValueValue = 2
+
+	fieldAnyValueString = 1
+)
+
+type keyValue struct {
+	key   string
+	value string
+}
+
+type dataPoint struct {
+	attributes []keyValue
+	// startNanos is the start of cumulative sums, zero for gauges.
+	startNanos uint64
+	timeNanos  uint64
+	intValue   int64
+	floatValue float64
+	isInt      bool
+}
+
+type metric struct {
+	name       string
+	cumulative bool
+	dataPoints []dataPoint
+}
+
+type resourceMetrics struct {
+	attributes []keyValue
+	metrics    []*metric
+	index      map[string]*metric
+}
+
+// StartTimes tracks the start of the cumulative series of an exporter. A
+// series starts when it is first seen and starts again after its value
+// decreased, which backends read as a counter reset.
+type StartTimes struct {
+	series map[string]*seriesStart
+}
+
+type seriesStart struct {
+	startNanos uint64
+	lastNanos  uint64
+	last       float64
+}
+
+// NewStartTimes creates a tracker without series.
+func NewStartTimes() *StartTimes {
+	return &StartTimes{series: map[string]*seriesStart{}}
+}
+
+// start returns the start of the series key at a sample of value.
+func (st *StartTimes) start(key string, timeNanos uint64, value float64) uint64 {
+	s, ok := st.series[key]
+	if !ok {
+		s = &seriesStart{startNanos: timeNanos, lastNanos: timeNanos, last: value}
+		st.series[key] = s
+		return s.startNanos
+	}
+	if timeNanos < s.lastNanos {
+		// A late sample does not move the series.
+		return s.startNanos
+	}
+	if value < s.last {
+		s.startNanos = s.lastNanos + 1
+	}
+	s.lastNanos = timeNanos
+	s.last = value
+	return s.startNanos
+}
+
+// Expire forgets the series not seen since before, which start again when
+// they come back.
+func (st *StartTimes) Expire(before time.Time) {
+	for key, s := range st.series {
+		if s.lastNanos < uint64(before.UnixNano()) {
+			delete(st.series, key)
+		}
+	}
+}
+
+// EncodeExportRequest converts points into a serialized
+// ExportMetricsServiceRequest. Points are grouped into one resource per
+// container, identified by the machine, container name and image tags.
+// Cumulative series become monotonic Sums starting at the times tracked by
+// starts, everything else becomes a Gauge.
+func EncodeExportRequest(points []*write.Point, starts *StartTimes) []byte {
+	var b []byte
+	for _, rm := range groupByResource(points, starts) {
+		b = protowire.AppendTag(b, fieldExportRequestResourceMetrics, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeResourceMetrics(rm))
+	}
+	return b
+}
+
+func groupByResource(points []*write.Point, starts *StartTimes) []*resourceMetrics {
+	resources := []*resourceMetrics{}
+	index := map[string]*resourceMetrics{}
+
+	for _, point := range points {
+		resourceAttrs, pointAttrs := splitAttributes(point)
+		key := attributesKey(resourceAttrs)
+		rm, ok := index[key]
+		if !ok {
+			rm = &resourceMetrics{
+				attributes: resourceAttrs,
+				index:      map[string]*metric{},
+			}
+			index[key] = rm
+			resources = append(resources, rm)
+		}
+
+		for _, field := range point.FieldList() {
+			dp, ok := newDataPoint(field.Value)
+			if !ok {
+				continue
+			}
+			dp.attributes = pointAttrs
+			dp.timeNanos = uint64(point.Time().UnixNano())
+
+			name := point.Name()
+			if field.Key != influx.ValueField {
+				name = name + "_" + field.Key
+			}
+
+			m, ok := rm.index[name]
+			if !ok {
+				m = &metric{
+					name:       name,
+					cumulative: influx.IsCumulative(point.Name()),
+				}
+				rm.index[name] = m
+				rm.metrics = append(rm.metrics, m)
+			}
+			if m.cumulative {
+				dp.startNanos = starts.start(key+name+"\xff"+attributesKey(pointAttrs), dp.timeNanos, dp.value())
+			}
+			m.dataPoints = append(m.dataPoints, dp)
+		}
+	}
+
+	return resources
+}
+
+// splitAttributes separates the tags that identify the container into
+// resource attributes and keeps the rest as data point attributes.
+func splitAttributes(point *write.Point) ([]keyValue, []keyValue) {
+	resourceAttrs := []keyValue{{key: attrServiceName, value: "stalker"}}
+	pointAttrs := []keyValue{}
+
+	for _, tag := range point.TagList() {
+		if tag.Value == "" {
+			continue
+		}
+
+		switch tag.Key {
+		case influx.TagMachineName:
+			resourceAttrs = append(resourceAttrs, keyValue{key: attrHostName, value: tag.Value})
+		case influx.TagContainerName:
+			resourceAttrs = append(resourceAttrs, keyValue{key: attrContainerName, value: tag.Value})
+		case influx.TagContainerImage:
+			name, imageTag := splitImage(tag.Value)
+			resourceAttrs = append(resourceAttrs, keyValue{key: attrContainerImageName, value: name})
+			if imageTag != "" {
+				resourceAttrs = append(resourceAttrs, keyValue{key: attrContainerImageTag, value: imageTag})
+			}
+		default:
+			pointAttrs = append(pointAttrs, keyValue{key: tag.Key, value: tag.Value})
+		}
+	}
+
+	sort.Slice(resourceAttrs, func(i, j int) bool {
+		return resourceAttrs[i].key < resourceAttrs[j].key
+	})
+	return resourceAttrs, pointAttrs
+}
+
+// splitImage splits an image reference like registry:5000/app:1.2 into its
+// name and tag.
+func splitImage(image string) (string, string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	slash := strings.LastIndex(image, "/")
+	colon := strings.LastIndex(image, ":")
+	if colon > slash {
+		return image[:colon], image[colon+1:]
+	}
+	return image, ""
+}
+
+func attributesKey(attrs []keyValue) string {
+	var b strings.Builder
+	for _, attr := range attrs {
+		b.WriteString(attr.key)
+		b.WriteByte(0xff)
+		b.WriteString(attr.value)
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
+
+func newDataPoint(value interface{}) (dataPoint, bool) {
+	switch v := value.(type) {
+	case int64:
+		return dataPoint{intValue: v, isInt: true}, true
+	case uint64:
+		if v <= math.MaxInt64 {
+			return dataPoint{intValue: int64(v), isInt: true}, true
+		}
+		return dataPoint{floatValue: float64(v)}, true
+	}
+
+	f, ok := influx.ToFloat(value)
+	if !ok {
+		return dataPoint{}, false
+	}
+	return dataPoint{floatValue: f}, true
+}
+
+func (dp dataPoint) value() float64 {
+	if dp.isInt {
+		return float64(dp.intValue)
+	}
+	return dp.floatValue
+}
+
+func encodeResourceMetrics(rm *resourceMetrics) []byte {
+	var resource []byte
+	for _, attr := range rm.attributes {
+		resource = protowire.AppendTag(resource, fieldResourceAttributes, protowire.BytesType)
+		resource = protowire.AppendBytes(resource, encodeKeyValue(attr))
+	}
+
+	var scope []byte
+	scope = protowire.AppendTag(scope, fieldScopeName, protowire.BytesType)
+	scope = protowire.AppendString(scope, scopeName)
+
+	var scopeMetrics []byte
+	scopeMetrics = protowire.AppendTag(scopeMetrics, fieldScopeMetricsScope, protowire.BytesType)
+	scopeMetrics = protowire.AppendBytes(scopeMetrics, scope)
+	for _, m := range rm.metrics {
+		scopeMetrics = protowire.AppendTag(scopeMetrics, fieldScopeMetricsMetrics, protowire.BytesType)
+		scopeMetrics = protowire.AppendBytes(scopeMetrics, encodeMetric(m))
+	}
+
+	var b []byte
+	b = protowire.AppendTag(b, fieldResourceMetricsResource, protowire.BytesType)
+	b = protowire.AppendBytes(b, resource)
+	b = protowire.AppendTag(b, fieldResourceMetricsScopeMetrics, protowire.BytesType)
+	b = protowire.AppendBytes(b, scopeMetrics)
+	return b
+}
+
+func encodeMetric(m *metric) []byte {
+	dataPointsField := protowire.Number(fieldGaugeDataPoints)
+	if m.cumulative {
+		dataPointsField = fieldSumDataPoints
+	}
+
+	var data []byte
+	for _, dp := range m.dataPoints {
+		data = protowire.AppendTag(data, dataPointsField, protowire.BytesType)
+		data = protowire.AppendBytes(data, encodeDataPoint(dp))
+	}
+
+	var b []byte
+	b = protowire.AppendTag(b, fieldMetricName, protowire.BytesType)
+	b = protowire.AppendString(b, m.name)
+	if m.cumulative {
+		data = protowire.AppendTag(data, fieldSumAggregationTemporality, protowire.VarintType)
+		data = protowire.AppendVarint(data, aggregationTemporalityCumulative)
+		data = protowire.AppendTag(data, fieldSumIsMonotonic, protowire.VarintType)
+		data = protowire.AppendVarint(data, protowire.EncodeBool(true))
+
+		b = protowire.AppendTag(b, fieldMetricSum, protowire.BytesType)
+	} else {
+		b = protowire.AppendTag(b, fieldMetricGauge, protowire.BytesType)
+	}
+	b = protowire.AppendBytes(b, data)
+	return b
+}
+
+func encodeDataPoint(dp dataPoint) []byte {
+	var b []byte
+	if dp.startNanos != 0 {
+		b = protowire.AppendTag(b, fieldDataPointStartTimeUnixNano, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, dp.startNanos)
+	}
+	b = protowire.AppendTag(b, fieldDataPointTimeUnixNano, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, dp.timeNanos)
+	if dp.isInt {
+		b = protowire.AppendTag(b, fieldDataPointAsInt, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(dp.intValue))
+	} else {
+		b = protowire.AppendTag(b, fieldDataPointAsDouble, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(dp.floatValue))
+	}
+	for _, attr := range dp.attributes {
+		b = protowire.AppendTag(b, fieldDataPointAttributes, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeKeyValue(attr))
+	}
+	return b
+}
+
+func encodeKeyValue(kv keyValue) []byte {
+	var value []byte
+	value = protowire.AppendTag(value, fieldAnyValueString, protowire.BytesType)
+	value = protowire.AppendString(value, kv.value)
+
+	var b []byte
+	b = protowire.AppendTag(b, fieldKeyValueKey, protowire.BytesType)
+	b = protowire.AppendString(b, kv.key)
+	b = protowire.AppendTag(b, fieldKeyValueValue, protowire.BytesType)
+	b = protowire.AppendBytes(b, value)
+	return b
+}
@@ -0,0 +1,159 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+var testStart = time.Unix(1600000000, 0)
+
+func containerPoint(name string, tags map[string]string, value interface{}, after time.Duration) *write.Point {
+	all := map[string]string{
+		"machine":         "node-1",
+		"container_name":  "/web",
+		"container_image": "registry:5000/web:1.2",
+	}
+	for k, v := range tags {
+		all[k] = v
+	}
+	return write.NewPoint(name, all, map[string]interface{}{"value": value}, testStart.Add(after))
+}
+
+func decodeExportRequest(t *testing.T, body []byte) *colmetricspb.ExportMetricsServiceRequest {
+	t.Helper()
+	req := &colmetricspb.ExportMetricsServiceRequest{}
+	err := proto.Unmarshal(body, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func attributes(kvs []*commonpb.KeyValue) map[string]string {
+	attrs := map[string]string{}
+	for _, kv := range kvs {
+		attrs[kv.Key] = kv.Value.GetStringValue()
+	}
+	return attrs
+}
+
+func TestEncodeExportRequest(t *testing.T) {
+	points := []*write.Point{
+		containerPoint("cpu_usage_total", nil, uint64(100), 0),
+		containerPoint("memory_usage", nil, 2048.5, 0),
+		containerPoint("cpu_usage_per_cpu", map[string]string{"instance": "1"}, int64(7), 0),
+		containerPoint("memory_usage", map[string]string{"container_name": "/db", "container_image": "db"}, 512.0, 0),
+	}
+	req := decodeExportRequest(t, EncodeExportRequest(points, NewStartTimes()))
+
+	if len(req.ResourceMetrics) != 2 {
+		t.Fatalf("got %d resources, want one per container", len(req.ResourceMetrics))
+	}
+
+	web := req.ResourceMetrics[0]
+	wantResource := map[string]string{
+		attrServiceName:        "stalker",
+		attrHostName:           "node-1",
+		attrContainerName:      "/web",
+		attrContainerImageName: "registry:5000/web",
+		attrContainerImageTag:  "1.2",
+	}
+	if got := attributes(web.Resource.Attributes); len(got) != len(wantResource) {
+		t.Errorf("got resource attributes %v, want %v", got, wantResource)
+	} else {
+		for k, v := range wantResource {
+			if got[k] != v {
+				t.Errorf("resource attribute %s = %q, want %q", k, got[k], v)
+			}
+		}
+	}
+	if db := attributes(req.ResourceMetrics[1].Resource.Attributes); db[attrContainerImageName] != "db" || db[attrContainerImageTag] != "" {
+		t.Errorf("got resource attributes %v for an image without a tag", db)
+	}
+
+	if len(web.ScopeMetrics) != 1 || web.ScopeMetrics[0].Scope.Name != scopeName {
+		t.Fatalf("got scope metrics %v, want the stalker scope", web.ScopeMetrics)
+	}
+	metrics := web.ScopeMetrics[0].Metrics
+	if len(metrics) != 3 {
+		t.Fatalf("got %d metrics, want 3", len(metrics))
+	}
+
+	cpu := metrics[0]
+	sum := cpu.GetSum()
+	if cpu.Name != "cpu_usage_total" || sum == nil {
+		t.Fatalf("got %s %v, want the sum cpu_usage_total", cpu.Name, cpu.Data)
+	}
+	if !sum.IsMonotonic || sum.AggregationTemporality != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+		t.Errorf("got monotonic %v temporality %v, want a monotonic cumulative sum", sum.IsMonotonic, sum.AggregationTemporality)
+	}
+	dp := sum.DataPoints[0]
+	if dp.GetAsInt() != 100 || dp.TimeUnixNano != uint64(testStart.UnixNano()) || dp.StartTimeUnixNano != dp.TimeUnixNano {
+		t.Errorf("got cpu_usage_total point %v", dp)
+	}
+
+	memory := metrics[1]
+	gauge := memory.GetGauge()
+	if memory.Name != "memory_usage" || gauge == nil {
+		t.Fatalf("got %s %v, want the gauge memory_usage", memory.Name, memory.Data)
+	}
+	dp = gauge.DataPoints[0]
+	if dp.GetAsDouble() != 2048.5 || dp.StartTimeUnixNano != 0 || len(dp.Attributes) != 0 {
+		t.Errorf("got memory_usage point %v", dp)
+	}
+
+	perCPU := metrics[2].GetSum()
+	if perCPU == nil || len(perCPU.DataPoints) != 1 {
+		t.Fatalf("got cpu_usage_per_cpu %v, want a sum of one point", metrics[2].Data)
+	}
+	if attrs := attributes(perCPU.DataPoints[0].Attributes); len(attrs) != 1 || attrs["instance"] != "1" {
+		t.Errorf("got point attributes %v, want instance=1", attrs)
+	}
+}
+
+func TestEncodeExportRequestStartTimes(t *testing.T) {
+	starts := NewStartTimes()
+	batches := []struct {
+		name  string
+		after time.Duration
+		value float64
+		// wantStart is the start of the series relative to testStart.
+		wantStart time.Duration
+	}{
+		{name: "first sample", after: 0, value: 10, wantStart: 0},
+		{name: "increase", after: 10 * time.Second, value: 20, wantStart: 0},
+		{name: "late sample", after: 5 * time.Second, value: 15, wantStart: 0},
+		{name: "reset", after: 20 * time.Second, value: 5, wantStart: 10*time.Second + 1},
+		{name: "after the reset", after: 30 * time.Second, value: 8, wantStart: 10*time.Second + 1},
+	}
+
+	for _, batch := range batches {
+		points := []*write.Point{
+			containerPoint("cpu_usage_total", nil, batch.value, batch.after),
+			// Another container has its own start.
+			containerPoint("cpu_usage_total", map[string]string{"container_name": "/db"}, 1000.0-batch.value, batch.after),
+		}
+		req := decodeExportRequest(t, EncodeExportRequest(points, starts))
+
+		dp := req.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].GetSum().DataPoints[0]
+		if want := uint64(testStart.Add(batch.wantStart).UnixNano()); dp.StartTimeUnixNano != want {
+			t.Errorf("%s: got start %d, want %d", batch.name, dp.StartTimeUnixNano, want)
+		}
+		if dp.GetAsDouble() != batch.value {
+			t.Errorf("%s: got value %v, want %v", batch.name, dp.GetAsDouble(), batch.value)
+		}
+	}
+
+	starts.Expire(testStart.Add(time.Hour))
+	req := decodeExportRequest(t, EncodeExportRequest([]*write.Point{containerPoint("cpu_usage_total", nil, 100.0, time.Hour)}, starts))
+	dp := req.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].GetSum().DataPoints[0]
+	if dp.StartTimeUnixNano != dp.TimeUnixNano {
+		t.Errorf("got start %d after expiry, want the time of the sample %d", dp.StartTimeUnixNano, dp.TimeUnixNano)
+	}
+}
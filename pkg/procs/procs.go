@@ -0,0 +1,268 @@
+package procs
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Orders processes can be ranked by.
+const (
+	ByCPU = "cpu"
+	ByRSS = "rss"
+)
+
+// userHZ is the unit of the cpu times in /proc/<pid>/stat.
+const userHZ = 100
+
+// Where cgroup hierarchies holding a cgroup.procs file are mounted, unified
+// hierarchy first.
+var cgroupMounts = []string{
+	"/sys/fs/cgroup",
+	"/sys/fs/cgroup/pids",
+	"/sys/fs/cgroup/cpu,cpuacct",
+	"/sys/fs/cgroup/cpu",
+	"/sys/fs/cgroup/systemd",
+}
+
+// Process is a single process of a container.
+type Process struct {
+	Pid        int
+	Command    string
+	CpuPercent float64
+	RssBytes   uint64
+}
+
+type cpuSample struct {
+	ticks     uint64
+	startTime uint64
+	at        time.Time
+	// percent is the usage computed when the sample was taken.
+	percent float64
+}
+
+// Sampler reads the processes of cgroups from /proc. CPU usage is the
+// average since the previous sample of the same process, so the first sample
+// of a process reports no usage. Only Processes takes samples, Latest reports
+// the usage of the last one.
+type Sampler struct {
+	procRoot string
+	pageSize uint64
+
+	mu      sync.Mutex
+	samples map[int]cpuSample
+}
+
+// NewSampler creates a sampler reading the host /proc.
+func NewSampler() *Sampler {
+	return &Sampler{
+		procRoot: "/proc",
+		pageSize: uint64(os.Getpagesize()),
+		samples:  map[int]cpuSample{},
+	}
+}
+
+// Top returns the n processes of the cgroup using the most CPU or memory,
+// depending on by.
+func (s *Sampler) Top(cgroup string, n int, by string) ([]Process, error) {
+	processes, err := s.Processes(cgroup)
+	if err != nil {
+		return nil, err
+	}
+
+	return Top(processes, n, by), nil
+}
+
+// Top returns the n processes using the most CPU or memory, depending on by.
+func Top(processes []Process, n int, by string) []Process {
+	sorted := append([]Process{}, processes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if by == ByRSS {
+			return sorted[i].RssBytes > sorted[j].RssBytes
+		}
+		return sorted[i].CpuPercent > sorted[j].CpuPercent
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// Processes returns the processes that are direct members of the cgroup.
+func (s *Sampler) Processes(cgroup string) ([]Process, error) {
+	pids, err := cgroupPids(cgroup)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	processes := []Process{}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pid := range pids {
+		stat, err := s.readStat(pid)
+		if err != nil {
+			// The process exited since the cgroup was read.
+			continue
+		}
+
+		process := Process{
+			Pid:      pid,
+			Command:  stat.command,
+			RssBytes: stat.rssPages * s.pageSize,
+		}
+		previous, ok := s.samples[pid]
+		if ok && previous.startTime == stat.startTime && stat.ticks >= previous.ticks {
+			elapsed := now.Sub(previous.at).Seconds()
+			if elapsed > 0 {
+				process.CpuPercent = float64(stat.ticks-previous.ticks) / userHZ / elapsed * 100
+			}
+		}
+		s.samples[pid] = cpuSample{ticks: stat.ticks, startTime: stat.startTime, at: now, percent: process.CpuPercent}
+
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// Latest returns the processes that are direct members of the cgroup with
+// the CPU usage of their last sample, without taking a new one. Processes
+// never sampled report no usage.
+func (s *Sampler) Latest(cgroup string) ([]Process, error) {
+	pids, err := cgroupPids(cgroup)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := []Process{}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pid := range pids {
+		stat, err := s.readStat(pid)
+		if err != nil {
+			continue
+		}
+
+		process := Process{
+			Pid:      pid,
+			Command:  stat.command,
+			RssBytes: stat.rssPages * s.pageSize,
+		}
+		sample, ok := s.samples[pid]
+		if ok && sample.startTime == stat.startTime {
+			process.CpuPercent = sample.percent
+		}
+
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// Expire forgets the cpu samples of processes not seen since the deadline.
+func (s *Sampler) Expire(deadline time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for pid, sample := range s.samples {
+		if sample.at.Before(deadline) {
+			delete(s.samples, pid)
+		}
+	}
+}
+
+type procStat struct {
+	command   string
+	ticks     uint64
+	startTime uint64
+	rssPages  uint64
+}
+
+// readStat parses /proc/<pid>/stat, see proc(5).
+func (s *Sampler) readStat(pid int) (procStat, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.procRoot, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return procStat{}, err
+	}
+
+	// The command may contain spaces and parentheses, it ends at the last ')'.
+	line := string(data)
+	open := strings.IndexByte(line, '(')
+	end := strings.LastIndexByte(line, ')')
+	if open < 0 || end < open {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+
+	// Fields after the command, starting with the state (field 3).
+	fields := strings.Fields(line[end+1:])
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return procStat{}, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return procStat{}, err
+	}
+	startTime, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return procStat{}, err
+	}
+	rss, err := strconv.ParseInt(fields[21], 10, 64)
+	if err != nil {
+		return procStat{}, err
+	}
+	if rss < 0 {
+		rss = 0
+	}
+
+	return procStat{
+		command:   line[open+1 : end],
+		ticks:     utime + stime,
+		startTime: startTime,
+		rssPages:  uint64(rss),
+	}, nil
+}
+
+// cgroupPids reads the member processes of a cgroup from the first hierarchy
+// that has it.
+func cgroupPids(cgroup string) ([]int, error) {
+	for _, mount := range cgroupMounts {
+		file, err := os.Open(filepath.Join(mount, cgroup, "cgroup.procs"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		pids := []int{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			pid, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+			if err != nil {
+				continue
+			}
+			pids = append(pids, pid)
+		}
+		return pids, scanner.Err()
+	}
+
+	return nil, fmt.Errorf("no cgroup.procs found for cgroup %q", cgroup)
+}
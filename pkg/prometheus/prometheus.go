@@ -0,0 +1,184 @@
+package prometheus
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+// PointsToMetricFamilies groups points into Prometheus metric families, one per
+// series name. Point tags become labels and cumulative series become counters.
+// Only the most recent sample of every label set is kept.
+func PointsToMetricFamilies(points []*write.Point) []*dto.MetricFamily {
+	families := map[string]*dto.MetricFamily{}
+	samples := map[string]*dto.Metric{}
+
+	for _, point := range points {
+		for _, field := range point.FieldList() {
+			value, ok := influx.ToFloat(field.Value)
+			if !ok {
+				continue
+			}
+
+			name := point.Name()
+			if field.Key != influx.ValueField {
+				name = name + "_" + field.Key
+			}
+			name = SanitizeMetricName(name)
+
+			family, ok := families[name]
+			if !ok {
+				metricType := dto.MetricType_GAUGE
+				if influx.IsCumulative(point.Name()) {
+					metricType = dto.MetricType_COUNTER
+				}
+				family = &dto.MetricFamily{
+					Name: stringPtr(name),
+					Type: &metricType,
+				}
+				families[name] = family
+			}
+
+			labels := pointLabels(point)
+			key := name + "{" + labelKey(labels) + "}"
+			timestampMs := point.Time().UnixNano() / 1e6
+
+			if existing, ok := samples[key]; ok {
+				if existing.GetTimestampMs() > timestampMs {
+					continue
+				}
+				setValue(existing, family.GetType(), value)
+				existing.TimestampMs = &timestampMs
+				continue
+			}
+
+			metric := &dto.Metric{
+				Label:       labels,
+				TimestampMs: &timestampMs,
+			}
+			setValue(metric, family.GetType(), value)
+			family.Metric = append(family.Metric, metric)
+			samples[key] = metric
+		}
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]*dto.MetricFamily, 0, len(names))
+	for _, name := range names {
+		result = append(result, families[name])
+	}
+	return result
+}
+
+// WriteMetricFamilies encodes families to w in the given exposition format.
+func WriteMetricFamilies(w io.Writer, format expfmt.Format, families []*dto.MetricFamily) error {
+	encoder := expfmt.NewEncoder(w, format)
+	for _, family := range families {
+		err := encoder.Encode(family)
+		if err != nil {
+			return err
+		}
+	}
+
+	if closer, ok := encoder.(expfmt.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// SanitizeMetricName replaces every character that is not valid in a
+// Prometheus metric name with an underscore.
+func SanitizeMetricName(name string) string {
+	return sanitize(name, true)
+}
+
+// SanitizeLabelName replaces every character that is not valid in a
+// Prometheus label name with an underscore. Names reserved for internal use
+// (starting with "__") get their leading underscores collapsed.
+func SanitizeLabelName(name string) string {
+	name = sanitize(name, false)
+	if strings.HasPrefix(name, "__") {
+		name = "_" + strings.TrimLeft(name, "_")
+	}
+	return name
+}
+
+func sanitize(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r == ':' && allowColon:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// pointLabels converts the tags of a point into sorted, sanitized labels. When
+// two tags sanitize to the same label name the first one wins.
+func pointLabels(point *write.Point) []*dto.LabelPair {
+	seen := map[string]bool{}
+	labels := []*dto.LabelPair{}
+	for _, tag := range point.TagList() {
+		name := SanitizeLabelName(tag.Key)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		labels = append(labels, &dto.LabelPair{
+			Name:  stringPtr(name),
+			Value: stringPtr(tag.Value),
+		})
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetName() < labels[j].GetName()
+	})
+	return labels
+}
+
+func labelKey(labels []*dto.LabelPair) string {
+	var b strings.Builder
+	for _, label := range labels {
+		b.WriteString(label.GetName())
+		b.WriteByte(0xff)
+		b.WriteString(label.GetValue())
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
+
+func setValue(metric *dto.Metric, metricType dto.MetricType, value float64) {
+	if metricType == dto.MetricType_COUNTER {
+		metric.Counter = &dto.Counter{Value: &value}
+		return
+	}
+	metric.Gauge = &dto.Gauge{Value: &value}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,119 @@
+package psi
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Resources with pressure stall information.
+const (
+	CPU    = "cpu"
+	Memory = "memory"
+	IO     = "io"
+)
+
+// Resources lists every resource pressure is reported for.
+var Resources = []string{CPU, Memory, IO}
+
+// Kinds of stalls. Some tasks or all non-idle tasks stalled on the resource.
+const (
+	Some = "some"
+	Full = "full"
+)
+
+const (
+	cgroupRoot   = "/sys/fs/cgroup"
+	pressureRoot = "/proc/pressure"
+)
+
+// Pressure is one line of a pressure file. Averages are percentages of wall
+// time, Total is the cumulative stall time in microseconds.
+type Pressure struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64
+}
+
+// Stats holds the pressure of one resource by kind.
+type Stats map[string]Pressure
+
+// Supported reports whether the host runs the unified cgroup hierarchy with
+// pressure stall information enabled.
+func Supported() bool {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(pressureRoot, CPU))
+	return err == nil
+}
+
+// Read returns the pressure of every resource of a cgroup. Resources without
+// a pressure file are left out. The root cgroup reports the system wide
+// pressure.
+func Read(cgroup string) (map[string]Stats, error) {
+	result := map[string]Stats{}
+	for _, resource := range Resources {
+		filename := filepath.Join(cgroupRoot, cgroup, resource+".pressure")
+		if cgroup == "/" {
+			filename = filepath.Join(pressureRoot, resource)
+		}
+
+		stats, err := readFile(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[resource] = stats
+	}
+	return result, nil
+}
+
+// readFile parses lines such as
+// "some avg10=0.00 avg60=0.00 avg300=0.00 total=0".
+func readFile(filename string) (Stats, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stats := Stats{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		pressure := Pressure{}
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("%s: malformed field %q", filename, field)
+			}
+			switch parts[0] {
+			case "avg10":
+				pressure.Avg10, err = strconv.ParseFloat(parts[1], 64)
+			case "avg60":
+				pressure.Avg60, err = strconv.ParseFloat(parts[1], 64)
+			case "avg300":
+				pressure.Avg300, err = strconv.ParseFloat(parts[1], 64)
+			case "total":
+				pressure.Total, err = strconv.ParseUint(parts[1], 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+		}
+		stats[fields[0]] = pressure
+	}
+
+	return stats, scanner.Err()
+}
@@ -0,0 +1,118 @@
+package push
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config lists the agents allowed to push to a central stalker.
+type Config struct {
+	Agents []AgentConfig `yaml:"agents"`
+}
+
+// AgentConfig authenticates an agent and bounds what it may push.
+type AgentConfig struct {
+	// Name is the node the points of the agent are tagged with.
+	Name string `yaml:"name"`
+	// Token is the bearer token of the agent.
+	Token string `yaml:"token"`
+	// PointsPerMinute is the quota of the agent, which may push up to a
+	// minute worth of points at once. 0 disables the quota.
+	PointsPerMinute int `yaml:"pointsPerMinute"`
+}
+
+// Load reads and validates a YAML agents configuration.
+func Load(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	config := Config{}
+	err = yaml.UnmarshalStrict(data, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	names := map[string]bool{}
+	tokens := map[string]bool{}
+	for i, agent := range config.Agents {
+		switch {
+		case agent.Name == "":
+			return Config{}, fmt.Errorf("%s: agent %d has no name", path, i)
+		case agent.Token == "":
+			return Config{}, fmt.Errorf("%s: agent %s has no token", path, agent.Name)
+		case agent.PointsPerMinute < 0:
+			return Config{}, fmt.Errorf("%s: agent %s has a negative quota", path, agent.Name)
+		case names[agent.Name]:
+			return Config{}, fmt.Errorf("%s: agent %s is listed twice", path, agent.Name)
+		case tokens[agent.Token]:
+			return Config{}, fmt.Errorf("%s: agent %s shares its token with another agent", path, agent.Name)
+		}
+		names[agent.Name] = true
+		tokens[agent.Token] = true
+	}
+
+	return config, nil
+}
+
+// AgentsFile is an agents configuration file that can be reloaded while
+// pushes are accepted.
+type AgentsFile struct {
+	path string
+
+	mu     sync.RWMutex
+	agents []AgentConfig
+}
+
+// NewAgentsFile loads the agents configuration at path. An empty path yields
+// a file without agents.
+func NewAgentsFile(path string) (*AgentsFile, error) {
+	file := &AgentsFile{path: path}
+	if path == "" {
+		return file, nil
+	}
+
+	err := file.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Reload re-reads the configuration file. The previous agents stay in effect
+// when the new configuration is invalid.
+func (f *AgentsFile) Reload() error {
+	if f.path == "" {
+		return nil
+	}
+
+	config, err := Load(f.path)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.agents = config.Agents
+	f.mu.Unlock()
+
+	return nil
+}
+
+// Authenticate returns the agent whose token is token.
+func (f *AgentsFile) Authenticate(token string) (AgentConfig, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, agent := range f.agents {
+		if subtle.ConstantTimeCompare([]byte(agent.Token), []byte(token)) == 1 {
+			return agent, true
+		}
+	}
+	return AgentConfig{}, false
+}
@@ -0,0 +1,452 @@
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	lineprotocol "github.com/influxdata/line-protocol"
+	"github.com/zawachte/stalker/pkg/client"
+	"golang.org/x/net/http2"
+)
+
+const (
+	// DefaultBatchSize is the number of points of a pushed batch.
+	DefaultBatchSize      = 5000
+	defaultMaxBufferBytes = 256 << 20
+	defaultMinBackoff     = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultTimeout        = 30 * time.Second
+)
+
+// Files of the buffer, named <sequence>-<batch id>.lp.gz so they sort oldest
+// first.
+const (
+	batchSuffix = ".lp.gz"
+	tmpSuffix   = ".tmp"
+)
+
+type PusherParams struct {
+	// Url of the central stalker, its --listen-address as an http(s) url.
+	Url string
+	// Token of the agent in the --push-agents file of the central stalker.
+	Token string
+	// BufferPath is the directory batches are kept in until the central
+	// stalker accepted them.
+	BufferPath string
+	// MaxBufferBytes caps the size of the buffer, the oldest batches are
+	// dropped beyond it.
+	MaxBufferBytes int64
+	BatchSize      int
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+	// TLSConfig configures https connections and may be nil.
+	TLSConfig *tls.Config
+}
+
+// Pusher pushes points to a central stalker. Points are written to the
+// buffer as batches of gzip compressed line protocol and sent oldest first by
+// a background worker over HTTP/2, cleartext for http urls. Batches stay in
+// the buffer until the central stalker accepted them, across outages and
+// restarts, and keep their id when they are retried so they are stored once.
+type Pusher struct {
+	params PusherParams
+	client *client.Client
+
+	mu      sync.Mutex
+	batches []spooledBatch
+	bytes   int64
+	seq     int64
+
+	wake chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// spooledBatch is a batch file of the buffer.
+type spooledBatch struct {
+	name string
+	size int64
+}
+
+func (b spooledBatch) id() string {
+	name := strings.TrimSuffix(b.name, batchSuffix)
+	return name[strings.Index(name, "-")+1:]
+}
+
+// NewPusher creates a pusher, queues the batches left in the buffer and
+// starts its background worker.
+func NewPusher(params PusherParams) (*Pusher, error) {
+	u, err := url.Parse(params.Url)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("push url %q must be http or https", params.Url)
+	}
+	if params.BufferPath == "" {
+		return nil, errors.New("pushing needs a buffer path")
+	}
+
+	if params.MaxBufferBytes <= 0 {
+		params.MaxBufferBytes = defaultMaxBufferBytes
+	}
+	if params.BatchSize <= 0 {
+		params.BatchSize = DefaultBatchSize
+	}
+	if params.MinBackoff <= 0 {
+		params.MinBackoff = defaultMinBackoff
+	}
+	if params.MaxBackoff <= 0 {
+		params.MaxBackoff = defaultMaxBackoff
+	}
+	if params.Timeout <= 0 {
+		params.Timeout = defaultTimeout
+	}
+
+	opts := []client.ClientOption{
+		client.WithHTTPClient(newHTTPClient(u.Scheme, params.TLSConfig, params.Timeout)),
+	}
+	if params.Token != "" {
+		opts = append(opts, client.WithBearerToken(params.Token))
+	}
+	c, err := client.NewClient(params.Url, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pusher{
+		params: params,
+		client: c,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	err = p.load()
+	if err != nil {
+		return nil, err
+	}
+
+	p.wg.Add(1)
+	go p.run()
+
+	return p, nil
+}
+
+// newHTTPClient returns a client speaking HTTP/2, over cleartext for http
+// as served by the --listen-address of stalker.
+func newHTTPClient(scheme string, tlsConfig *tls.Config, timeout time.Duration) *http.Client {
+	if scheme == "http" {
+		return &http.Client{
+			Timeout: timeout,
+			Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+					return net.DialTimeout(network, addr, timeout)
+				},
+			},
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.ForceAttemptHTTP2 = true
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// load queues the batches of the buffer left by a previous run.
+func (p *Pusher) load() error {
+	err := os.MkdirAll(p.params.BufferPath, 0700)
+	if err != nil {
+		return err
+	}
+
+	files, err := ioutil.ReadDir(p.params.BufferPath)
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	})
+
+	for _, file := range files {
+		name := file.Name()
+		switch {
+		case strings.HasSuffix(name, tmpSuffix):
+			// A batch whose write was interrupted.
+			os.Remove(filepath.Join(p.params.BufferPath, name))
+		case strings.HasSuffix(name, batchSuffix) && strings.Contains(name, "-"):
+			p.batches = append(p.batches, spooledBatch{name: name, size: file.Size()})
+			p.bytes += file.Size()
+			seq, err := strconv.ParseInt(name[:strings.Index(name, "-")], 10, 64)
+			if err == nil && seq > p.seq {
+				p.seq = seq
+			}
+		}
+	}
+	if len(p.batches) > 0 {
+		log.Printf("push: resuming %d buffered batches", len(p.batches))
+	}
+
+	return nil
+}
+
+// WritePoints writes the points to the buffer in batches of BatchSize. It
+// does not wait for them to be pushed.
+func (p *Pusher) WritePoints(ctx context.Context, points []*write.Point) error {
+	for start := 0; start < len(points); start += p.params.BatchSize {
+		end := start + p.params.BatchSize
+		if end > len(points) {
+			end = len(points)
+		}
+
+		body, n, err := encodeBatch(points[start:end])
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			continue
+		}
+		err = p.spool(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// encodeBatch returns the points as gzip compressed line protocol and the
+// number of points encoded. Points without a valid field are left out.
+func encodeBatch(points []*write.Point) ([]byte, int, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := lineprotocol.NewEncoder(gz)
+	encoder.SetFieldTypeSupport(lineprotocol.UintSupport)
+
+	n := 0
+	for _, point := range points {
+		_, err := encoder.Encode(point)
+		if err == nil {
+			n++
+		}
+	}
+	err := gz.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return buf.Bytes(), n, nil
+}
+
+// spool writes a batch to the buffer under a new id, dropping the oldest
+// batches when the buffer is full.
+func (p *Pusher) spool(body []byte) error {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	if now := time.Now().UnixNano(); now > p.seq {
+		p.seq = now
+	}
+	name := fmt.Sprintf("%020d-%s%s", p.seq, hex.EncodeToString(id), batchSuffix)
+
+	path := filepath.Join(p.params.BufferPath, name)
+	err = ioutil.WriteFile(path+tmpSuffix, body, 0600)
+	if err != nil {
+		os.Remove(path + tmpSuffix)
+		return err
+	}
+	err = os.Rename(path+tmpSuffix, path)
+	if err != nil {
+		return err
+	}
+
+	p.batches = append(p.batches, spooledBatch{name: name, size: int64(len(body))})
+	p.bytes += int64(len(body))
+
+	for p.bytes > p.params.MaxBufferBytes && len(p.batches) > 1 {
+		oldest := p.batches[0]
+		log.Printf("push: buffer is full, dropping batch %s", oldest.id())
+		p.removeLocked(oldest)
+	}
+	return nil
+}
+
+func (p *Pusher) oldest() (spooledBatch, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.batches) == 0 {
+		return spooledBatch{}, false
+	}
+	return p.batches[0], true
+}
+
+func (p *Pusher) remove(batch spooledBatch) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.removeLocked(batch)
+}
+
+// removeLocked deletes a batch from the buffer unless it was dropped while
+// it was being pushed.
+func (p *Pusher) removeLocked(batch spooledBatch) {
+	for i, b := range p.batches {
+		if b.name != batch.name {
+			continue
+		}
+		p.batches = append(p.batches[:i], p.batches[i+1:]...)
+		p.bytes -= b.size
+		err := os.Remove(filepath.Join(p.params.BufferPath, b.name))
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("push: failed to remove batch %s: %v", b.id(), err)
+		}
+		return
+	}
+}
+
+// Close stops the background worker. Batches not pushed yet stay in the
+// buffer for the next run.
+func (p *Pusher) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+	})
+	p.wg.Wait()
+	return nil
+}
+
+func (p *Pusher) run() {
+	defer p.wg.Done()
+
+	backoff := p.params.MinBackoff
+	for {
+		batch, ok := p.oldest()
+		if !ok {
+			select {
+			case <-p.wake:
+				continue
+			case <-p.done:
+				return
+			}
+		}
+
+		retryAfter, err := p.push(batch)
+		if err == nil {
+			p.remove(batch)
+			backoff = p.params.MinBackoff
+			continue
+		}
+		var rejected *rejectedError
+		if errors.As(err, &rejected) {
+			log.Printf("push: dropping batch %s: %v", batch.id(), err)
+			p.remove(batch)
+			continue
+		}
+
+		wait := backoff
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		log.Printf("push: failed to push batch %s, retrying in %v: %v", batch.id(), wait, err)
+		select {
+		case <-time.After(wait):
+		case <-p.done:
+			return
+		}
+		backoff *= 2
+		if backoff > p.params.MaxBackoff {
+			backoff = p.params.MaxBackoff
+		}
+	}
+}
+
+// rejectedError is returned for batches the central stalker will never
+// accept.
+type rejectedError struct {
+	err error
+}
+
+func (e *rejectedError) Error() string {
+	return e.err.Error()
+}
+
+// push sends a batch. It returns how long the central stalker asked to wait
+// before a retry, if it did.
+func (p *Pusher) push(batch spooledBatch) (time.Duration, error) {
+	f, err := os.Open(filepath.Join(p.params.BufferPath, batch.name))
+	if os.IsNotExist(err) {
+		// Dropped while the buffer was full.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, &rejectedError{err: err}
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.params.Timeout)
+	defer cancel()
+
+	resp, err := p.client.PostPushWithBody(ctx, &client.PostPushParams{Batch: batch.id()}, "text/plain; charset=utf-8", f,
+		func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("Content-Encoding", "gzip")
+			req.Header.Set("User-Agent", "stalker")
+			req.ContentLength = batch.size
+			return nil
+		})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode/100 == 2 {
+		return 0, nil
+	}
+
+	err = client.CheckResponse(resp.Status, body, false)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(seconds) * time.Second, err
+	// Agents may be added to the central stalker while the batch is retried,
+	// and a batch whose answer was lost may still be being stored.
+	case resp.StatusCode/100 == 5, resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusConflict:
+		return 0, err
+	}
+	return 0, &rejectedError{err: err}
+}
@@ -0,0 +1,265 @@
+package push
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// central is a central stalker storing the batches pushed to it, answering
+// its first pushes with the codes of answers.
+type central struct {
+	*httptest.Server
+	receiver *Receiver
+	answers  []int
+
+	mu       sync.Mutex
+	pushes   int
+	attempts map[string][]time.Time
+	stored   map[string][]string
+}
+
+func startCentral(t *testing.T, answers ...int) *central {
+	t.Helper()
+	c := &central{
+		receiver: NewReceiver(),
+		answers:  answers,
+		attempts: map[string][]time.Time{},
+		stored:   map[string][]string{},
+	}
+	c.Server = httptest.NewServer(h2c.NewHandler(http.HandlerFunc(c.push), &http2.Server{}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *central) push(w http.ResponseWriter, r *http.Request) {
+	batch := r.URL.Query().Get("batch")
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.attempts[batch] = append(c.attempts[batch], time.Now())
+	c.pushes++
+	pushes := c.pushes
+	c.mu.Unlock()
+
+	agent := AgentConfig{Name: "node-1"}
+	if pushes <= len(c.answers) && c.answers[pushes-1] != http.StatusOK {
+		code := c.answers[pushes-1]
+		if code == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		// A batch whose answer is lost is stored nonetheless.
+		if code == http.StatusBadGateway && c.receiver.Admit(agent, batch, 1) == nil {
+			c.store(batch, string(body))
+			c.receiver.Accepted(agent.Name, batch)
+		}
+		w.WriteHeader(code)
+		return
+	}
+
+	err = c.receiver.Admit(agent, batch, 1)
+	if err == ErrDuplicate {
+		w.Write([]byte(`{"duplicate": true}`))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	c.store(batch, string(body))
+	c.receiver.Accepted(agent.Name, batch)
+	w.Write([]byte(`{"points": 1}`))
+}
+
+func (c *central) store(batch, body string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stored[batch] = append(c.stored[batch], body)
+}
+
+func (c *central) storedBatches() map[string][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stored := map[string][]string{}
+	for batch, bodies := range c.stored {
+		stored[batch] = append([]string{}, bodies...)
+	}
+	return stored
+}
+
+func (c *central) attemptsOf(batch string) []time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Time{}, c.attempts[batch]...)
+}
+
+func newTestPusher(t *testing.T, url, dir string) *Pusher {
+	t.Helper()
+	p, err := NewPusher(PusherParams{
+		Url:        url,
+		BufferPath: dir,
+		BatchSize:  2,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+		Timeout:    time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func testPoints(values ...float64) []*write.Point {
+	points := []*write.Point{}
+	for i, value := range values {
+		points = append(points, write.NewPoint("memory_usage", map[string]string{"container_name": "/web"}, map[string]interface{}{"value": value}, time.Unix(1600000000+int64(i), 0)))
+	}
+	return points
+}
+
+// bufferedBatches returns the ids of the batches in the buffer, oldest
+// first.
+func bufferedBatches(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), batchSuffix) {
+			ids = append(ids, spooledBatch{name: file.Name()}.id())
+		}
+	}
+	return ids
+}
+
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPusherResumesBuffer(t *testing.T) {
+	dir := t.TempDir()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	p := newTestPusher(t, down.URL, dir)
+	err := p.WritePoints(context.Background(), testPoints(1, 2, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+
+	ids := bufferedBatches(t, dir)
+	if len(ids) != 2 {
+		t.Fatalf("got %d batches in the buffer, want 2", len(ids))
+	}
+	// Batches whose write was interrupted are left out.
+	err = os.WriteFile(filepath.Join(dir, fmt.Sprintf("%020d-interrupted%s%s", 1, batchSuffix, tmpSuffix)), []byte("x"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := startCentral(t)
+	newTestPusher(t, c.URL, dir)
+	waitFor(t, "the buffer to be pushed", func() bool { return len(bufferedBatches(t, dir)) == 0 })
+
+	stored := c.storedBatches()
+	if len(stored) != 2 {
+		t.Fatalf("stored %d batches, want 2", len(stored))
+	}
+	for _, id := range ids {
+		if len(stored[id]) != 1 {
+			t.Errorf("batch %s stored %d times, want once with the id it was spooled with", id, len(stored[id]))
+		}
+	}
+	if !strings.Contains(stored[ids[0]][0], "value=1") || !strings.Contains(stored[ids[1]][0], "value=3") {
+		t.Errorf("got batches %v, want the oldest pushed first", stored)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("got files %v left in the buffer", files)
+	}
+}
+
+func TestPusherAnswers(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   int
+		attempts int
+		stored   int
+		wait     time.Duration
+	}{
+		{name: "stored", answer: http.StatusOK, attempts: 1, stored: 1},
+		{name: "answer lost", answer: http.StatusBadGateway, attempts: 2, stored: 1},
+		{name: "unavailable", answer: http.StatusServiceUnavailable, attempts: 2, stored: 1},
+		{name: "being stored", answer: http.StatusConflict, attempts: 2, stored: 1},
+		{name: "quota exceeded", answer: http.StatusTooManyRequests, attempts: 2, stored: 1, wait: time.Second},
+		{name: "larger than the quota", answer: http.StatusRequestEntityTooLarge, attempts: 1, stored: 0},
+		{name: "invalid", answer: http.StatusBadRequest, attempts: 1, stored: 0},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		c := startCentral(t, tt.answer)
+		p := newTestPusher(t, c.URL, dir)
+		err := p.WritePoints(context.Background(), testPoints(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := bufferedBatches(t, dir)
+		waitFor(t, tt.name, func() bool { return len(bufferedBatches(t, dir)) == 0 })
+
+		// A later batch is pushed once the first is done with.
+		err = p.WritePoints(context.Background(), testPoints(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, tt.name+" then the next batch", func() bool { return len(c.storedBatches()) == tt.stored+1 })
+
+		attempts := c.attemptsOf(ids[0])
+		stored := c.storedBatches()[ids[0]]
+		if len(attempts) != tt.attempts || len(stored) != tt.stored {
+			t.Errorf("%s: got %d attempts storing the batch %d times, want %d attempts and %d times", tt.name, len(attempts), len(stored), tt.attempts, tt.stored)
+			continue
+		}
+		if len(attempts) == 2 {
+			if wait := attempts[1].Sub(attempts[0]); wait < tt.wait {
+				t.Errorf("%s: retried after %v, want %v", tt.name, wait, tt.wait)
+			}
+		}
+	}
+}
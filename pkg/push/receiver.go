@@ -0,0 +1,164 @@
+package push
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// recentBatches is the number of batch ids remembered per agent to detect
+// retried batches.
+const recentBatches = 1024
+
+// ErrDuplicate is returned for batches accepted before.
+var ErrDuplicate = errors.New("batch was accepted before")
+
+// ErrPending is returned for batches admitted but neither accepted nor
+// released yet, which another push of the agent is storing.
+var ErrPending = errors.New("batch is being stored")
+
+// QuotaError is returned for batches exceeding the quota of an agent.
+type QuotaError struct {
+	Agent string
+	// RetryAfter is when the quota admits the batch, zero when it never does
+	// because the batch holds more than a minute worth of points.
+	RetryAfter time.Duration
+}
+
+func (e *QuotaError) Error() string {
+	if e.RetryAfter == 0 {
+		return fmt.Sprintf("batch exceeds the quota of agent %s", e.Agent)
+	}
+	return fmt.Sprintf("agent %s exceeded its quota, retry after %v", e.Agent, e.RetryAfter.Round(time.Second))
+}
+
+// Receiver enforces the quotas of the agents and recognizes the batches they
+// retry. It remembers batches in memory only, batches retried across a
+// restart are stored again, which overwrites the same points.
+type Receiver struct {
+	mu     sync.Mutex
+	agents map[string]*agentState
+}
+
+type agentState struct {
+	pointsPerMinute int
+	// tokens is the number of points the agent may push now, refilled at
+	// pointsPerMinute up to a minute worth of points.
+	tokens float64
+	filled time.Time
+
+	recent []string
+	next   int
+	seen   map[string]bool
+	// pending holds the batches admitted until they are accepted or
+	// released.
+	pending map[string]bool
+}
+
+// NewReceiver creates a receiver.
+func NewReceiver() *Receiver {
+	return &Receiver{agents: map[string]*agentState{}}
+}
+
+func (r *Receiver) state(agent AgentConfig, now time.Time) *agentState {
+	state, ok := r.agents[agent.Name]
+	if !ok {
+		state = &agentState{
+			recent:  make([]string, recentBatches),
+			seen:    map[string]bool{},
+			pending: map[string]bool{},
+		}
+		r.agents[agent.Name] = state
+	}
+	// New agents and agents whose quota was reloaded start with a full quota.
+	if !ok || state.pointsPerMinute != agent.PointsPerMinute {
+		state.pointsPerMinute = agent.PointsPerMinute
+		state.tokens = float64(agent.PointsPerMinute)
+		state.filled = now
+	}
+	return state
+}
+
+// Admit reserves the quota of a batch of points pushed by agent, which must
+// then be either accepted or released. It returns ErrDuplicate for batches
+// accepted before, ErrPending for batches admitted and not accepted or
+// released yet and a *QuotaError when the agent exceeded its quota.
+func (r *Receiver) Admit(agent AgentConfig, batch string, points int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	state := r.state(agent, now)
+	if state.seen[batch] {
+		return ErrDuplicate
+	}
+	if state.pending[batch] {
+		return ErrPending
+	}
+	if state.pointsPerMinute == 0 {
+		state.pending[batch] = true
+		return nil
+	}
+	if points > state.pointsPerMinute {
+		return &QuotaError{Agent: agent.Name}
+	}
+
+	perSecond := float64(state.pointsPerMinute) / 60
+	state.tokens += now.Sub(state.filled).Seconds() * perSecond
+	if state.tokens > float64(state.pointsPerMinute) {
+		state.tokens = float64(state.pointsPerMinute)
+	}
+	state.filled = now
+
+	missing := float64(points) - state.tokens
+	if missing > 0 {
+		return &QuotaError{
+			Agent:      agent.Name,
+			RetryAfter: time.Duration(missing / perSecond * float64(time.Second)),
+		}
+	}
+	state.tokens -= float64(points)
+	state.pending[batch] = true
+	return nil
+}
+
+// Release returns the quota reserved for a batch that failed to be stored,
+// which may be pushed again.
+func (r *Receiver) Release(agent, batch string, points int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.agents[agent]
+	if !ok {
+		return
+	}
+	delete(state.pending, batch)
+	if state.pointsPerMinute == 0 {
+		return
+	}
+	state.tokens += float64(points)
+	if state.tokens > float64(state.pointsPerMinute) {
+		state.tokens = float64(state.pointsPerMinute)
+	}
+}
+
+// Accepted records a stored batch, which is a duplicate when it is pushed
+// again.
+func (r *Receiver) Accepted(agent, batch string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.agents[agent]
+	if !ok {
+		return
+	}
+	delete(state.pending, batch)
+	if state.seen[batch] {
+		return
+	}
+	delete(state.seen, state.recent[state.next])
+	state.recent[state.next] = batch
+	state.next = (state.next + 1) % len(state.recent)
+	state.seen[batch] = true
+}
@@ -0,0 +1,129 @@
+package push
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReceiverDuplicates(t *testing.T) {
+	r := NewReceiver()
+	agent := AgentConfig{Name: "node-1"}
+
+	steps := []struct {
+		name  string
+		batch string
+		do    func()
+		want  error
+	}{
+		{name: "new batch", batch: "a", want: nil},
+		{name: "batch being stored", batch: "a", want: ErrPending},
+		{name: "accepted batch", batch: "a", do: func() { r.Accepted(agent.Name, "a") }, want: ErrDuplicate},
+		{name: "other batch", batch: "b", want: nil},
+		{name: "released batch", batch: "b", do: func() { r.Release(agent.Name, "b", 1) }, want: nil},
+		{name: "batch of another agent", batch: "a", do: func() { agent = AgentConfig{Name: "node-2"} }, want: nil},
+	}
+	for _, step := range steps {
+		if step.do != nil {
+			step.do()
+		}
+		if err := r.Admit(agent, step.batch, 1); err != step.want {
+			t.Errorf("%s: got %v, want %v", step.name, err, step.want)
+		}
+	}
+
+	// Only the recent batches are remembered.
+	agent = AgentConfig{Name: "node-3"}
+	for i := 0; i <= recentBatches; i++ {
+		batch := fmt.Sprint(i)
+		if err := r.Admit(agent, batch, 1); err != nil {
+			t.Fatal(err)
+		}
+		r.Accepted(agent.Name, batch)
+	}
+	if err := r.Admit(agent, "0", 1); err != nil {
+		t.Errorf("got %v for a batch accepted %d batches ago", err, recentBatches+1)
+	}
+	if err := r.Admit(agent, "1", 1); err != ErrDuplicate {
+		t.Errorf("got %v for a recent batch, want ErrDuplicate", err)
+	}
+}
+
+func TestReceiverConcurrentAdmit(t *testing.T) {
+	r := NewReceiver()
+	agent := AgentConfig{Name: "node-1", PointsPerMinute: 1000}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- r.Admit(agent, "a", 10)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	admitted := 0
+	for err := range errs {
+		switch err {
+		case nil:
+			admitted++
+		case ErrPending:
+		default:
+			t.Errorf("got %v, want nil or ErrPending", err)
+		}
+	}
+	if admitted != 1 {
+		t.Errorf("admitted the batch %d times, want once", admitted)
+	}
+	// Only the admitted push reserved its quota.
+	if tokens := r.agents[agent.Name].tokens; tokens < 990 || tokens > 991 {
+		t.Errorf("got %v tokens left, want 990", tokens)
+	}
+}
+
+func TestReceiverQuota(t *testing.T) {
+	r := NewReceiver()
+	agent := AgentConfig{Name: "node-1", PointsPerMinute: 60}
+
+	var quota *QuotaError
+	err := r.Admit(agent, "too large", 61)
+	if !errors.As(err, &quota) || quota.RetryAfter != 0 {
+		t.Errorf("got %v for a batch beyond a minute worth of points, want a QuotaError without retry", err)
+	}
+
+	if err := r.Admit(agent, "a", 60); err != nil {
+		t.Fatal(err)
+	}
+	r.Accepted(agent.Name, "a")
+
+	// The quota refills at a point per second.
+	err = r.Admit(agent, "b", 30)
+	if !errors.As(err, &quota) || quota.RetryAfter < 29*time.Second || quota.RetryAfter > 30*time.Second {
+		t.Errorf("got %v, want a retry after 30s", err)
+	}
+	// A rejected batch is not pending.
+	err = r.Admit(agent, "b", 30)
+	if !errors.As(err, &quota) {
+		t.Errorf("got %v for the retried batch, want a QuotaError", err)
+	}
+
+	// A released batch returns its quota.
+	r.agents[agent.Name].tokens = 30
+	if err := r.Admit(agent, "b", 30); err != nil {
+		t.Fatal(err)
+	}
+	r.Release(agent.Name, "b", 30)
+	if err := r.Admit(agent, "b", 30); err != nil {
+		t.Errorf("got %v after the release, want the quota returned", err)
+	}
+
+	// A reloaded quota starts full.
+	if err := r.Admit(AgentConfig{Name: "node-1", PointsPerMinute: 120}, "d", 120); err != nil {
+		t.Errorf("got %v after the quota was reloaded", err)
+	}
+}
@@ -0,0 +1,115 @@
+package remotewrite
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/influx"
+	"github.com/zawachte/stalker/pkg/prometheus"
+)
+
+const metricNameLabel = "__name__"
+
+// TimeSeriesToPoints converts remote_write series into points. The metric name
+// becomes the measurement and every other label becomes a tag. Series without
+// a metric name are dropped, as are NaN and infinite samples such as the
+// stale markers of Prometheus, which the database does not store.
+func TimeSeriesToPoints(series []TimeSeries) []*write.Point {
+	points := []*write.Point{}
+	for _, ts := range series {
+		var name string
+		tags := map[string]string{}
+		for _, label := range ts.Labels {
+			if label.Name == metricNameLabel {
+				name = label.Value
+				continue
+			}
+			tags[label.Name] = label.Value
+		}
+		if name == "" {
+			continue
+		}
+
+		for _, sample := range ts.Samples {
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			fields := map[string]interface{}{
+				influx.ValueField: sample.Value,
+			}
+			points = append(points, write.NewPoint(name, tags, fields, time.Unix(0, sample.Timestamp*int64(time.Millisecond))))
+		}
+	}
+
+	return points
+}
+
+// PointsToTimeSeries converts points into remote_write series. Samples of the
+// same series are merged in timestamp order.
+func PointsToTimeSeries(points []*write.Point) []TimeSeries {
+	index := map[string]int{}
+	series := []TimeSeries{}
+
+	for _, point := range points {
+		for _, field := range point.FieldList() {
+			value, ok := influx.ToFloat(field.Value)
+			if !ok {
+				continue
+			}
+
+			name := point.Name()
+			if field.Key != influx.ValueField {
+				name = name + "_" + field.Key
+			}
+
+			labels := []Label{{Name: metricNameLabel, Value: prometheus.SanitizeMetricName(name)}}
+			seen := map[string]bool{metricNameLabel: true}
+			for _, tag := range point.TagList() {
+				labelName := prometheus.SanitizeLabelName(tag.Key)
+				if seen[labelName] {
+					continue
+				}
+				seen[labelName] = true
+				labels = append(labels, Label{Name: labelName, Value: tag.Value})
+			}
+			sort.Slice(labels, func(i, j int) bool {
+				return labels[i].Name < labels[j].Name
+			})
+
+			sample := Sample{
+				Value:     value,
+				Timestamp: point.Time().UnixNano() / int64(time.Millisecond),
+			}
+
+			key := seriesKey(labels)
+			if i, ok := index[key]; ok {
+				series[i].Samples = append(series[i].Samples, sample)
+				continue
+			}
+			index[key] = len(series)
+			series = append(series, TimeSeries{Labels: labels, Samples: []Sample{sample}})
+		}
+	}
+
+	for i := range series {
+		samples := series[i].Samples
+		sort.SliceStable(samples, func(a, b int) bool {
+			return samples[a].Timestamp < samples[b].Timestamp
+		})
+	}
+
+	return series
+}
+
+func seriesKey(labels []Label) string {
+	key := []byte{}
+	for _, label := range labels {
+		key = append(key, label.Name...)
+		key = append(key, 0xff)
+		key = append(key, label.Value...)
+		key = append(key, 0xff)
+	}
+	return string(key)
+}
@@ -0,0 +1,56 @@
+package remotewrite
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReceiveNonFiniteSamples(t *testing.T) {
+	body := EncodeWriteRequest([]TimeSeries{{
+		Labels: []Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+		Samples: []Sample{
+			{Value: 1, Timestamp: 1000},
+			{Value: math.Float64frombits(0x7ff0000000000002), Timestamp: 2000}, // stale marker
+			{Value: math.Inf(1), Timestamp: 3000},
+			{Value: 0, Timestamp: 4000},
+		},
+	}})
+
+	series, err := DecodeWriteRequest(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 || len(series[0].Samples) != 4 {
+		t.Fatalf("decoded %+v, want one series of 4 samples", series)
+	}
+
+	points := TimeSeriesToPoints(series)
+	if len(points) != 2 {
+		t.Fatalf("got %d points, want the 2 finite samples", len(points))
+	}
+	for i, want := range []int64{1000, 4000} {
+		if got := points[i].Time().UnixNano() / int64(time.Millisecond); got != want {
+			t.Errorf("point %d at %d, want %d", i, got, want)
+		}
+		if points[i].Name() != "up" || points[i].TagList()[0].Value != "node" {
+			t.Errorf("point %d = %s %v, want up tagged job=node", i, points[i].Name(), points[i].TagList())
+		}
+	}
+}
+
+func TestDecodeTooLarge(t *testing.T) {
+	// A varint length claiming more than MaxDecodedBytes, without the data.
+	claim := uint64(MaxDecodedBytes + 1)
+	body := []byte{}
+	for claim >= 0x80 {
+		body = append(body, byte(claim)|0x80)
+		claim >>= 7
+	}
+	body = append(body, byte(claim))
+
+	_, err := DecodeWriteRequest(body)
+	if err != ErrTooLarge {
+		t.Errorf("got %v, want ErrTooLarge", err)
+	}
+}
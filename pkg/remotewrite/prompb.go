@@ -0,0 +1,216 @@
+package remotewrite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Label is a single name/value pair identifying a time series.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a single value of a time series at a millisecond timestamp.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// TimeSeries is a labelled list of samples, as carried by a remote_write request.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Field numbers of the prometheus.WriteRequest protobuf message and its children.
+const (
+	fieldWriteRequestTimeseries = 1
+
+	fieldTimeSeriesLabels  = 1
+	fieldTimeSeriesSamples = 2
+
+	fieldLabelName  = 1
+	fieldLabelValue = 2
+
+	fieldSampleValue     = 1
+	fieldSampleTimestamp = 2
+)
+
+// MaxDecodedBytes bounds the decompressed size of a remote_write request.
+const MaxDecodedBytes = 64 << 20
+
+var errMalformed = errors.New("malformed remote_write request")
+
+// ErrTooLarge is returned for requests decompressing to more than
+// MaxDecodedBytes.
+var ErrTooLarge = fmt.Errorf("remote_write requests are limited to %d bytes decompressed", MaxDecodedBytes)
+
+// DecodeWriteRequest decodes a snappy-compressed prometheus.WriteRequest.
+// Metadata and exemplars are ignored.
+func DecodeWriteRequest(compressed []byte) ([]TimeSeries, error) {
+	n, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, err
+	}
+	if n > MaxDecodedBytes {
+		return nil, ErrTooLarge
+	}
+
+	body, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	series := []TimeSeries{}
+	err = walkMessage(body, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != fieldWriteRequestTimeseries || typ != protowire.BytesType {
+			return nil
+		}
+		ts, err := decodeTimeSeries(value)
+		if err != nil {
+			return err
+		}
+		series = append(series, ts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// EncodeWriteRequest encodes series as a snappy-compressed prometheus.WriteRequest.
+func EncodeWriteRequest(series []TimeSeries) []byte {
+	var body []byte
+	for _, ts := range series {
+		body = protowire.AppendTag(body, fieldWriteRequestTimeseries, protowire.BytesType)
+		body = protowire.AppendBytes(body, encodeTimeSeries(ts))
+	}
+
+	return snappy.Encode(nil, body)
+}
+
+func encodeTimeSeries(ts TimeSeries) []byte {
+	var b []byte
+	for _, label := range ts.Labels {
+		var l []byte
+		l = protowire.AppendTag(l, fieldLabelName, protowire.BytesType)
+		l = protowire.AppendString(l, label.Name)
+		l = protowire.AppendTag(l, fieldLabelValue, protowire.BytesType)
+		l = protowire.AppendString(l, label.Value)
+
+		b = protowire.AppendTag(b, fieldTimeSeriesLabels, protowire.BytesType)
+		b = protowire.AppendBytes(b, l)
+	}
+
+	for _, sample := range ts.Samples {
+		var s []byte
+		s = protowire.AppendTag(s, fieldSampleValue, protowire.Fixed64Type)
+		s = protowire.AppendFixed64(s, math.Float64bits(sample.Value))
+		s = protowire.AppendTag(s, fieldSampleTimestamp, protowire.VarintType)
+		s = protowire.AppendVarint(s, uint64(sample.Timestamp))
+
+		b = protowire.AppendTag(b, fieldTimeSeriesSamples, protowire.BytesType)
+		b = protowire.AppendBytes(b, s)
+	}
+
+	return b
+}
+
+func decodeTimeSeries(b []byte) (TimeSeries, error) {
+	ts := TimeSeries{}
+	err := walkMessage(b, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+
+		switch num {
+		case fieldTimeSeriesLabels:
+			label := Label{}
+			err := walkMessage(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				if typ != protowire.BytesType {
+					return nil
+				}
+				switch num {
+				case fieldLabelName:
+					label.Name = string(value)
+				case fieldLabelValue:
+					label.Value = string(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, label)
+		case fieldTimeSeriesSamples:
+			sample := Sample{}
+			err := walkMessage(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				switch {
+				case num == fieldSampleValue && typ == protowire.Fixed64Type:
+					v, n := protowire.ConsumeFixed64(value)
+					if n < 0 {
+						return errMalformed
+					}
+					sample.Value = math.Float64frombits(v)
+				case num == fieldSampleTimestamp && typ == protowire.VarintType:
+					v, n := protowire.ConsumeVarint(value)
+					if n < 0 {
+						return errMalformed
+					}
+					sample.Timestamp = int64(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, sample)
+		}
+		return nil
+	})
+
+	return ts, err
+}
+
+// walkMessage calls fn for every field of an encoded protobuf message. For
+// length-delimited fields value holds the payload, for all other wire types it
+// holds the raw encoded value.
+func walkMessage(b []byte, fn func(protowire.Number, protowire.Type, []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errMalformed
+		}
+		b = b[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			v, m := protowire.ConsumeBytes(b)
+			if m < 0 {
+				return errMalformed
+			}
+			value, n = v, m
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return errMalformed
+			}
+			value = b[:n]
+		}
+		b = b[n:]
+
+		err := fn(num, typ, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
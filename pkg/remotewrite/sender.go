@@ -0,0 +1,208 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+const (
+	defaultQueueCapacity     = 100
+	defaultMaxSamplesPerSend = 500
+	defaultMaxRetries        = 5
+	defaultMinBackoff        = 100 * time.Millisecond
+	defaultMaxBackoff        = 10 * time.Second
+	defaultTimeout           = 30 * time.Second
+)
+
+// ErrQueueFull is returned when points are dropped because the send queue is full.
+var ErrQueueFull = errors.New("remote_write queue is full")
+
+type SenderParams struct {
+	// Url of the remote_write receiver, e.g. http://prometheus:9090/api/v1/write.
+	Url         string
+	BearerToken string
+	// Number of batches buffered before new points are dropped.
+	QueueCapacity     int
+	MaxSamplesPerSend int
+	// Number of retries for a batch that failed with a recoverable error.
+	// Zero uses the default, a negative value disables retries.
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Timeout    time.Duration
+	Client     *http.Client
+}
+
+// Sender forwards points to a Prometheus remote_write endpoint. Points are
+// queued in batches and sent by a single background worker that retries
+// recoverable failures with exponential backoff.
+type Sender struct {
+	params SenderParams
+	queue  chan []TimeSeries
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewSender creates a sender and starts its background worker.
+func NewSender(params SenderParams) (*Sender, error) {
+	u, err := url.Parse(params.Url)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("remote_write url %q must be http or https", params.Url)
+	}
+
+	if params.QueueCapacity <= 0 {
+		params.QueueCapacity = defaultQueueCapacity
+	}
+	if params.MaxSamplesPerSend <= 0 {
+		params.MaxSamplesPerSend = defaultMaxSamplesPerSend
+	}
+	if params.MaxRetries < 0 {
+		params.MaxRetries = 0
+	} else if params.MaxRetries == 0 {
+		params.MaxRetries = defaultMaxRetries
+	}
+	if params.MinBackoff <= 0 {
+		params.MinBackoff = defaultMinBackoff
+	}
+	if params.MaxBackoff <= 0 {
+		params.MaxBackoff = defaultMaxBackoff
+	}
+	if params.Timeout <= 0 {
+		params.Timeout = defaultTimeout
+	}
+	if params.Client == nil {
+		params.Client = &http.Client{Timeout: params.Timeout}
+	}
+
+	s := &Sender{
+		params: params,
+		queue:  make(chan []TimeSeries, params.QueueCapacity),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+// WritePoints queues points for sending. It never blocks; when the queue is
+// full the remaining batches are dropped and ErrQueueFull is returned.
+func (s *Sender) WritePoints(ctx context.Context, points []*write.Point) error {
+	for _, batch := range s.batches(PointsToTimeSeries(points)) {
+		select {
+		case s.queue <- batch:
+		default:
+			return ErrQueueFull
+		}
+	}
+
+	return nil
+}
+
+// Close stops accepting points and waits for the queued batches to be sent.
+func (s *Sender) Close() error {
+	s.once.Do(func() {
+		close(s.queue)
+	})
+	s.wg.Wait()
+	return nil
+}
+
+// batches splits series so no request carries more than MaxSamplesPerSend samples.
+func (s *Sender) batches(series []TimeSeries) [][]TimeSeries {
+	batches := [][]TimeSeries{}
+	batch := []TimeSeries{}
+	samples := 0
+	for _, ts := range series {
+		if samples > 0 && samples+len(ts.Samples) > s.params.MaxSamplesPerSend {
+			batches = append(batches, batch)
+			batch = []TimeSeries{}
+			samples = 0
+		}
+		batch = append(batch, ts)
+		samples += len(ts.Samples)
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+func (s *Sender) run() {
+	defer s.wg.Done()
+
+	for batch := range s.queue {
+		err := s.sendWithRetries(EncodeWriteRequest(batch))
+		if err != nil {
+			log.Printf("remote_write: dropping %d series: %v", len(batch), err)
+		}
+	}
+}
+
+func (s *Sender) sendWithRetries(body []byte) error {
+	backoff := s.params.MinBackoff
+
+	var err error
+	for attempt := 0; attempt <= s.params.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > s.params.MaxBackoff {
+				backoff = s.params.MaxBackoff
+			}
+		}
+
+		var recoverable bool
+		recoverable, err = s.send(body)
+		if err == nil || !recoverable {
+			return err
+		}
+	}
+
+	return err
+}
+
+// send posts a single encoded request. It reports whether a failure is worth retrying.
+func (s *Sender) send(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.params.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "stalker")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if s.params.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.params.BearerToken)
+	}
+
+	resp, err := s.params.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("remote_write server returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	recoverable := resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+	return recoverable, err
+}
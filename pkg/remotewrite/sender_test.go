@@ -0,0 +1,181 @@
+package remotewrite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// receiver is a stand-in remote_write receiver answering with the statuses
+// of codes in turn, then with 204.
+type receiver struct {
+	mu       sync.Mutex
+	codes    []int
+	requests []*http.Request
+	series   [][]TimeSeries
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	series, err := DecodeWriteRequest(body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.series = append(rc.series, series)
+	code := http.StatusNoContent
+	if len(rc.codes) > 0 {
+		code, rc.codes = rc.codes[0], rc.codes[1:]
+	}
+	w.WriteHeader(code)
+}
+
+func newTestSender(t *testing.T, url string, params SenderParams) *Sender {
+	t.Helper()
+	params.Url = url
+	params.MinBackoff = time.Millisecond
+	params.MaxBackoff = time.Millisecond
+	s, err := NewSender(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testPoints(n int) []*write.Point {
+	points := []*write.Point{}
+	start := time.Unix(1600000000, 0)
+	for i := 0; i < n; i++ {
+		points = append(points, write.NewPoint("cpu_usage_total",
+			map[string]string{"container_name": "/app"},
+			map[string]interface{}{"value": float64(i)},
+			start.Add(time.Duration(i)*time.Second)))
+	}
+	return points
+}
+
+func TestSenderEncodesAndBatches(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	s := newTestSender(t, srv.URL, SenderParams{BearerToken: "secret", MaxSamplesPerSend: 2})
+	points := testPoints(3)
+	points = append(points, write.NewPoint("memory_usage",
+		map[string]string{"container_name": "/app"},
+		map[string]interface{}{"value": 42.0},
+		time.Unix(1600000000, 0)))
+	err := s.WritePoints(context.Background(), points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	if len(rc.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(rc.requests))
+	}
+	req := rc.requests[0]
+	for header, want := range map[string]string{
+		"Content-Encoding":                  "snappy",
+		"Content-Type":                      "application/x-protobuf",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+		"Authorization":                     "Bearer secret",
+	} {
+		if got := req.Header.Get(header); got != want {
+			t.Errorf("header %s = %q, want %q", header, got, want)
+		}
+	}
+
+	first := rc.series[0]
+	if len(first) != 1 || len(first[0].Samples) != 3 {
+		t.Fatalf("first request = %+v, want one series of 3 samples", first)
+	}
+	wantLabels := []Label{{Name: "__name__", Value: "cpu_usage_total"}, {Name: "container_name", Value: "/app"}}
+	if len(first[0].Labels) != len(wantLabels) {
+		t.Fatalf("labels = %+v, want %+v", first[0].Labels, wantLabels)
+	}
+	for i, label := range wantLabels {
+		if first[0].Labels[i] != label {
+			t.Errorf("label %d = %+v, want %+v", i, first[0].Labels[i], label)
+		}
+	}
+	for i, sample := range first[0].Samples {
+		want := Sample{Value: float64(i), Timestamp: 1600000000000 + int64(i)*1000}
+		if sample != want {
+			t.Errorf("sample %d = %+v, want %+v", i, sample, want)
+		}
+	}
+	if second := rc.series[1]; len(second) != 1 || second[0].Labels[0].Value != "memory_usage" {
+		t.Errorf("second request = %+v, want the memory_usage series", second)
+	}
+}
+
+func TestSenderRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []int
+		requests int
+	}{
+		{name: "server error", codes: []int{500, 503}, requests: 3},
+		{name: "rate limited", codes: []int{429}, requests: 2},
+		{name: "client error", codes: []int{400}, requests: 1},
+		{name: "retries exhausted", codes: []int{500, 500, 500, 500}, requests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{codes: tt.codes}
+			srv := httptest.NewServer(rc)
+			defer srv.Close()
+
+			s := newTestSender(t, srv.URL, SenderParams{MaxRetries: 2})
+			err := s.WritePoints(context.Background(), testPoints(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Close()
+
+			if len(rc.requests) != tt.requests {
+				t.Errorf("got %d requests, want %d", len(rc.requests), tt.requests)
+			}
+		})
+	}
+}
+
+func TestSenderQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s := newTestSender(t, srv.URL, SenderParams{QueueCapacity: 1, MaxSamplesPerSend: 1})
+	points := testPoints(3)
+	for i, point := range points {
+		point.AddTag("container_name", fmt.Sprintf("/app%d", i))
+	}
+	// Every series is a batch, the worker holds at most one and the queue
+	// another one.
+	err := s.WritePoints(context.Background(), points)
+	if err != ErrQueueFull {
+		t.Errorf("got %v, want ErrQueueFull", err)
+	}
+	close(block)
+	s.Close()
+}
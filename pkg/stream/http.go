@@ -0,0 +1,152 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Event types sent to stream clients.
+const (
+	EventPoints    = "points"
+	EventDropped   = "dropped"
+	EventHeartbeat = "heartbeat"
+)
+
+// Message is the envelope of every websocket message. Server-sent events use
+// the type as the event name and the remaining fields as data.
+type Message struct {
+	Type    string    `json:"type"`
+	Points  []Point   `json:"points,omitempty"`
+	Dropped uint64    `json:"dropped,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+const (
+	writeWait = 10 * time.Second
+	pongWait  = time.Minute
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// IsWebSocket reports whether the request asks for a websocket upgrade.
+func IsWebSocket(r *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(r)
+}
+
+// ServeSSE streams a subscription as server-sent events until the client
+// disconnects or the subscription is closed.
+func ServeSSE(w http.ResponseWriter, r *http.Request, sub *Subscription, heartbeat time.Duration) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		var msgs []Message
+		select {
+		case <-r.Context().Done():
+			return
+		case points, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			msgs = pointMessages(sub, points)
+		case t := <-ticker.C:
+			msgs = []Message{{Type: EventHeartbeat, Time: t}}
+		}
+
+		for _, msg := range msgs {
+			data, err := json.Marshal(msg)
+			if err != nil {
+				return
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Type, data)
+			if err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// ServeWebSocket upgrades the connection and streams a subscription as JSON
+// messages until the client disconnects or the subscription is closed.
+func ServeWebSocket(w http.ResponseWriter, r *http.Request, sub *Subscription, heartbeat time.Duration) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Read in the background so control frames are processed and a client
+	// disconnect is noticed.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		var msgs []Message
+		select {
+		case <-done:
+			return
+		case points, ok := <-sub.C():
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+				return
+			}
+			msgs = pointMessages(sub, points)
+		case t := <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			msgs = []Message{{Type: EventHeartbeat, Time: t}}
+		}
+
+		for _, msg := range msgs {
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func pointMessages(sub *Subscription, points []Point) []Message {
+	now := time.Now()
+	msgs := []Message{}
+	if dropped := sub.Dropped(); dropped > 0 {
+		msgs = append(msgs, Message{Type: EventDropped, Dropped: dropped, Time: now})
+	}
+	return append(msgs, Message{Type: EventPoints, Points: points, Time: now})
+}
@@ -0,0 +1,199 @@
+package stream
+
+import (
+	"context"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/zawachte/stalker/pkg/influx"
+)
+
+const defaultBufferSize = 16
+
+// Point is the JSON representation of a single collected point.
+type Point struct {
+	Measurement string                 `json:"measurement"`
+	Tags        map[string]string      `json:"tags"`
+	Fields      map[string]interface{} `json:"fields"`
+	Time        time.Time              `json:"time"`
+}
+
+// Filter selects the points a subscriber receives. Empty lists match everything.
+type Filter struct {
+	// Container names or path globs matched against the container_name tag.
+	Containers   []string
+	Measurements []string
+}
+
+// Matches reports whether the point passes the filter.
+func (f Filter) Matches(point *write.Point) bool {
+	if len(f.Measurements) > 0 && !contains(f.Measurements, point.Name()) {
+		return false
+	}
+	if len(f.Containers) == 0 {
+		return true
+	}
+
+	var container string
+	for _, tag := range point.TagList() {
+		if tag.Key == influx.TagContainerName {
+			container = tag.Value
+			break
+		}
+	}
+	for _, pattern := range f.Containers {
+		if ok, _ := path.Match(pattern, container); ok || pattern == container {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription delivers the points of every scrape that pass its filter.
+type Subscription struct {
+	filter  Filter
+	ch      chan []Point
+	dropped uint64
+	mu      sync.Mutex
+	closed  bool
+}
+
+// C returns the channel scrapes are delivered on. It is closed when the
+// subscription is cancelled.
+func (s *Subscription) C() <-chan []Point {
+	return s.ch
+}
+
+// Dropped returns and resets the number of scrapes that were dropped because
+// the subscriber did not keep up.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.SwapUint64(&s.dropped, 0)
+}
+
+// deliver hands a scrape to the subscriber without blocking. When the buffer
+// is full the oldest pending scrape is discarded to make room, so slow
+// subscribers always see the most recent data.
+func (s *Subscription) deliver(points []Point) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	for {
+		select {
+		case s.ch <- points:
+			return
+		default:
+		}
+
+		select {
+		case <-s.ch:
+			atomic.AddUint64(&s.dropped, 1)
+		default:
+		}
+	}
+}
+
+func (s *Subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// Broadcaster fans the points of every scrape out to live subscribers.
+type Broadcaster struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+	bufferSize    int
+}
+
+// NewBroadcaster creates a broadcaster that buffers up to bufferSize scrapes
+// per subscriber.
+func NewBroadcaster(bufferSize int) *Broadcaster {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	return &Broadcaster{
+		subscriptions: map[*Subscription]struct{}{},
+		bufferSize:    bufferSize,
+	}
+}
+
+// Subscribe registers a new subscriber.
+func (b *Broadcaster) Subscribe(filter Filter) *Subscription {
+	s := &Subscription{
+		filter: filter,
+		ch:     make(chan []Point, b.bufferSize),
+	}
+
+	b.mu.Lock()
+	b.subscriptions[s] = struct{}{}
+	b.mu.Unlock()
+
+	return s
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (b *Broadcaster) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	delete(b.subscriptions, s)
+	b.mu.Unlock()
+
+	s.close()
+}
+
+// WritePoints delivers a scrape to every subscriber whose filter matches at
+// least one point. It never blocks on slow subscribers.
+func (b *Broadcaster) WritePoints(ctx context.Context, points []*write.Point) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for s := range b.subscriptions {
+		matched := []Point{}
+		for _, point := range points {
+			if s.filter.Matches(point) {
+				matched = append(matched, toPoint(point))
+			}
+		}
+		if len(matched) > 0 {
+			s.deliver(matched)
+		}
+	}
+
+	return nil
+}
+
+func toPoint(point *write.Point) Point {
+	p := Point{
+		Measurement: point.Name(),
+		Tags:        map[string]string{},
+		Fields:      map[string]interface{}{},
+		Time:        point.Time(),
+	}
+	for _, tag := range point.TagList() {
+		p.Tags[tag.Key] = tag.Value
+	}
+	for _, field := range point.FieldList() {
+		p.Fields[field.Key] = field.Value
+	}
+	return p
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
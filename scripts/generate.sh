@@ -0,0 +1,21 @@
+#!/bin/sh
+# Generates internal/models, internal/api and pkg/client from stalker.yaml.
+set -e
+cd "$(dirname "$0")/.."
+
+SPEC=stalker.yaml
+oapi_codegen() {
+	go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen --old-config-style "$@" "$SPEC"
+}
+
+oapi_codegen -generate types -package models -o internal/models/models.go
+oapi_codegen -generate types,client -package client -o pkg/client/client.go
+
+# The server is generated without types, its references to the models are
+# qualified with the models package.
+oapi_codegen -generate chi-server -package api -o internal/api/api.go
+for t in $(sed -n -E 's/^type ([A-Z][A-Za-z0-9_]*) .*/\1/p' internal/models/models.go); do
+	sed -i -E "s/([] (*[])$t([ ){,]|$)/\1models.$t\2/g" internal/api/api.go
+done
+sed -i -E 's#^(\t"github.com/go-chi/chi/v5")$#\1\n\t"github.com/zawachte/stalker/internal/models"#' internal/api/api.go
+gofmt -w internal/api/api.go
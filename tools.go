@@ -0,0 +1,9 @@
+//go:build tools
+// +build tools
+
+package main
+
+// Tools run by go generate, tracked in go.mod.
+import (
+	_ "github.com/deepmap/oapi-codegen/cmd/oapi-codegen"
+)